@@ -0,0 +1,279 @@
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Format names the file format in the manifest.
+const Format = "readinglist-backup"
+
+// Version is the version of the format written. Backups of later versions can't be read.
+const Version = 1
+
+// Books is the collection of the books, which are restored through an IBookCollection.
+const Books = "books"
+
+// Collections are the collections backed up along with the books, restored document by document.
+var Collections = []string{"authors", "series", "shelves", "reading_sessions", "goals", "reviews", "notes", "highlights", "tags", "users"}
+
+var ErrInvalid = errors.New("invalid backup")
+
+/*
+Manifest ends every backup. Counts gives the number of documents of each collection and Checksum the SHA-256
+of every line before the manifest, uncompressed.
+*/
+type Manifest struct {
+	Format    string         `json:"format"`
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Counts    map[string]int `json:"counts"`
+	Checksum  string         `json:"checksum"`
+}
+
+/*
+line is one line of a backup: a document of a collection, or the manifest.
+Books are written as JSON, other documents as canonical MongoDB Extended JSON so their types survive.
+*/
+type line struct {
+	Collection string          `json:"collection,omitempty"`
+	Document   json.RawMessage `json:"document,omitempty"`
+	Manifest   *Manifest       `json:"manifest,omitempty"`
+}
+
+// Filename names a backup taken at t, as in readinglist-20240309T101500Z.jsonl.gz.
+func Filename(t time.Time) string {
+	return "readinglist-" + t.UTC().Format("20060102T150405Z") + ".jsonl.gz"
+}
+
+/*
+Writer writes a backup as gzip-compressed JSON Lines. Close must be called to write the manifest.
+*/
+type Writer struct {
+	gzip   *gzip.Writer
+	hash   hash.Hash
+	counts map[string]int
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{gzip: gzip.NewWriter(w), hash: sha256.New(), counts: map[string]int{Books: 0}}
+}
+
+// WriteBook writes a book.
+func (w *Writer) WriteBook(book *data.Book) error {
+	document, err := json.Marshal(book)
+	if err != nil {
+		return err
+	}
+
+	return w.write(Books, document)
+}
+
+/*
+WriteDocument writes a document, as it is stored, of one of the Collections.
+
+Parameters:
+
+	param1: collection string
+	param2: doc bson.Raw
+*/
+func (w *Writer) WriteDocument(collection string, doc bson.Raw) error {
+	document, err := bson.MarshalExtJSON(doc, true, false)
+	if err != nil {
+		return err
+	}
+
+	return w.write(collection, document)
+}
+
+func (w *Writer) write(collection string, document []byte) error {
+	encoded, err := json.Marshal(line{Collection: collection, Document: document})
+	if err != nil {
+		return err
+	}
+
+	encoded = append(encoded, '\n')
+	w.hash.Write(encoded)
+	w.counts[collection]++
+
+	_, err = w.gzip.Write(encoded)
+	return err
+}
+
+/*
+Close writes the manifest and flushes the compressed stream. It doesn't close the underlying writer.
+
+Returns:
+
+	return1: pointer Manifest
+	return2: error
+*/
+func (w *Writer) Close() (*Manifest, error) {
+	manifest := &Manifest{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Counts:    w.counts,
+		Checksum:  "sha256:" + hex.EncodeToString(w.hash.Sum(nil)),
+	}
+
+	encoded, err := json.Marshal(line{Manifest: manifest})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.gzip.Write(append(encoded, '\n')); err != nil {
+		return nil, err
+	}
+
+	return manifest, w.gzip.Close()
+}
+
+/*
+Backup is the content of a backup file.
+*/
+type Backup struct {
+	Manifest  *Manifest
+	Books     []*data.Book
+	Documents map[string][]bson.Raw
+}
+
+/*
+Read reads a whole backup, checking it against its manifest before anything is restored from it.
+
+Parameters:
+
+	param1: io.Reader, the gzip-compressed file
+
+Returns:
+
+	return1: pointer Backup
+	return2: error, wrapping ErrInvalid when the file is corrupt, truncated or not a backup
+*/
+func Read(r io.Reader) (*Backup, error) {
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	defer compressed.Close()
+
+	reader := bufio.NewReader(compressed)
+	backup := &Backup{Books: []*data.Book{}, Documents: make(map[string][]bson.Raw)}
+	counts := make(map[string]int)
+	sum := sha256.New()
+
+	for number := 1; ; number++ {
+		raw, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(raw) == 0 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+
+		if backup.Manifest != nil {
+			return nil, fmt.Errorf("%w: line %d comes after the manifest", ErrInvalid, number)
+		}
+
+		var l line
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalid, number, err)
+		}
+
+		if l.Manifest != nil {
+			backup.Manifest = l.Manifest
+			continue
+		}
+
+		sum.Write(raw)
+		counts[l.Collection]++
+
+		if err := backup.add(l); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalid, number, err)
+		}
+	}
+
+	if err := check(backup.Manifest, counts, "sha256:"+hex.EncodeToString(sum.Sum(nil))); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	return backup, nil
+}
+
+func (b *Backup) add(l line) error {
+	if l.Collection == Books {
+		var book data.Book
+		if err := json.Unmarshal(l.Document, &book); err != nil {
+			return err
+		}
+
+		b.Books = append(b.Books, &book)
+		return nil
+	}
+
+	if !isCollection(l.Collection) {
+		return fmt.Errorf("unknown collection %q", l.Collection)
+	}
+
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON(l.Document, true, &doc); err != nil {
+		return err
+	}
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	b.Documents[l.Collection] = append(b.Documents[l.Collection], raw)
+	return nil
+}
+
+// check compares what was read with the manifest.
+func check(manifest *Manifest, counts map[string]int, checksum string) error {
+	if manifest == nil {
+		return errors.New("no manifest, the file is truncated")
+	}
+
+	if manifest.Format != Format {
+		return fmt.Errorf("format %q, expected %q", manifest.Format, Format)
+	}
+
+	if manifest.Version < 1 || manifest.Version > Version {
+		return fmt.Errorf("version %d, expected at most %d", manifest.Version, Version)
+	}
+
+	if manifest.Checksum != checksum {
+		return errors.New("checksum mismatch, the file is corrupt")
+	}
+
+	for collection, count := range manifest.Counts {
+		if counts[collection] != count {
+			return fmt.Errorf("%d %s read, expected %d", counts[collection], collection, count)
+		}
+	}
+
+	return nil
+}
+
+func isCollection(name string) bool {
+	for _, collection := range Collections {
+		if collection == name {
+			return true
+		}
+	}
+
+	return false
+}
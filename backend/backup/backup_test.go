@@ -0,0 +1,119 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"readinglistapp/internal/data"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func writeBackup(t *testing.T) []byte {
+	t.Helper()
+
+	var file bytes.Buffer
+	writer := NewWriter(&file)
+
+	finished := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	if err := writer.WriteBook(&data.Book{ID: "65f0c0ffee0000000000b001", Title: "Dune", FinishedAt: &finished, Rating: 4.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	shelf, _ := bson.Marshal(bson.D{
+		{Key: "_id", Value: primitive.ObjectID{1}},
+		{Key: "name", Value: "Favourites"},
+		{Key: "createdat", Value: primitive.NewDateTimeFromTime(finished)},
+	})
+	if err := writer.WriteDocument("shelves", shelf); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Counts[Books] != 1 || manifest.Counts["shelves"] != 1 || !strings.HasPrefix(manifest.Checksum, "sha256:") {
+		t.Errorf("got %+v, expected the counts and checksum", manifest)
+	}
+
+	return file.Bytes()
+}
+
+// rewrite decompresses a backup, changes its lines and compresses it again.
+func rewrite(t *testing.T, file []byte, change func(lines []string) []string) []byte {
+	t.Helper()
+
+	reader, err := gzip.NewReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plain bytes.Buffer
+	plain.ReadFrom(reader)
+
+	lines := change(strings.SplitAfter(plain.String(), "\n"))
+
+	var out bytes.Buffer
+	writer := gzip.NewWriter(&out)
+	writer.Write([]byte(strings.Join(lines, "")))
+	writer.Close()
+
+	return out.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	backup, err := Read(bytes.NewReader(writeBackup(t)))
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if len(backup.Books) != 1 || backup.Books[0].Title != "Dune" || backup.Books[0].ID != "65f0c0ffee0000000000b001" || backup.Books[0].FinishedAt == nil {
+		t.Errorf("got %+v, expected Dune with its ID and dates", backup.Books)
+	}
+
+	shelves := backup.Documents["shelves"]
+	if len(shelves) != 1 {
+		t.Fatalf("got %d shelves, expected 1", len(shelves))
+	}
+
+	if id, ok := shelves[0].Lookup("_id").ObjectIDOK(); !ok || id != (primitive.ObjectID{1}) {
+		t.Errorf("got _id %v, expected the ObjectID to survive", shelves[0].Lookup("_id"))
+	}
+
+	if _, ok := shelves[0].Lookup("createdat").DateTimeOK(); !ok {
+		t.Errorf("got createdat %v, expected a date", shelves[0].Lookup("createdat"))
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	file := writeBackup(t)
+
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{name: "not gzip", file: []byte("title\nDune\n")},
+		{name: "truncated", file: rewrite(t, file, func(lines []string) []string { return lines[:2] })},
+		{name: "changed", file: rewrite(t, file, func(lines []string) []string {
+			lines[0] = strings.Replace(lines[0], "Dune", "Emma", 1)
+			return lines
+		})},
+		{name: "newer version", file: rewrite(t, file, func(lines []string) []string {
+			lines[2] = strings.Replace(lines[2], `"version":1`, `"version":2`, 1)
+			return lines
+		})},
+		{name: "after the manifest", file: rewrite(t, file, func(lines []string) []string { return append(lines, lines[0]) })},
+	}
+
+	for _, tt := range tests {
+		if _, err := Read(bytes.NewReader(tt.file)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: got error %v, expected ErrInvalid", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package bookcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"readinglistapp/internal/data"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListDelimiter joins the values of list columns such as authors and genres.
+const ListDelimiter = "|"
+
+// Columns are the columns of an export, in order. Imports read the same names.
+var Columns = []string{
+	"id", "title", "authors", "isbn10", "isbn13", "publisher", "language", "description", "edition",
+	"series", "series_position", "published", "pages", "genres", "tags", "rating", "status",
+	"current_page", "started_at", "finished_at", "abandoned_at", "created_at",
+}
+
+var ErrNoTitle = errors.New("no title column")
+
+/*
+formulaPrefixes start the cells that spreadsheets would run as formulas, as OWASP's CSV injection guidance lists them:
+a tab or carriage return can come before a formula. Such cells are exported behind a quote.
+*/
+const formulaPrefixes = "=+-@\t\r"
+
+/*
+Writer writes books as CSV rows under the Columns header.
+*/
+type Writer struct {
+	csv         *csv.Writer
+	wroteHeader bool
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{csv: csv.NewWriter(w)}
+}
+
+/*
+Write writes a book as one row, after the header when it is the first.
+Cells a spreadsheet would take for a formula, such as a title starting with "=", are written behind a "'",
+which imports strip again.
+
+Parameters:
+
+	param1: pointer of book data
+
+Returns:
+
+	return1: error
+*/
+func (w *Writer) Write(book *data.Book) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	row := []string{
+		book.ID,
+		book.Title,
+		strings.Join(book.Authors, ListDelimiter),
+		book.ISBN10,
+		book.ISBN13,
+		book.Publisher,
+		book.Language,
+		book.Description,
+		book.Edition,
+		book.Series,
+		formatFloat(book.SeriesPosition),
+		formatInt(book.Published),
+		formatInt(book.Pages),
+		strings.Join(book.Genres, ListDelimiter),
+		strings.Join(book.Tags, ListDelimiter),
+		formatFloat(book.Rating),
+		book.Status,
+		formatInt(book.CurrentPage),
+		formatTime(book.StartedAt),
+		formatTime(book.FinishedAt),
+		formatTime(book.AbandonedAt),
+		formatTime(&book.CreatedAt),
+	}
+
+	for i, cell := range row {
+		row[i] = EscapeFormula(cell)
+	}
+
+	return w.csv.Write(row)
+}
+
+/*
+Flush writes any buffered rows, and the header when no book was written, so an empty export is still a valid file.
+
+Returns:
+
+	return1: error
+*/
+func (w *Writer) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	w.csv.Flush()
+
+	return w.csv.Error()
+}
+
+// EscapeFormula puts a quote before a cell that starts like a formula, as Writer does for every cell.
+func EscapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+
+	return cell
+}
+
+// unescapeFormula removes the quote EscapeFormula puts before a cell.
+func unescapeFormula(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+
+	return cell
+}
+
+func (w *Writer) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+
+	w.wroteHeader = true
+
+	return w.csv.Write(Columns)
+}
+
+/*
+Record is one row read from a CSV file. Shelves names the shelves the book should be on, for exports
+of services that have them.
+Err explains why the row could not be turned into a book, in which case Book is nil.
+*/
+type Record struct {
+	Line    int
+	Values  []string
+	Book    *data.Book
+	Shelves []string
+	Err     error
+}
+
+/*
+RecordReader reads books from a file one row at a time, returning io.EOF after the last one.
+*/
+type RecordReader interface {
+	Header() []string
+	Read() (*Record, error)
+}
+
+/*
+Reader reads books from CSV with a header row. The id and tags columns are ignored: imported books get new IDs
+and tags are set through the taxonomy.
+*/
+type Reader struct {
+	csv    *csv.Reader
+	header []string
+	index  map[string]int
+}
+
+/*
+NewReader reads the header and works out which CSV column each book column comes from.
+Columns are found by their own name, ignoring case, unless the mapping names another header for them.
+
+Parameters:
+
+	param1: io.Reader
+	param2: mapping map[string]string, from a name in Columns to a header in the file
+
+Returns:
+
+	return1: pointer Reader
+	return2: error, when the mapping names an unknown column or header, or there is no title column
+*/
+func NewReader(r io.Reader, mapping map[string]string) (*Reader, error) {
+	reader, header, positions, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(Columns))
+	for _, column := range Columns {
+		if i, ok := positions[column]; ok {
+			index[column] = i
+		}
+	}
+
+	for column, name := range mapping {
+		if !isColumn(column) {
+			return nil, fmt.Errorf("unknown column %q, expected one of %s", column, strings.Join(Columns, ", "))
+		}
+
+		i, ok := positions[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("no %q header to read %s from", name, column)
+		}
+		index[column] = i
+	}
+
+	if _, ok := index["title"]; !ok {
+		return nil, ErrNoTitle
+	}
+
+	return &Reader{csv: reader, header: header, index: index}, nil
+}
+
+// Header returns the header row of the file.
+func (r *Reader) Header() []string {
+	return r.header
+}
+
+/*
+Read reads the next row. A row with bad values is returned with Err set; a file that is not valid CSV
+stops the import with an error.
+
+Returns:
+
+	return1: pointer Record
+	return2: error, io.EOF after the last row
+*/
+func (r *Reader) Read() (*Record, error) {
+	values, err := r.csv.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	record := &Record{Line: line, Values: values}
+	record.Book, record.Err = r.book(values)
+
+	return record, nil
+}
+
+func (r *Reader) book(values []string) (*data.Book, error) {
+	p := parser{values: values, index: r.index}
+
+	book := &data.Book{
+		Title:          p.text("title"),
+		Authors:        p.list("authors"),
+		ISBN10:         p.text("isbn10"),
+		ISBN13:         p.text("isbn13"),
+		Publisher:      p.text("publisher"),
+		Language:       p.text("language"),
+		Description:    p.text("description"),
+		Edition:        p.text("edition"),
+		Series:         p.text("series"),
+		SeriesPosition: p.float("series_position"),
+		Published:      p.int("published"),
+		Pages:          p.int("pages"),
+		Genres:         p.list("genres"),
+		Rating:         p.float("rating"),
+		Status:         p.text("status"),
+		CurrentPage:    p.int("current_page"),
+		StartedAt:      p.time("started_at"),
+		FinishedAt:     p.time("finished_at"),
+		AbandonedAt:    p.time("abandoned_at"),
+	}
+
+	if createdAt := p.time("created_at"); createdAt != nil {
+		book.CreatedAt = *createdAt
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return book, nil
+}
+
+// parser reads typed values from a row, keeping the first error.
+type parser struct {
+	values []string
+	index  map[string]int
+	err    error
+}
+
+func (p *parser) text(column string) string {
+	i, ok := p.index[column]
+	if !ok || i >= len(p.values) {
+		return ""
+	}
+
+	return unescapeFormula(strings.TrimSpace(p.values[i]))
+}
+
+func (p *parser) list(column string) []string {
+	var list []string
+	for _, value := range strings.Split(p.text(column), ListDelimiter) {
+		if value = strings.TrimSpace(value); value != "" {
+			list = append(list, value)
+		}
+	}
+
+	return list
+}
+
+func (p *parser) int(column string) int {
+	value := p.text(column)
+	if value == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(column, "a whole number", value)
+	}
+
+	return n
+}
+
+func (p *parser) float(column string) float64 {
+	value := p.text(column)
+	if value == "" {
+		return 0
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.fail(column, "a number", value)
+	}
+
+	return f
+}
+
+// time accepts RFC 3339 timestamps, as exported, and plain dates.
+func (p *parser) time(column string) *time.Time {
+	value := p.text(column)
+	if value == "" {
+		return nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly, "2006/01/02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+
+	p.fail(column, "a date", value)
+
+	return nil
+}
+
+func (p *parser) fail(column, expected, value string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("%s must be %s, got %q", column, expected, value)
+	}
+}
+
+/*
+readHeader starts reading CSV from r and returns the header row with the position of each header,
+by its lower-cased name. ErrNoTitle is returned for an empty file.
+*/
+func readHeader(r io.Reader) (*csv.Reader, []string, map[string]int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil, ErrNoTitle
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+
+	return reader, header, positions, nil
+}
+
+func isColumn(name string) bool {
+	for _, column := range Columns {
+		if column == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(n)
+}
+
+func formatFloat(f float64) string {
+	if f == 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,144 @@
+package bookcsv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"readinglistapp/internal/data"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	finished := time.Date(2024, 3, 9, 18, 30, 0, 0, time.UTC)
+	book := &data.Book{
+		ID:          "507f1f77bcf86cd799439011",
+		Title:       "Dune, Part One",
+		Authors:     []string{"Frank Herbert"},
+		ISBN13:      "9780441172719",
+		Description: "Spice\nand sand",
+		Published:   1965,
+		Pages:       412,
+		Genres:      []string{"science fiction", "classic"},
+		Rating:      4.5,
+		Status:      data.StatusFinished,
+		FinishedAt:  &finished,
+		CreatedAt:   finished,
+	}
+
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+	if err := writer.Write(book); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(&buf, nil)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	record, err := reader.Read()
+	if err != nil || record.Err != nil {
+		t.Fatalf("got errors %v and %v, expected nil", err, record.Err)
+	}
+
+	if record.Line != 2 {
+		t.Errorf("got line %d, expected 2", record.Line)
+	}
+
+	expected := *book
+	expected.ID = ""
+	if !reflect.DeepEqual(record.Book, &expected) {
+		t.Errorf("got %+v, expected %+v", record.Book, &expected)
+	}
+
+	if _, err := reader.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("got error %v, expected io.EOF", err)
+	}
+}
+
+func TestFormulaCells(t *testing.T) {
+	book := &data.Book{Title: "=HYPERLINK(\"http://example.com\")", Authors: []string{"@someone"}, Publisher: "+Plus", Description: "\t=1+1", Edition: "\r-2"}
+
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+	if err := writer.Write(book); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if export := buf.String(); !strings.Contains(export, `"'=HYPERLINK(""http://example.com"")"`) || !strings.Contains(export, ",'@someone,") || !strings.Contains(export, ",'\t=1+1,") {
+		t.Errorf("got %q, expected the formula cells behind a quote", export)
+	}
+
+	reader, err := NewReader(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := reader.Read()
+	if err != nil || record.Err != nil {
+		t.Fatalf("got errors %v and %v, expected nil", err, record.Err)
+	}
+
+	if record.Book.Title != book.Title || record.Book.Authors[0] != "@someone" || record.Book.Publisher != "+Plus" || record.Book.Edition != "\r-2" {
+		t.Errorf("got %+v, expected the cells as they were before the export", record.Book)
+	}
+}
+
+func TestEmptyExport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := strings.Join(Columns, ",") + "\n"; buf.String() != expected {
+		t.Errorf("got %q, expected only the header", buf.String())
+	}
+}
+
+func TestReaderMapping(t *testing.T) {
+	file := "Book Title,Writer,Year,Stars\nEmma,Jane Austen,1815,five\n"
+
+	if _, err := NewReader(strings.NewReader(file), nil); !errors.Is(err, ErrNoTitle) {
+		t.Errorf("got error %v, expected ErrNoTitle", err)
+	}
+
+	if _, err := NewReader(strings.NewReader(file), map[string]string{"title": "Missing"}); err == nil {
+		t.Error("got nil, expected an error for an unknown header")
+	}
+
+	if _, err := NewReader(strings.NewReader(file), map[string]string{"colour": "Writer"}); err == nil {
+		t.Error("got nil, expected an error for an unknown column")
+	}
+
+	reader, err := NewReader(strings.NewReader(file), map[string]string{"title": "book title", "authors": "Writer", "published": "Year"})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	record, err := reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Err != nil {
+		t.Fatalf("got error %v, expected the unmapped Stars column to be ignored", record.Err)
+	}
+
+	if record.Book.Title != "Emma" || record.Book.Authors[0] != "Jane Austen" || record.Book.Published != 1815 {
+		t.Errorf("got %+v, expected the mapped columns read", record.Book)
+	}
+
+	reader, _ = NewReader(strings.NewReader(file), map[string]string{"title": "Book Title", "rating": "Stars"})
+	if record, _ := reader.Read(); record.Err == nil || record.Book != nil {
+		t.Errorf("got %+v, expected an error for a rating that is not a number", record)
+	}
+}
@@ -0,0 +1,298 @@
+package bookcsv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"readinglistapp/internal/data"
+	"readinglistapp/isbn"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Exports of reading services that can be imported.
+const (
+	Goodreads  = "goodreads"
+	StoryGraph = "storygraph"
+)
+
+// Formats lists what an import can read: CSV with the Columns of an export, or the export of a reading service.
+var Formats = []string{"csv", Goodreads, StoryGraph}
+
+// seriesTitle matches the series Goodreads appends to titles, as in "The Hobbit (Middle-earth, #0.5)".
+var seriesTitle = regexp.MustCompile(`^(.+?)\s*\(([^()]+),\s*#(\d+(?:\.\d+)?)\)$`)
+
+// goodreadsStatuses maps the exclusive shelves of Goodreads onto reading statuses.
+var goodreadsStatuses = map[string]string{
+	"read":              data.StatusFinished,
+	"currently-reading": data.StatusReading,
+	"to-read":           data.StatusWantToRead,
+}
+
+/*
+goodreadsAbandoned lists the custom exclusive shelves Goodreads readers use for books they gave up on,
+by their name in lower case without punctuation or spaces.
+*/
+var goodreadsAbandoned = map[string]bool{
+	"dnf":          true,
+	"didnotfinish": true,
+	"didntfinish":  true,
+	"abandoned":    true,
+	"gaveup":       true,
+	"unfinished":   true,
+}
+
+// storyGraphStatuses maps the read statuses of StoryGraph onto reading statuses.
+var storyGraphStatuses = map[string]string{
+	"read":              data.StatusFinished,
+	"currently-reading": data.StatusReading,
+	"paused":            data.StatusReading,
+	"to-read":           data.StatusWantToRead,
+	"did-not-finish":    data.StatusAbandoned,
+}
+
+/*
+Open reads a file in one of the Formats. Plain CSV columns are matched to headers of the same name unless
+mapping gives the header a column is read from.
+
+Parameters:
+
+	param1: io.Reader
+	param2: format string, one of Formats; empty means csv
+	param3: mapping map[string]string, from column to header
+
+Returns:
+
+	return1: RecordReader
+	return2: error, when the format is unknown or the header doesn't fit it
+*/
+func Open(r io.Reader, format string, mapping map[string]string) (RecordReader, error) {
+	if format == "" || format == "csv" {
+		return NewReader(r, mapping)
+	}
+
+	return NewServiceReader(r, format)
+}
+
+/*
+ServiceReader reads the CSV export of a reading service, turning its columns into books, reading statuses and shelves.
+*/
+type ServiceReader struct {
+	csv       *csv.Reader
+	header    []string
+	positions map[string]int
+	book      func(p *parser) (*data.Book, []string)
+}
+
+/*
+NewServiceReader reads the header of a Goodreads or StoryGraph export.
+
+Goodreads: the exclusive shelf gives the status (read, currently-reading or to-read) and the other shelves become
+shelves. Series appended to titles, as in "Dune (Dune, #1)", are split off. A rating of 0 means unrated.
+
+StoryGraph: the read status gives the status, with did-not-finish as abandoned and paused as reading, and tags
+become shelves. Its exports have no page count or year published.
+
+Parameters:
+
+	param1: io.Reader
+	param2: service string, Goodreads or StoryGraph
+
+Returns:
+
+	return1: pointer ServiceReader
+	return2: error, when the file isn't an export of the service
+*/
+func NewServiceReader(r io.Reader, service string) (*ServiceReader, error) {
+	var required []string
+	var book func(p *parser) (*data.Book, []string)
+
+	switch service {
+	case Goodreads:
+		required, book = []string{"title", "author", "exclusive shelf"}, goodreadsBook
+	case StoryGraph:
+		required, book = []string{"title", "authors", "read status"}, storyGraphBook
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected one of %s", service, strings.Join(Formats, ", "))
+	}
+
+	reader, header, positions, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range required {
+		if _, ok := positions[name]; !ok {
+			return nil, fmt.Errorf("not a %s export: there is no %q column", service, name)
+		}
+	}
+
+	return &ServiceReader{csv: reader, header: header, positions: positions, book: book}, nil
+}
+
+// Header returns the header row of the file.
+func (r *ServiceReader) Header() []string {
+	return r.header
+}
+
+/*
+Read reads the next row.
+
+Returns:
+
+	return1: pointer Record
+	return2: error, io.EOF after the last row
+*/
+func (r *ServiceReader) Read() (*Record, error) {
+	values, err := r.csv.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	record := &Record{Line: line, Values: values}
+
+	p := &parser{values: values, index: r.positions}
+	book, shelves := r.book(p)
+
+	if p.err != nil {
+		record.Err = p.err
+		return record, nil
+	}
+
+	record.Book, record.Shelves = book, shelves
+
+	return record, nil
+}
+
+func goodreadsBook(p *parser) (*data.Book, []string) {
+	book := &data.Book{
+		Title:     p.text("title"),
+		Authors:   names(p.text("author"), p.text("additional authors")),
+		ISBN10:    strings.Trim(p.text("isbn"), `="`),
+		ISBN13:    strings.Trim(p.text("isbn13"), `="`),
+		Publisher: p.text("publisher"),
+		Pages:     p.int("number of pages"),
+		Published: p.int("year published"),
+		Rating:    p.float("my rating"),
+	}
+
+	if book.Published == 0 {
+		book.Published = p.int("original publication year")
+	}
+
+	if match := seriesTitle.FindStringSubmatch(book.Title); match != nil {
+		book.Title, book.Series = match[1], match[2]
+		book.SeriesPosition, _ = strconv.ParseFloat(match[3], 64)
+	}
+
+	if added := p.time("date added"); added != nil {
+		book.CreatedAt = *added
+	}
+
+	exclusive := p.text("exclusive shelf")
+	book.Status = goodreadsStatus(exclusive)
+
+	switch book.Status {
+	case data.StatusFinished:
+		book.FinishedAt = p.time("date read")
+		book.CurrentPage = book.Pages
+	case data.StatusAbandoned:
+		// Goodreads has no date for giving up on a book; the last one it has is the best guess
+		if book.AbandonedAt = p.time("date read"); book.AbandonedAt == nil {
+			book.AbandonedAt = p.time("date added")
+		}
+	}
+
+	var shelves []string
+	for _, shelf := range names(p.text("bookshelves")) {
+		if goodreadsStatus(shelf) == "" {
+			shelves = append(shelves, shelf)
+		}
+	}
+
+	// Any other custom exclusive shelf is kept as a shelf
+	if book.Status == "" && exclusive != "" {
+		book.Status = data.StatusWantToRead
+		if !contains(shelves, exclusive) {
+			shelves = append(shelves, exclusive)
+		}
+	}
+
+	return book, shelves
+}
+
+// goodreadsStatus returns the reading status a Goodreads shelf stands for, or "" for a shelf that is only a shelf.
+func goodreadsStatus(shelf string) string {
+	shelf = strings.ToLower(shelf)
+	if status, ok := goodreadsStatuses[shelf]; ok {
+		return status
+	}
+
+	key := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r
+		}
+		return -1
+	}, shelf)
+	if goodreadsAbandoned[key] {
+		return data.StatusAbandoned
+	}
+
+	return ""
+}
+
+func storyGraphBook(p *parser) (*data.Book, []string) {
+	book := &data.Book{
+		Title:   p.text("title"),
+		Authors: names(p.text("authors")),
+		Rating:  p.float("star rating"),
+	}
+
+	switch id := isbn.Clean(p.text("isbn/uid")); {
+	case isbn.Valid13(id):
+		book.ISBN13 = id
+	case isbn.Valid10(id):
+		book.ISBN10 = id
+	}
+
+	if added := p.time("date added"); added != nil {
+		book.CreatedAt = *added
+	}
+
+	book.Status = storyGraphStatuses[strings.ToLower(p.text("read status"))]
+
+	switch book.Status {
+	case data.StatusFinished:
+		book.FinishedAt = p.time("last date read")
+	case data.StatusAbandoned:
+		book.AbandonedAt = p.time("last date read")
+	}
+
+	return book, names(p.text("tags"))
+}
+
+// names splits comma-separated lists, such as authors and shelves, dropping blanks.
+func names(lists ...string) []string {
+	var result []string
+	for _, list := range lists {
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				result = append(result, name)
+			}
+		}
+	}
+
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package bookcsv
+
+import (
+	"readinglistapp/internal/data"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const goodreadsExport = `Book Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating,Average Rating,Publisher,Binding,Number of Pages,Year Published,Original Publication Year,Date Read,Date Added,Bookshelves,Bookshelves with positions,Exclusive Shelf,My Review,Spoiler,Private Notes,Read Count,Owned Copies
+234225,"Dune (Dune, #1)",Frank Herbert,"Herbert, Frank",,"=""0441172717""","=""9780441172719""",5,4.27,Ace Books,Paperback,604,,1965,2024/03/09,2023/12/01,"favorites, sci-fi, read","favorites (#3), sci-fi (#1), read (#10)",read,,,,1,0
+12067,Good Omens,Terry Pratchett,"Pratchett, Terry",Neil Gaiman,"=""""","=""""",0,4.25,,,,2006,1990,,2022/01/05,to-read,to-read (#1),to-read,,,,0,0
+6759,Infinite Jest,David Foster Wallace,"Wallace, David Foster",,"=""""","=""""",0,4.3,,,1079,2006,1996,,2021/06/01,did-not-finish,did-not-finish (#1),did-not-finish,,,,0,0
+`
+
+const goodreadsCustomShelf = `Book Id,Title,Author,ISBN,ISBN13,My Rating,Number of Pages,Year Published,Date Read,Date Added,Bookshelves,Exclusive Shelf
+6759,Emma,Jane Austen,,,0,474,1815,,2021/06/01,holiday,holiday
+`
+
+const storyGraphExport = `Title,Authors,Contributors,ISBN/UID,Format,Read Status,Date Added,Last Date Read,Dates Read,Read Count,Moods,Pace,Character- or Plot-Driven?,Strong Character Development?,Loveable Characters?,Diverse Characters?,Flawed Characters?,Star Rating,Review,Content Warnings,Content Warning Description,Tags,Owned?
+Piranesi,Susanna Clarke,,9780306406157,hardcover,read,2023/05/01,2023/05/20,2023/05/10-2023/05/20,1,mysterious,medium,Character,Yes,Yes,No,Yes,4.5,,,,"comfort reads, fantasy",No
+The Silmarillion,"J.R.R. Tolkien, Christopher Tolkien",,sg-2a9c,,did-not-finish,2022/01/01,2022/02/01,,0,,,,,,,,,,,,,No
+`
+
+func readAll(t *testing.T, reader RecordReader) []*Record {
+	t.Helper()
+
+	var records []*Record
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			return records
+		}
+		if record.Err != nil {
+			t.Fatalf("got error %v on line %d, expected nil", record.Err, record.Line)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestGoodreads(t *testing.T) {
+	reader, err := NewServiceReader(strings.NewReader(goodreadsExport), Goodreads)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	records := readAll(t, reader)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, expected 3", len(records))
+	}
+
+	read := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	expected := &data.Book{
+		CreatedAt:      time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+		Title:          "Dune",
+		Authors:        []string{"Frank Herbert"},
+		ISBN10:         "0441172717",
+		ISBN13:         "9780441172719",
+		Publisher:      "Ace Books",
+		Series:         "Dune",
+		SeriesPosition: 1,
+		Status:         data.StatusFinished,
+		CurrentPage:    604,
+		FinishedAt:     &read,
+		Published:      1965,
+		Pages:          604,
+		Rating:         5,
+	}
+	if !reflect.DeepEqual(records[0].Book, expected) {
+		t.Errorf("got %+v, expected %+v", records[0].Book, expected)
+	}
+
+	if expected := []string{"favorites", "sci-fi"}; !reflect.DeepEqual(records[0].Shelves, expected) {
+		t.Errorf("got shelves %v, expected %v without the exclusive shelf", records[0].Shelves, expected)
+	}
+
+	omens := records[1].Book
+	if omens.Status != data.StatusWantToRead || omens.Rating != 0 || omens.Published != 2006 || len(omens.Authors) != 2 || omens.ISBN13 != "" {
+		t.Errorf("got %+v, expected an unrated book to read with both authors", omens)
+	}
+
+	added := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	if jest := records[2].Book; jest.Status != data.StatusAbandoned || jest.AbandonedAt == nil || !jest.AbandonedAt.Equal(added) || len(records[2].Shelves) != 0 {
+		t.Errorf("got %+v on %v, expected a did-not-finish shelf to mean abandoned", jest, records[2].Shelves)
+	}
+
+	if reader, err = NewServiceReader(strings.NewReader(goodreadsCustomShelf), Goodreads); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if records := readAll(t, reader); records[0].Book.Status != data.StatusWantToRead || !reflect.DeepEqual(records[0].Shelves, []string{"holiday"}) {
+		t.Errorf("got %+v on %v, expected a custom exclusive shelf kept as a shelf", records[0].Book, records[0].Shelves)
+	}
+
+	if _, err := NewServiceReader(strings.NewReader(storyGraphExport), Goodreads); err == nil {
+		t.Error("got nil, expected an error reading a StoryGraph export as Goodreads")
+	}
+}
+
+func TestStoryGraph(t *testing.T) {
+	reader, err := NewServiceReader(strings.NewReader(storyGraphExport), StoryGraph)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	records := readAll(t, reader)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, expected 2", len(records))
+	}
+
+	piranesi := records[0].Book
+	if piranesi.ISBN13 != "9780306406157" || piranesi.Rating != 4.5 || piranesi.Status != data.StatusFinished || piranesi.FinishedAt == nil {
+		t.Errorf("got %+v, expected a finished, rated book with its ISBN", piranesi)
+	}
+
+	if expected := []string{"comfort reads", "fantasy"}; !reflect.DeepEqual(records[0].Shelves, expected) {
+		t.Errorf("got shelves %v, expected the tags %v", records[0].Shelves, expected)
+	}
+
+	silmarillion := records[1].Book
+	if silmarillion.Status != data.StatusAbandoned || silmarillion.AbandonedAt == nil || silmarillion.ISBN10 != "" || silmarillion.ISBN13 != "" || len(silmarillion.Authors) != 2 {
+		t.Errorf("got %+v, expected an abandoned book without an ISBN", silmarillion)
+	}
+
+	if _, err := NewServiceReader(strings.NewReader(storyGraphExport), "librarything"); err == nil {
+		t.Error("got nil, expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"readinglistapp/internal/data"
+	"readinglistapp/model"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newBooksCommand(open appOpener) *cobra.Command {
+	books := &cobra.Command{
+		Use:   "books",
+		Short: "List, show and delete books",
+	}
+
+	books.AddCommand(newBooksListCommand(open), newBooksGetCommand(open), newBooksDeleteCommand(open))
+
+	return books
+}
+
+func newBooksListCommand(open appOpener) *cobra.Command {
+	var owner, status, tag string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the books, like GET /v1/books",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statuses, err := model.ParseStatusFilter(status)
+			if err != nil {
+				return err
+			}
+
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			filter := data.BookFilter{Statuses: statuses}
+
+			if owner = strings.TrimSpace(owner); owner != "" {
+				filter.Owners = []string{owner}
+			}
+
+			if tag != "" {
+				if filter.Tags, err = app.Model.ExpandTag(app.Tags, tag); err != nil {
+					return err
+				}
+			}
+
+			books, err := app.Model.GetFiltered(app.Books, filter)
+			if err != nil {
+				return err
+			}
+
+			table := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(table, "ID\tSTATUS\tTITLE\tAUTHORS")
+
+			for _, book := range books {
+				fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", book.ID, book.Status, book.Title, strings.Join(book.Authors, ", "))
+			}
+
+			return table.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&owner, "owner", "", "owner of the books")
+	cmd.Flags().StringVar(&status, "status", "", "comma-separated reading statuses")
+	cmd.Flags().StringVar(&tag, "tag", "", "tag, including the tags under it")
+
+	return cmd
+}
+
+func newBooksGetCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show a book as JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			book, err := app.Model.Get(app.Books, args[0])
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "\t")
+
+			return encoder.Encode(book)
+		},
+	}
+}
+
+func newBooksDeleteCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Move a book to the trash",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			if err := app.Model.Delete(app.Books, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved book %s to the trash\n", args[0])
+			return nil
+		},
+	}
+}
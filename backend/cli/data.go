@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"readinglistapp/backup"
+	"readinglistapp/bookcsv"
+	"readinglistapp/internal"
+	"readinglistapp/migrations"
+	"readinglistapp/model"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// seedBooks are the sample books added by the seed command.
+//
+//go:embed seed.csv
+var seedBooks string
+
+// stdio names standard input or output in place of a file.
+const stdio = "-"
+
+func newMigrateCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate [up|down|status] [n]",
+		Short: "Apply, roll back or list database migrations; every pending one without arguments",
+		Args:  cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			return migrate(app, args, cmd.OutOrStdout())
+		},
+	}
+}
+
+func migrate(app *internal.App, args []string, out io.Writer) error {
+	migrator, err := migrations.NewMigrator(app.DB.Database(), migrations.All())
+	if err != nil {
+		return err
+	}
+
+	return migrations.RunCommand(context.Background(), migrator, args, out)
+}
+
+func newSeedCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "seed",
+		Short: "Add a few sample books; running it again adds nothing",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			source, err := bookcsv.NewReader(strings.NewReader(seedBooks), nil)
+			if err != nil {
+				return err
+			}
+
+			report, err := app.Model.ImportBooks(app.Books, app.Shelves, source, "", false)
+			if err != nil {
+				return err
+			}
+
+			printImportReport(cmd.OutOrStdout(), report)
+			return nil
+		},
+	}
+}
+
+func newImportCommand(open appOpener) *cobra.Command {
+	var format, owner string
+	var dryRun bool
+	var mapping map[string]string
+
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import books from CSV or a Goodreads or StoryGraph export, read from standard input without a file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in, err := openInput(cmd, args)
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+
+			source, err := bookcsv.Open(in, format, mapping)
+			if err != nil {
+				return err
+			}
+
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			report, err := app.Model.ImportBooks(app.Books, app.Shelves, source, owner, dryRun)
+			if err != nil {
+				return err
+			}
+
+			printImportReport(cmd.OutOrStdout(), report)
+
+			if report.Failed > 0 {
+				return fmt.Errorf("%d rows failed", report.Failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "csv", "file format: "+strings.Join(bookcsv.Formats, ", "))
+	cmd.Flags().StringVar(&owner, "owner", "", "owner of the imported books")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be imported without writing anything")
+	cmd.Flags().StringToStringVar(&mapping, "map", nil, "read a column from another header, as in title=\"Book Title\"")
+
+	return cmd
+}
+
+func newExportCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export every book as CSV, to standard output without a file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			out, err := createOutput(cmd, args, "")
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			writer := bookcsv.NewWriter(out)
+
+			if err := app.Model.ExportBooks(app.Books, writer.Write); err != nil {
+				return err
+			}
+
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+
+			return out.Close()
+		},
+	}
+}
+
+func newBackupCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup [file]",
+		Short: "Back up the books and related collections, to readinglist-<time>.jsonl.gz without a file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			out, err := createOutput(cmd, args, backup.Filename(time.Now()))
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			manifest, err := app.Model.Backup(app.Books, app.Documents, out)
+			if err != nil {
+				return err
+			}
+
+			if err := out.Close(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Backed up %d books (%s)\n", manifest.Counts[backup.Books], manifest.Checksum)
+			return nil
+		},
+	}
+}
+
+func newRestoreCommand(open appOpener) *cobra.Command {
+	var mode string
+
+	cmd := &cobra.Command{
+		Use:   "restore [file]",
+		Short: "Restore a backup, read from standard input without a file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in, err := openInput(cmd, args)
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			report, err := app.Model.Restore(app.Books, app.Documents, app.Transactor, in, mode)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			for _, collection := range append([]string{backup.Books}, backup.Collections...) {
+				count := report.Collections[collection]
+				fmt.Fprintf(out, "%-16s created %d, updated %d, deleted %d, skipped %d\n", collection, count.Created, count.Updated, count.Deleted, count.Skipped)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&mode, "mode", model.RestoreMerge, "merge, to only add what is missing, or replace, to make the library the backup")
+
+	return cmd
+}
+
+/*
+printImportReport summarises an import, listing the rows that failed.
+*/
+func printImportReport(out io.Writer, report *model.ImportReport) {
+	if report.DryRun {
+		fmt.Fprintln(out, "Dry run, nothing was written.")
+	}
+
+	fmt.Fprintf(out, "created %d, updated %d, skipped %d, failed %d\n", report.Created, report.Updated, report.Skipped, report.Failed)
+
+	if report.Shelved > 0 || len(report.ShelvesCreated) > 0 {
+		fmt.Fprintf(out, "shelved %d, new shelves: %s\n", report.Shelved, strings.Join(report.ShelvesCreated, ", "))
+	}
+
+	for _, row := range report.Failures() {
+		fmt.Fprintf(out, "line %d: %s\n", row.Line, row.Reason)
+	}
+}
+
+// openInput opens the file named by the only argument, or standard input without one or for "-".
+func openInput(cmd *cobra.Command, args []string) (io.ReadCloser, error) {
+	if len(args) == 0 || args[0] == stdio {
+		return io.NopCloser(cmd.InOrStdin()), nil
+	}
+
+	return os.Open(args[0])
+}
+
+// createOutput creates the file named by the only argument or else by name, writing to standard output without either.
+func createOutput(cmd *cobra.Command, args []string, name string) (io.WriteCloser, error) {
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	if name == "" || name == stdio {
+		return nopWriteCloser{cmd.OutOrStdout()}, nil
+	}
+
+	return os.Create(name)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
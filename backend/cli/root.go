@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"readinglistapp/internal"
+	"readinglistapp/settings"
+
+	"github.com/spf13/cobra"
+)
+
+// appOpener loads the configuration and wires the application, for the commands that need the database.
+type appOpener func() (*internal.App, error)
+
+/*
+NewRootCommand builds the readinglist command line. Commands that need the database load the configuration
+the same way as the server (defaults, config file, .env and environment) and share its internal.App wiring.
+
+Returns:
+
+	return1: pointer cobra.Command
+*/
+func NewRootCommand() *cobra.Command {
+	var configFile string
+
+	root := &cobra.Command{
+		Use:          "readinglist",
+		Short:        "Serve and administer the reading list",
+		SilenceUsage: true,
+	}
+
+	root.PersistentFlags().StringVar(&configFile, "config", "", "YAML or TOML config file (default $CONFIG_FILE)")
+
+	open := func() (*internal.App, error) {
+		cfg, err := settings.Load(configFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return internal.NewApp(cfg)
+	}
+
+	root.AddCommand(
+		newServeCommand(open),
+		newMigrateCommand(open),
+		newSeedCommand(open),
+		newImportCommand(open),
+		newExportCommand(open),
+		newBackupCommand(open),
+		newRestoreCommand(open),
+		newBooksCommand(open),
+		newTrashCommand(open),
+		newUserCommand(open),
+	)
+
+	return root
+}
+
+/*
+Execute runs the command line with args, the arguments after the program name.
+
+Parameters:
+
+	param1: args []string
+
+Returns:
+
+	return1: error, already printed
+*/
+func Execute(args []string) error {
+	root := NewRootCommand()
+	root.SetArgs(args)
+
+	return root.Execute()
+}
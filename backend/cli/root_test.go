@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"readinglistapp/bookcsv"
+	"strings"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	root := NewRootCommand()
+
+	for _, path := range []string{"serve", "migrate", "seed", "import", "export", "backup", "restore", "books list", "books get", "books delete", "trash list", "trash restore", "trash purge", "user create", "user list"} {
+		if cmd, _, err := root.Find(strings.Fields(path)); err != nil || cmd.Name() != strings.Fields(path)[len(strings.Fields(path))-1] {
+			t.Errorf("got %v, expected the %q command", err, path)
+		}
+	}
+}
+
+func TestImportUnknownFormat(t *testing.T) {
+	root := NewRootCommand()
+	root.SetArgs([]string{"import", "--format", "librarything"})
+	root.SetIn(strings.NewReader("title\nDune\n"))
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+
+	// The file is checked before the configuration is loaded, so no database is needed
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("got error %v, expected an unknown format", err)
+	}
+}
+
+func TestSeedBooks(t *testing.T) {
+	reader, err := bookcsv.NewReader(bytes.NewBufferString(seedBooks), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := 0
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			break
+		}
+		if record.Err != nil || record.Book.Title == "" {
+			t.Errorf("got %+v on line %d, expected a valid book", record.Err, record.Line)
+		}
+		rows++
+	}
+
+	if rows == 0 {
+		t.Error("expected sample books")
+	}
+}
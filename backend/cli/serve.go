@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"readinglistapp/config"
+	"readinglistapp/internal"
+	"readinglistapp/server"
+	"readinglistapp/session"
+	"readinglistapp/settings"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the web server, over TLS when a certificate is configured",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer cleanup(app.DB.Close)
+
+			return serve(app, cmd.OutOrStdout())
+		},
+	}
+}
+
+/*
+serve applies pending migrations unless disabled, sets up the router and listens until the process receives
+SIGINT or SIGTERM, then shuts the server down gracefully. Meanwhile books that have been in the trash for longer
+than the retention period are purged.
+*/
+func serve(app *internal.App, out io.Writer) error {
+	cfg := app.Config
+
+	if cfg.DB.MigrateOnStart {
+		if err := migrate(app, nil, out); err != nil {
+			return err
+		}
+	}
+
+	sessions, err := newSessionManager(cfg)
+	if err != nil {
+		return err
+	}
+	app.Sessions = sessions
+
+	router := config.SetUpRouter(app)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Trash.Retention > 0 {
+		go purgeTrash(ctx, app, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
+	}
+
+	fmt.Fprintf(out, "\nListening on port: %s (TLS: %t)\n", cfg.Port, cfg.TLSEnabled())
+
+	if err := server.Run(ctx, serverOptions(cfg), router); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+/*
+purgeTrash purges the books deleted more than retention ago now and then every interval. It blocks until ctx
+is cancelled. Failures are logged and retried at the next interval.
+*/
+func purgeTrash(ctx context.Context, app *internal.App, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := app.Model.PurgeTrash(app.Books, app.GetBookDependents(), retention, time.Now())
+		if err != nil {
+			log.Printf("purging the trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d books deleted more than %s ago", purged, retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+serverOptions maps the listener settings of the configuration onto server.Options.
+*/
+func serverOptions(cfg *settings.Config) server.Options {
+	return server.Options{
+		Port:               cfg.Port,
+		CertFile:           cfg.TLS.CertFile,
+		KeyFile:            cfg.TLS.KeyFile,
+		MinTLSVersion:      cfg.TLS.MinVersion,
+		CipherSuites:       cfg.TLS.CipherSuites,
+		RedirectPort:       cfg.TLS.RedirectPort,
+		CertReloadInterval: cfg.TLS.ReloadInterval,
+	}
+}
+
+/*
+newSessionManager creates the session manager used by the server-rendered pages.
+Cookies are signed with the session secret; without it a random secret is used and sessions are lost on restart.
+*/
+func newSessionManager(cfg *settings.Config) (*session.Manager, error) {
+	if cfg.Session.Secret == "" {
+		log.Println("SESSION_SECRET is not set, using a random session secret.")
+	}
+
+	sessions, err := session.NewManager([]byte(cfg.Session.Secret), cfg.Session.Lifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions.Secure = cfg.TLSEnabled() || strings.HasPrefix(cfg.SiteURL, "https://")
+
+	return sessions, nil
+}
+
+/*
+Closes DB connection
+*/
+func cleanup(disconnect func()) {
+	fmt.Println("\nExecuting Clean Up...")
+	defer disconnect()
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newTrashCommand(open appOpener) *cobra.Command {
+	trash := &cobra.Command{
+		Use:   "trash",
+		Short: "List, restore and purge deleted books",
+	}
+
+	trash.AddCommand(newTrashListCommand(open), newTrashRestoreCommand(open), newTrashPurgeCommand(open))
+
+	return trash
+}
+
+func newTrashListCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the books in the trash, like GET /v1/trash",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			books, err := app.Model.GetTrash(app.Books)
+			if err != nil {
+				return err
+			}
+
+			table := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(table, "ID\tDELETED\tTITLE\tAUTHORS")
+
+			for _, book := range books {
+				fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", book.ID, book.DeletedAt.Format(time.DateTime), book.Title, strings.Join(book.Authors, ", "))
+			}
+
+			return table.Flush()
+		},
+	}
+}
+
+func newTrashRestoreCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <id>",
+		Short: "Take a book out of the trash",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			book, err := app.Model.RestoreBook(app.Books, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored book %s (%s)\n", book.ID, book.Title)
+			return nil
+		},
+	}
+}
+
+func newTrashPurgeCommand(open appOpener) *cobra.Command {
+	var olderThan time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "purge [id]",
+		Short: "Permanently delete a book from the trash, or every book in it",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			if len(args) == 1 {
+				if err := app.Model.PurgeBook(app.Books, app.GetBookDependents(), args[0]); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Purged book %s\n", args[0])
+				return nil
+			}
+
+			purged, err := app.Model.PurgeTrash(app.Books, app.GetBookDependents(), olderThan, time.Now())
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Purged %d books\n", purged)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "only purge the books deleted longer ago than this, such as 720h")
+
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"readinglistapp/model"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newUserCommand(open appOpener) *cobra.Command {
+	user := &cobra.Command{
+		Use:   "user",
+		Short: "Create and list the users books and goals belong to",
+	}
+
+	user.AddCommand(newUserCreateCommand(open), newUserListCommand(open))
+
+	return user
+}
+
+func newUserCreateCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a user, whose name books and goals give as their owner",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			_, user, err := app.Model.CreateUser(app.Users, model.UserInput{Name: args[0]})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Created user %s (%s)\n", user.ID, user.Name)
+			return nil
+		},
+	}
+}
+
+func newUserListCommand(open appOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the users",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := open()
+			if err != nil {
+				return err
+			}
+			defer app.DB.Close()
+
+			users, err := app.Model.GetUsers(app.Users)
+			if err != nil {
+				return err
+			}
+
+			table := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(table, "ID\tCREATED\tNAME")
+
+			for _, user := range users {
+				fmt.Fprintf(table, "%s\t%s\t%s\n", user.ID, user.CreatedAt.Format(time.DateTime), user.Name)
+			}
+
+			return table.Flush()
+		},
+	}
+}
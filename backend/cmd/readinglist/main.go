@@ -0,0 +1,15 @@
+package main
+
+import (
+	"os"
+	"readinglistapp/cli"
+)
+
+/*
+main runs the readinglist command line: serve, migrate, seed, import, export, backup, restore, books, trash and user.
+*/
+func main() {
+	if err := cli.Execute(os.Args[1:]); err != nil {
+		os.Exit(1)
+	}
+}
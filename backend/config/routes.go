@@ -3,7 +3,10 @@ package config
 import (
 	"net/http"
 	"readinglistapp/internal"
+	"readinglistapp/middleware"
 	"readinglistapp/routes"
+	"readinglistapp/session"
+	"readinglistapp/settings"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -12,7 +15,8 @@ import (
 /*
 SetUpRouter creates and configures a new HTTP router using mux.Router.
 It sets up routes defined in the routes package, enables handling of trailing slashes,
-and applies CORS (Cross-Origin Resource Sharing) middleware to allow requests from any origin.
+wraps the server-rendered pages with session and CSRF middleware, adds security headers
+for the current environment and configuration, and applies CORS (Cross-Origin Resource Sharing) middleware to allow requests from any origin.
 
 Returns:
 
@@ -25,12 +29,56 @@ func SetUpRouter(app internal.IApp) http.Handler {
 
 	muxRouter.StrictSlash(false)
 
+	handler := middleware.Sessions(app.GetSessions())(middleware.CSRF(muxRouter))
+	handler = middleware.SecurityHeaders(securityHeaders(app.GetConfig()))(handler)
+
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"}, // Allow requests from your React app's origin
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", session.CSRFHeader},
 		AllowCredentials: true, // Allow sending cookies and credentials
-	}).Handler(muxRouter)
+	}).Handler(handler)
 
 	return corsHandler
 }
+
+/*
+securityHeaders applies the header settings of the configuration over the defaults of its environment.
+The configuration has been validated, so the trusted proxies parse.
+*/
+func securityHeaders(cfg *settings.Config) middleware.SecurityHeadersOptions {
+	opts := middleware.DefaultSecurityHeaders(cfg.IsProduction())
+	headers := cfg.Headers
+
+	if headers.ContentSecurityPolicy != nil {
+		opts.ContentSecurityPolicy = *headers.ContentSecurityPolicy
+	}
+
+	if headers.CSPReportOnly != nil {
+		opts.CSPReportOnly = *headers.CSPReportOnly
+	}
+
+	if headers.FrameOptions != nil {
+		opts.FrameOptions = *headers.FrameOptions
+	}
+
+	if headers.ReferrerPolicy != nil {
+		opts.ReferrerPolicy = *headers.ReferrerPolicy
+	}
+
+	if headers.PermissionsPolicy != nil {
+		opts.PermissionsPolicy = *headers.PermissionsPolicy
+	}
+
+	if headers.HSTSMaxAge != nil {
+		opts.HSTSMaxAge = *headers.HSTSMaxAge
+	}
+
+	if headers.HSTSIncludeSubdomains != nil {
+		opts.HSTSIncludeSubdomains = *headers.HSTSIncludeSubdomains
+	}
+
+	opts.TrustedProxies, _ = headers.Proxies()
+
+	return opts
+}
@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+
+	"github.com/gorilla/mux"
+)
+
+/*
+AuthorList displays the authors page.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func AuthorList(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection) {
+	authors, err := m.GetAuthors(authorCollection)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	err = v.AuthorList(w, r, authors)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+}
+
+/*
+AuthorView displays an author's page with their books.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func AuthorView(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection, bookCollection initialisers.IBookCollection) {
+	id := r.URL.Query().Get("id")
+
+	if len(id) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	author, err := m.GetAuthor(authorCollection, id)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	books, err := m.GetAuthorBooks(authorCollection, bookCollection, id)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	err = v.AuthorView(w, r, author, books)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+}
+
+/*
+GetAuthorsHandler lists every author, ordered by sort name.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetAuthorsHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection) {
+	authors, err := m.GetAuthors(authorCollection)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"authors": authors})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+CreateAuthorHandler creates an author from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func CreateAuthorHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection) {
+	var input model.AuthorInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	_, author, err := m.CreateAuthor(authorCollection, input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("v1/authors/%s", author.ID))
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"author": author})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, headers)
+}
+
+/*
+GetAuthor retrieves an author by ID.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetAuthor(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection) {
+	author, err := m.GetAuthor(authorCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"author": author})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetAuthorBooks lists the books linked to an author.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetAuthorBooks(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection, bookCollection initialisers.IBookCollection) {
+	books, err := m.GetAuthorBooks(authorCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"books": books})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+UpdateAuthor applies the fields present in the JSON request body to an author.
+Renaming an author also renames them on their books.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func UpdateAuthor(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection, bookCollection initialisers.IBookCollection, transactor initialisers.ITransactor) {
+	author, err := m.GetAuthor(authorCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	var input struct {
+		Name      *string  `json:"name"`
+		SortName  *string  `json:"sortName"`
+		BirthYear *int     `json:"birthYear"`
+		DeathYear *int     `json:"deathYear"`
+		Bio       *string  `json:"bio"`
+		Aliases   []string `json:"aliases"`
+	}
+
+	err = v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	if input.Name != nil {
+		author.Name = *input.Name
+	}
+
+	if input.SortName != nil {
+		author.SortName = *input.SortName
+	}
+
+	if input.BirthYear != nil {
+		author.BirthYear = *input.BirthYear
+	}
+
+	if input.DeathYear != nil {
+		author.DeathYear = *input.DeathYear
+	}
+
+	if input.Bio != nil {
+		author.Bio = *input.Bio
+	}
+
+	if input.Aliases != nil {
+		author.Aliases = input.Aliases
+	}
+
+	author.Version++
+
+	err = m.UpdateAuthor(authorCollection, bookCollection, transactor, author)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"author": author})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+DeleteAuthor deletes an author. Their books keep the author's name but are no longer linked to them.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func DeleteAuthor(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection, bookCollection initialisers.IBookCollection, transactor initialisers.ITransactor) {
+	err := m.DeleteAuthor(authorCollection, bookCollection, transactor, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "author successfully deleted"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+MergeAuthors merges the duplicate authors listed in the request body ({"sourceIds": [...]}) into the author
+in the URL, re-pointing their books and keeping their names as aliases.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func MergeAuthors(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, authorCollection initialisers.IAuthorCollection, bookCollection initialisers.IBookCollection, transactor initialisers.ITransactor) {
+	var input struct {
+		SourceIDs []string `json:"sourceIds"`
+	}
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	author, err := m.MergeAuthors(authorCollection, bookCollection, transactor, mux.Vars(r)["id"], input.SourceIDs)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"author": author})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"readinglistapp/backup"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+	"time"
+)
+
+// maxBackupBytes limits the size of an uploaded backup.
+const maxBackupBytes = 100 << 20
+
+/*
+BackupLibrary downloads a backup of every book and related collection as gzip-compressed JSON Lines,
+streaming it as it is read from the database.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func BackupLibrary(w http.ResponseWriter, r *http.Request, m model.IModelFuncs, bookCollection initialisers.IBookCollection, documents initialisers.IDocumentStore) {
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", backup.Filename(time.Now())))
+
+	// The status is sent with the first bytes, so a failure part way only cuts the download short;
+	// restoring a cut backup fails its checksum
+	if _, err := m.Backup(bookCollection, documents, w); err != nil {
+		log.Println(err)
+	}
+}
+
+/*
+RestoreLibrary restores the backup in the request body and reports what it did with each collection.
+The mode query parameter is merge (the default), which only adds what is missing, or replace, which makes the
+library the backup. Nothing is written when the file is corrupt, truncated or not a backup.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func RestoreLibrary(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, documents initialisers.IDocumentStore, transactor initialisers.ITransactor) {
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = model.RestoreMerge
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBackupBytes)
+
+	report, err := m.Restore(bookCollection, documents, transactor, r.Body, mode)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"restore": report})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
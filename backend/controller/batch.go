@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+)
+
+/*
+BatchBooks applies the creates, updates and deletes listed in the JSON request body
+({"atomic": false, "operations": [{"op": "update", "id": "...", "book": {...}}]}).
+Each result carries the status code the single-item endpoint would have answered with.
+The batch answers 200 unless it is atomic and an operation failed, in which case nothing is kept and it answers
+with the status of that operation.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func BatchBooks(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, authorCollection initialisers.IAuthorCollection, transactor initialisers.ITransactor) {
+	var input model.BatchInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	results, err := m.Batch(bookCollection, authorCollection, transactor, input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	status := http.StatusOK
+	for _, result := range results {
+		setBatchResultStatus(result)
+
+		if input.Atomic && result.Err != nil && !errors.Is(result.Err, model.ErrBatchAborted) {
+			status = result.Status
+		}
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"atomic": input.Atomic, "results": results})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, status, jsonResponse, nil)
+}
+
+/*
+setBatchResultStatus fills in the status and error message of a batch result like isStorageError would.
+Server errors are logged and reported by their status text only.
+*/
+func setBatchResultStatus(result *model.BatchResult) {
+	if result.Err == nil {
+		result.Status = http.StatusOK
+		if result.Op == model.BatchCreate {
+			result.Status = http.StatusCreated
+		}
+		return
+	}
+
+	result.Status = storageErrorStatus(result.Err)
+
+	var validationErr *model.ValidationError
+
+	switch {
+	case errors.As(result.Err, &validationErr):
+		result.Error = validationErr.Error()
+	case errors.Is(result.Err, model.ErrBatchAborted):
+		result.Error = result.Err.Error()
+	default:
+		if result.Status >= http.StatusInternalServerError {
+			log.Println(result.Err)
+		}
+		result.Error = http.StatusText(result.Status)
+	}
+}
@@ -1,16 +1,23 @@
 package controller
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
-	"os"
 	helper "readinglistapp/helper"
 	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
 	"readinglistapp/model"
+	"readinglistapp/session"
+	"readinglistapp/settings"
 	"readinglistapp/view"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -23,8 +30,10 @@ Parameters:
 
 	param1: w http.ResponseWriter
 	param2: r *http.Request
+	param3: view.IViewFuncs
+	param4: pointer settings.Config
 */
-func HealthCheck(w http.ResponseWriter, r *http.Request, v view.IViewFuncs) {
+func HealthCheck(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, cfg *settings.Config) {
 	if r.Method != http.MethodGet {
 		helper.HandleHTTPStatusError(w, http.StatusMethodNotAllowed)
 		return
@@ -32,8 +41,8 @@ func HealthCheck(w http.ResponseWriter, r *http.Request, v view.IViewFuncs) {
 
 	res := model.ResponseHealthCheck{
 		Endpoint:    "Health Check Endpoint",
-		Environment: os.Getenv("ENV"),
-		Version:     os.Getenv("VERSION"),
+		Environment: cfg.Env,
+		Version:     cfg.Version,
 	}
 
 	jsonResponse, err := v.RenderJSON(view.Envelope{"healthcheck": res})
@@ -45,27 +54,83 @@ func HealthCheck(w http.ResponseWriter, r *http.Request, v view.IViewFuncs) {
 	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
 }
 
+/*
+Readiness handles the readiness endpoint.
+It pings the database and reports the storage circuit breaker state, answering 503 when either
+means requests can't currently be served.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+	param3: view.IViewFuncs
+	param4: pointer initialisers.DB
+	param5: initialisers.IBookCollection
+*/
+func Readiness(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, db *initialisers.DB, bookCollection initialisers.IBookCollection) {
+	if r.Method != http.MethodGet {
+		helper.HandleHTTPStatusError(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res := model.ResponseReadiness{Status: "ready", Database: "up"}
+	status := http.StatusOK
+
+	if err := db.Ping(ctx); err != nil {
+		log.Println(err)
+		res.Database = "down"
+	}
+
+	if cb, ok := bookCollection.(*initialisers.CircuitBreaker); ok {
+		breaker := cb.Status()
+		res.CircuitBreaker = &breaker
+	}
+
+	if res.Database == "down" || (res.CircuitBreaker != nil && res.CircuitBreaker.State == initialisers.BreakerOpen) {
+		res.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"readiness": res})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, status, jsonResponse, nil)
+}
+
 /*
 Home displays the home page of the application.
-It retrieves all books from the model and renders them using the view.BookHome function.
+It retrieves all books from the model, optionally filtered by reading status and tag, and renders them with the
+progress of the current reading goals using the view.BookHome function. The home page has no signed in user,
+so it shows the goals with an empty owner, counted from the books nobody owns.
 
 Parameters:
 
 	param1: w http.ResponseWriter
 	param2: r *http.Request
 */
-func Home(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+func Home(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, goalCollection initialisers.IGoalCollection, tagCollection initialisers.ITagCollection) {
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
 		return
 	}
 
-	books, err := m.GetAll(bookCollection)
-	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+	books, err := getBooks(r, m, bookCollection, tagCollection)
+	if isStorageError(w, err) {
+		return
+	}
+
+	goals, err := m.GetCurrentGoalsProgress(goalCollection, bookCollection, "")
+	if isStorageError(w, err) {
 		return
 	}
 
-	err = v.BookHome(w, books)
+	err = v.BookHome(w, r, books, r.URL.Query().Get("status"), goals)
 
 	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
 		return
@@ -82,7 +147,7 @@ Parameters:
 	param1: w http.ResponseWriter
 	param2: r *http.Request
 */
-func BookView(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+func BookView(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, reviewCollection initialisers.IReviewCollection, noteCollection initialisers.INoteCollection) {
 	id := r.URL.Query().Get("id")
 
 	if len(id) == 0 {
@@ -92,11 +157,23 @@ func BookView(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model
 
 	book, err := m.Get(bookCollection, id)
 
-	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+	if isStorageError(w, err) {
 		return
 	}
 
-	err = v.BookView(w, id, book)
+	reviews, err := m.GetReviews(reviewCollection, bookCollection, id)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	notes, err := m.GetNotes(noteCollection, bookCollection, id)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	err = v.BookView(w, r, id, book, reviews, notes)
 
 	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
 		return
@@ -111,11 +188,14 @@ Parameters:
 
 	param1: w http.ResponseWriter
 	param2: r *http.Request
+	param3: view.IViewFuncs
+	param4: model.IModelFuncs
+	param5: initialisers.IBookCollection
 */
-func BookCreate(w http.ResponseWriter, r *http.Request, v view.IViewFuncs) {
+func BookCreate(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
 	switch r.Method {
 	case http.MethodGet:
-		err := v.BookCreateForm(w, r)
+		err := v.BookCreateForm(w, r, "")
 
 		if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
 			return
@@ -128,51 +208,97 @@ func BookCreate(w http.ResponseWriter, r *http.Request, v view.IViewFuncs) {
 			return
 		}
 
-		bookCreateProcess(w, r, data)
+		bookCreateProcess(w, r, v, m, bookCollection, data)
 	default:
 		helper.HandleHTTPStatusError(w, http.StatusMethodNotAllowed)
 	}
 }
 
 /*
-bookCreateProcess sends a POST request to create a book.
-It constructs an HTTP request with the provided data, sends it to the specified endpoint, and handles the response.
+bookCreateProcess creates a book from the JSON the form was turned into, through the model like CreateBooksHandler.
+On success a flash message naming the created book is queued before redirecting home.
+A book the model rejects, for an invalid or already used ISBN, gets the form back with the reason.
 
 Parameters:
 
 	param1: w http.ResponseWriter
 	param2: r *http.Request
-	param3: data []byte The JSON data to be sent in the request body.
+	param3: view.IViewFuncs
+	param4: model.IModelFuncs
+	param5: initialisers.IBookCollection
+	param6: data []byte The JSON data of the book.
 */
-func bookCreateProcess(w http.ResponseWriter, r *http.Request, data []byte) {
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s:%s/v1/books", os.Getenv("SITE_URL"), os.Getenv("PORT")), bytes.NewBuffer(data))
+func bookCreateProcess(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, data []byte) {
+	var input model.Input
+
+	err := json.Unmarshal(data, &input)
 
 	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
 		return
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	_, book, err := m.Insert(bookCollection, input)
 
-	client := &http.Client{}
-	response, err := client.Do(req)
+	var validationErr *model.ValidationError
 
-	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+	switch {
+	case errors.Is(err, initialisers.ErrDuplicateRecord), errors.As(err, &validationErr):
+		problem := "A book with this ISBN already exists"
+		if validationErr != nil {
+			problem = validationErr.Error()
+		}
+
+		w.WriteHeader(storageErrorStatus(err))
+
+		if err := v.BookCreateForm(w, r, problem); err != nil {
+			log.Printf("render create form: %v", err)
+		}
+		return
+	case isStorageError(w, err):
+		return
+	}
+
+	session.AddFlash(r, fmt.Sprintf("Book '%s' created", book.Title))
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+/*
+BookDelete handles the delete form on the book view page.
+It deletes the book identified by the posted id, queues a flash message and redirects home.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func BookDelete(w http.ResponseWriter, r *http.Request, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+	if r.Method != http.MethodPost {
+		helper.HandleHTTPStatusError(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PostFormValue("id")
+
+	if len(id) == 0 {
+		helper.HandleHTTPStatusError(w, http.StatusBadRequest)
 		return
 	}
 
-	defer response.Body.Close()
+	err := m.Delete(bookCollection, id)
 
-	if response.StatusCode != http.StatusCreated {
-		log.Printf("unexpected status: %s", response.Status)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	if isStorageError(w, err) {
 		return
 	}
 
+	session.AddFlash(r, "Book moved to the trash")
+
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
 /*
-GetBooksHandler retrieves all books.
+GetBooksHandler retrieves all books, or those with the reading statuses in the status query parameter
+(?status=reading,finished) and the tag in the tag query parameter, including narrower tags (?tag=fantasy).
 It fetches books from the model, renders them as JSON, and sends an HTTP response.
 
 Parameters:
@@ -180,11 +306,11 @@ Parameters:
 	param1: w http.ResponseWriter
 	param2: r *http.Request
 */
-func GetBooksHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+func GetBooksHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, tagCollection initialisers.ITagCollection) {
 	fmt.Println("GetBooksHandler")
-	books, err := m.GetAll(bookCollection)
+	books, err := getBooks(r, m, bookCollection, tagCollection)
 
-	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+	if isStorageError(w, err) {
 		return
 	}
 
@@ -199,7 +325,7 @@ func GetBooksHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs,
 
 /*
 The CreateBooksHandler function handles the creation of books.
-It reads JSON input from the request, inserts the book into the model,
+It reads JSON input from the request, resolves the names of linked authors, inserts the book into the model,
 and returns a JSON response with appropriate status codes and headers.
 
 Parameters:
@@ -207,7 +333,7 @@ Parameters:
 	param1: w http.ResponseWriter
 	param2: r *http.Request
 */
-func CreateBooksHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+func CreateBooksHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, authorCollection initialisers.IAuthorCollection) {
 	var input model.Input
 
 	err := v.ReadJSON(w, r, &input)
@@ -216,9 +342,18 @@ func CreateBooksHandler(w http.ResponseWriter, r *http.Request, v view.IViewFunc
 		return
 	}
 
+	// Linked authors take precedence over author names sent as plain text
+	if len(input.AuthorIDs) > 0 {
+		input.Authors, err = m.ResolveAuthors(authorCollection, input.AuthorIDs)
+
+		if isStorageError(w, err) {
+			return
+		}
+	}
+
 	id, book, err := m.Insert(bookCollection, input)
 
-	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+	if isStorageError(w, err) {
 		return
 	}
 
@@ -256,13 +391,32 @@ func GetBook(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.
 
 	book, err := m.Get(bookCollection, id)
 
-	if err != nil {
-		switch {
-		case errors.Is(err, errors.New("record not found")):
-			helper.LogHTTPStatusError(w, err, http.StatusNotFound)
-		default:
-			helper.LogHTTPStatusError(w, err, http.StatusInternalServerError)
-		}
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"book": book})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetBookByISBN retrieves a book of the owner in the owner query parameter, nobody by default, by its ISBN-10 or
+ISBN-13, with or without hyphens. It answers 422 for an invalid ISBN and 404 when no book of the owner has it.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetBookByISBN(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+	book, err := m.GetByISBN(bookCollection, r.URL.Query().Get("owner"), mux.Vars(r)["isbn"])
+
+	if isStorageError(w, err) {
 		return
 	}
 
@@ -286,7 +440,7 @@ Parameters:
 	param1: http.ResponseWriter
 	param2: *http.Request
 */
-func UpdateBook(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+func UpdateBook(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, authorCollection initialisers.IAuthorCollection) {
 	var err error
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -298,23 +452,11 @@ func UpdateBook(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m mod
 
 	book, err := m.Get(bookCollection, id)
 
-	if err != nil {
-		switch {
-		case errors.Is(err, errors.New("record not found")):
-			helper.HandleHTTPStatusError(w, http.StatusNotFound)
-		default:
-			helper.HandleHTTPStatusError(w, http.StatusInternalServerError)
-		}
+	if isStorageError(w, err) {
 		return
 	}
 
-	var input struct {
-		Title     *string  `json:"title"`
-		Published *int     `json:"published"`
-		Pages     *int     `json:"pages"`
-		Genres    []string `json:"genres"`
-		Rating    *float64 `json:"rating"`
-	}
+	var input model.UpdateInput
 
 	err = v.ReadJSON(w, r, &input)
 
@@ -322,29 +464,15 @@ func UpdateBook(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m mod
 		return
 	}
 
-	if input.Title != nil {
-		book.Title = *input.Title
-	}
+	err = m.ApplyUpdate(authorCollection, book, input)
 
-	if input.Published != nil {
-		book.Published = *input.Published
-	}
-
-	if input.Pages != nil {
-		book.Pages = *input.Pages
-	}
-
-	if len(input.Genres) > 0 {
-		book.Genres = input.Genres
-	}
-
-	if input.Rating != nil {
-		book.Rating = *input.Rating
+	if isStorageError(w, err) {
+		return
 	}
 
 	err = m.Update(bookCollection, id, book)
 
-	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+	if isStorageError(w, err) {
 		return
 	}
 
@@ -360,8 +488,8 @@ func UpdateBook(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m mod
 /*
 DeleteBook handles the deletion of a book identified by its ID.
 It retrieves the ID from the request parameters,
-attempts to delete the corresponding record in the model layer, and returns an appropriate JSON response
-with the status code indicating success or failure.
+moves the corresponding record to the trash in the model layer, and returns an appropriate JSON response
+with the status code indicating success or failure. The book can be restored until it is purged.
 
 Parameters:
 
@@ -380,21 +508,11 @@ func DeleteBook(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m mod
 
 	err = m.Delete(bookCollection, id)
 
-	if err != nil {
-		switch {
-		case errors.Is(err, errors.New("record not found")):
-			if helper.IsHTTPStatusError(w, err, http.StatusNotFound) {
-				return
-			}
-		default:
-			if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
-				return
-			}
-		}
+	if isStorageError(w, err) {
 		return
 	}
 
-	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "book successfully deleted"})
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "book moved to the trash"})
 
 	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
 		return
@@ -403,6 +521,139 @@ func DeleteBook(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m mod
 	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
 }
 
+/*
+UpdateBookProgress handles PATCH /v1/books/{id}/progress, recording the current page and/or reading status
+from the JSON request body ({"currentPage": 120, "status": "reading"}).
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func UpdateBookProgress(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+	var input model.ProgressInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	book, err := m.UpdateProgress(bookCollection, mux.Vars(r)["id"], input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"book": book})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+getBooks returns every book, or only those of the owner in the owner query parameter, with the statuses listed in
+the status query parameter and the tag in the tag query parameter or any tag below it.
+*/
+func getBooks(r *http.Request, m model.IModelFuncs, bookCollection initialisers.IBookCollection, tagCollection initialisers.ITagCollection) ([]*data.Book, error) {
+	statuses, err := model.ParseStatusFilter(r.URL.Query().Get("status"))
+	if err != nil {
+		return nil, err
+	}
+
+	filter := data.BookFilter{Statuses: statuses}
+
+	if owner := strings.TrimSpace(r.URL.Query().Get("owner")); owner != "" {
+		filter.Owners = []string{owner}
+	}
+
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		filter.Tags, err = m.ExpandTag(tagCollection, tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Owners) == 0 && len(filter.Statuses) == 0 && len(filter.Tags) == 0 {
+		return m.GetAll(bookCollection)
+	}
+
+	return m.GetFiltered(bookCollection, filter)
+}
+
+/*
+isStorageError checks if there is an error returned by the model or storage backend. If an error is present,
+it logs it and sends the matching HTTP error response: 404 for unknown records, 409 for duplicates,
+422 with the problem for invalid input, 503 with a Retry-After header while the circuit breaker is open
+and 500 otherwise.
+It returns true if there is an error, otherwise false.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: error
+
+Returns:
+
+	return1: boolean
+*/
+func isStorageError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var openErr *initialisers.CircuitOpenError
+	var validationErr *model.ValidationError
+
+	status := storageErrorStatus(err)
+
+	switch {
+	case errors.As(err, &validationErr):
+		http.Error(w, validationErr.Error(), status)
+	case errors.As(err, &openErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(openErr.RetryAfter.Seconds()))))
+		helper.LogHTTPStatusError(w, err, status)
+	default:
+		helper.LogHTTPStatusError(w, err, status)
+	}
+
+	return true
+}
+
+/*
+storageErrorStatus maps an error from the model layer onto an HTTP status code.
+
+Parameters:
+
+	param1: error, not nil
+
+Returns:
+
+	return1: int, HTTP status code
+*/
+func storageErrorStatus(err error) int {
+	var openErr *initialisers.CircuitOpenError
+	var validationErr *model.ValidationError
+
+	switch {
+	case errors.Is(err, initialisers.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, initialisers.ErrDuplicateRecord), errors.Is(err, initialisers.ErrEditConflict):
+		return http.StatusConflict
+	case errors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, model.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.As(err, &openErr):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 /*
 writeJSONResponse writes a JSON response to the provided http.ResponseWriter with the specified status code,
 JSON content, and headers.
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+
+	"github.com/gorilla/mux"
+)
+
+/*
+GetGoalsHandler lists the goals of the owner named by the owner query parameter.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetGoalsHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, goalCollection initialisers.IGoalCollection) {
+	goals, err := m.GetGoals(goalCollection, r.URL.Query().Get("owner"))
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"goals": goals})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+CreateGoalHandler creates a reading goal from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func CreateGoalHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, goalCollection initialisers.IGoalCollection) {
+	var input model.GoalInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	_, goal, err := m.CreateGoal(goalCollection, input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("v1/goals/%s", goal.ID))
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"goal": goal})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, headers)
+}
+
+/*
+GetGoal retrieves a reading goal.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetGoal(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, goalCollection initialisers.IGoalCollection) {
+	goal, err := m.GetGoal(goalCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"goal": goal})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+UpdateGoal applies the fields present in the JSON request body to a reading goal.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func UpdateGoal(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, goalCollection initialisers.IGoalCollection) {
+	goal, err := m.GetGoal(goalCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	var input struct {
+		Metric *string `json:"metric"`
+		Year   *int    `json:"year"`
+		Month  *int    `json:"month"`
+		Target *int    `json:"target"`
+	}
+
+	err = v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	if input.Metric != nil {
+		goal.Metric = *input.Metric
+	}
+
+	if input.Year != nil {
+		goal.Year = *input.Year
+	}
+
+	if input.Month != nil {
+		goal.Month = *input.Month
+	}
+
+	if input.Target != nil {
+		goal.Target = *input.Target
+	}
+
+	goal.Version++
+
+	err = m.UpdateGoal(goalCollection, goal)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"goal": goal})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+DeleteGoal deletes a reading goal.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func DeleteGoal(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, goalCollection initialisers.IGoalCollection) {
+	err := m.DeleteGoal(goalCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "goal successfully deleted"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetGoalProgress reports the progress of a reading goal.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetGoalProgress(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, goalCollection initialisers.IGoalCollection, bookCollection initialisers.IBookCollection) {
+	progress, err := m.GetGoalProgress(goalCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"progress": progress})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetCurrentGoalsProgress reports the progress of the goals for the current year and month of the owner named by
+the owner query parameter.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetCurrentGoalsProgress(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, goalCollection initialisers.IGoalCollection, bookCollection initialisers.IBookCollection) {
+	progress, err := m.GetCurrentGoalsProgress(goalCollection, bookCollection, r.URL.Query().Get("owner"))
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"progress": progress})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
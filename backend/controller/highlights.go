@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+	"regexp"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// unsafeFilename matches the characters replaced when a book title is used as a download filename.
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+/*
+GetBookHighlights lists the highlights of a book in reading order.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetBookHighlights(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, highlightCollection initialisers.IHighlightCollection, bookCollection initialisers.IBookCollection) {
+	highlights, err := m.GetBookHighlights(highlightCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"highlights": highlights})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+CreateHighlight adds a highlight to a book from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func CreateHighlight(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, highlightCollection initialisers.IHighlightCollection, bookCollection initialisers.IBookCollection) {
+	var input model.HighlightInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	highlight, err := m.CreateHighlight(highlightCollection, bookCollection, mux.Vars(r)["id"], input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"highlight": highlight})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, nil)
+}
+
+/*
+UpdateHighlight applies the fields present in the JSON request body to a highlight.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func UpdateHighlight(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, highlightCollection initialisers.IHighlightCollection, bookCollection initialisers.IBookCollection) {
+	vars := mux.Vars(r)
+
+	highlight, err := m.GetHighlight(highlightCollection, vars["id"], vars["highlightId"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	var input struct {
+		Quote    *string  `json:"quote"`
+		Page     *int     `json:"page"`
+		Location *string  `json:"location"`
+		Comment  *string  `json:"comment"`
+		Tags     []string `json:"tags"`
+	}
+
+	err = v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	if input.Quote != nil {
+		highlight.Quote = *input.Quote
+	}
+
+	if input.Page != nil {
+		highlight.Page = *input.Page
+	}
+
+	if input.Location != nil {
+		highlight.Location = *input.Location
+	}
+
+	if input.Comment != nil {
+		highlight.Comment = *input.Comment
+	}
+
+	if input.Tags != nil {
+		highlight.Tags = input.Tags
+	}
+
+	highlight.Version++
+
+	err = m.UpdateHighlight(highlightCollection, bookCollection, highlight)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"highlight": highlight})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+DeleteHighlight deletes a highlight from a book.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func DeleteHighlight(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, highlightCollection initialisers.IHighlightCollection) {
+	vars := mux.Vars(r)
+
+	err := m.DeleteHighlight(highlightCollection, vars["id"], vars["highlightId"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "highlight successfully deleted"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+ExportHighlights downloads the highlights of a book as a markdown document.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func ExportHighlights(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, highlightCollection initialisers.IHighlightCollection, bookCollection initialisers.IBookCollection) {
+	id := mux.Vars(r)["id"]
+
+	book, err := m.Get(bookCollection, id)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	highlights, err := m.GetBookHighlights(highlightCollection, bookCollection, id)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	filename := unsafeFilename.ReplaceAllString(book.Title, "-")
+	if filename == "" || filename == "-" {
+		filename = book.ID
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"-highlights.md"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(v.RenderHighlightsMarkdown(book, highlights))
+}
+
+/*
+GetHighlights lists the highlights across the library, most recent first, optionally only those with the tag query parameter.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetHighlights(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, highlightCollection initialisers.IHighlightCollection, bookCollection initialisers.IBookCollection) {
+	highlights, err := m.GetHighlights(highlightCollection, bookCollection, r.URL.Query().Get("tag"))
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"highlights": highlights})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+HighlightOfTheDay returns a highlight picked for today, the same one all day. The highlight is null when there are none.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func HighlightOfTheDay(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, highlightCollection initialisers.IHighlightCollection, bookCollection initialisers.IBookCollection) {
+	highlight, err := m.HighlightOfTheDay(highlightCollection, bookCollection, time.Now())
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"highlight": highlight})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
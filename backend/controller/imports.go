@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"readinglistapp/bookcsv"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"readinglistapp/model"
+	"readinglistapp/view"
+	"strconv"
+	"strings"
+)
+
+// maxImportBytes limits the size of an uploaded import file.
+const maxImportBytes = 10 << 20
+
+// mappingParam prefixes the query parameters naming the CSV header a column is read from (?map.title=Book%20Title).
+const mappingParam = "map."
+
+/*
+ExportBooksCSV downloads every book as CSV with the columns in bookcsv.Columns, streaming them from the database
+as they are written. List values such as genres are joined by bookcsv.ListDelimiter.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func ExportBooksCSV(w http.ResponseWriter, r *http.Request, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+	writer := bookcsv.NewWriter(w)
+	started := false
+
+	err := m.ExportBooks(bookCollection, func(book *data.Book) error {
+		if !started {
+			setCSVHeaders(w, "books.csv")
+			started = true
+		}
+
+		return writer.Write(book)
+	})
+
+	if !started && isStorageError(w, err) {
+		return
+	}
+
+	// Once rows were sent the status can't change, so the download is cut short
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	if !started {
+		setCSVHeaders(w, "books.csv")
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Println(err)
+	}
+}
+
+/*
+ImportBooksCSV imports the books in the CSV request body and reports what happened to each row.
+The format query parameter reads a Goodreads (format=goodreads) or StoryGraph (format=storygraph) export,
+putting books on the shelves they had there. Otherwise columns are matched to headers of the same name unless
+mapped with map.<column>=<header> query parameters.
+The books are imported for the owner query parameter, nobody by default. With dryRun=true nothing is written. With report=csv the response is a CSV of the rows that failed,
+each with its line number and error, instead of the JSON report.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func ImportBooksCSV(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection, shelfCollection initialisers.IShelfCollection) {
+	query := r.URL.Query()
+
+	dryRun, err := parseBoolParam(r, "dryRun")
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	mapping := make(map[string]string)
+	for name, values := range query {
+		if column, ok := strings.CutPrefix(name, mappingParam); ok && len(values) > 0 {
+			mapping[column] = values[0]
+		}
+	}
+
+	source, err := bookcsv.Open(r.Body, query.Get("format"), mapping)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	report, err := m.ImportBooks(bookCollection, shelfCollection, source, query.Get("owner"), dryRun)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	if query.Get("report") == "csv" {
+		writeImportErrors(w, report)
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"import": report})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+writeImportErrors downloads the rows of an import that failed, as read, after their line number and error.
+Cells are escaped like an export's, so the report is safe to open in a spreadsheet.
+*/
+func writeImportErrors(w http.ResponseWriter, report *model.ImportReport) {
+	setCSVHeaders(w, "import-errors.csv")
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(append([]string{"line", "error"}, report.Header...))
+
+	for _, row := range report.Failures() {
+		cells := append([]string{strconv.Itoa(row.Line), row.Reason}, row.Values...)
+		for i, cell := range cells {
+			cells[i] = bookcsv.EscapeFormula(cell)
+		}
+		writer.Write(cells)
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		log.Println(err)
+	}
+}
+
+func setCSVHeaders(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+}
+
+/*
+parseBoolParam parses a boolean query parameter, returning false when it is absent.
+*/
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &model.ValidationError{Field: name, Message: "must be true or false"}
+	}
+
+	return parsed, nil
+}
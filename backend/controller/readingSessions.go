@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+/*
+GetBookSessions lists the reading sessions of a book, most recent first, with the book's reading statistics.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetBookSessions(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, sessionCollection initialisers.IReadingSessionCollection, bookCollection initialisers.IBookCollection) {
+	sessions, stats, err := m.GetBookSessions(sessionCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"sessions": sessions, "stats": stats})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+LogSession records a reading session entered by hand from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func LogSession(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, sessionCollection initialisers.IReadingSessionCollection, bookCollection initialisers.IBookCollection) {
+	var input model.ReadingSessionInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	session, err := m.LogSession(sessionCollection, bookCollection, mux.Vars(r)["id"], input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"session": session})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, nil)
+}
+
+/*
+StartSession starts timing a reading session of a book.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func StartSession(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, sessionCollection initialisers.IReadingSessionCollection, bookCollection initialisers.IBookCollection) {
+	session, err := m.StartSession(sessionCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"session": session})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, nil)
+}
+
+/*
+StopSession stops the running reading session of a book. The optional JSON request body gives the page reached
+or the number of pages read.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func StopSession(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, sessionCollection initialisers.IReadingSessionCollection, bookCollection initialisers.IBookCollection) {
+	var input model.StopReadingSessionInput
+
+	if r.ContentLength != 0 {
+		err := v.ReadJSON(w, r, &input)
+
+		if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+			return
+		}
+	}
+
+	session, err := m.StopSession(sessionCollection, bookCollection, mux.Vars(r)["id"], input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"session": session})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetReadingStats computes reading statistics for the days between the from and to query parameters (2006-01-02,
+to exclusive), the last 30 days by default.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetReadingStats(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, sessionCollection initialisers.IReadingSessionCollection, bookCollection initialisers.IBookCollection) {
+	from, err := parseDateParam(r, "from")
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	to, err := parseDateParam(r, "to")
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	stats, err := m.ReadingStats(sessionCollection, bookCollection, from, to)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"stats": stats})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+parseDateParam parses a date query parameter, returning the zero time when it is absent.
+*/
+func parseDateParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, &model.ValidationError{Field: name, Message: "must be a date such as 2006-01-02"}
+	}
+
+	return date, nil
+}
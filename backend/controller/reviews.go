@@ -0,0 +1,295 @@
+package controller
+
+import (
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+
+	"github.com/gorilla/mux"
+)
+
+/*
+GetReviews lists the reviews of a book, most recent first.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetReviews(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, reviewCollection initialisers.IReviewCollection, bookCollection initialisers.IBookCollection) {
+	reviews, err := m.GetReviews(reviewCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"reviews": reviews})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+CreateReview adds a review to a book from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func CreateReview(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, reviewCollection initialisers.IReviewCollection, bookCollection initialisers.IBookCollection) {
+	var input model.ReviewInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	review, err := m.CreateReview(reviewCollection, bookCollection, mux.Vars(r)["id"], input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"review": review})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, nil)
+}
+
+/*
+UpdateReview applies the body and spoiler flag present in the JSON request body to a review.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func UpdateReview(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, reviewCollection initialisers.IReviewCollection) {
+	vars := mux.Vars(r)
+
+	review, err := m.GetReview(reviewCollection, vars["id"], vars["reviewId"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	var input struct {
+		Body    *string `json:"body"`
+		Spoiler *bool   `json:"spoiler"`
+	}
+
+	err = v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	if input.Body != nil {
+		review.Body = *input.Body
+	}
+
+	if input.Spoiler != nil {
+		review.Spoiler = *input.Spoiler
+	}
+
+	review.Version++
+
+	err = m.UpdateReview(reviewCollection, review)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"review": review})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+DeleteReview deletes a review of a book.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func DeleteReview(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, reviewCollection initialisers.IReviewCollection) {
+	vars := mux.Vars(r)
+
+	err := m.DeleteReview(reviewCollection, vars["id"], vars["reviewId"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "review successfully deleted"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetNotes lists the private notes on a book, most recent first.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetNotes(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, noteCollection initialisers.INoteCollection, bookCollection initialisers.IBookCollection) {
+	notes, err := m.GetNotes(noteCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"notes": notes})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+CreateNote adds a private note to a book from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func CreateNote(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, noteCollection initialisers.INoteCollection, bookCollection initialisers.IBookCollection) {
+	var input model.NoteInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	note, err := m.CreateNote(noteCollection, bookCollection, mux.Vars(r)["id"], input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"note": note})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, nil)
+}
+
+/*
+UpdateNote replaces the body of a note with the one in the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func UpdateNote(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, noteCollection initialisers.INoteCollection) {
+	vars := mux.Vars(r)
+
+	note, err := m.GetNote(noteCollection, vars["id"], vars["noteId"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	var input model.NoteInput
+
+	err = v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	note.Body = input.Body
+	note.Version++
+
+	err = m.UpdateNote(noteCollection, note)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"note": note})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+DeleteNote deletes a note on a book.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func DeleteNote(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, noteCollection initialisers.INoteCollection) {
+	vars := mux.Vars(r)
+
+	err := m.DeleteNote(noteCollection, vars["id"], vars["noteId"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "note successfully deleted"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+SearchNotes searches the text of every note for the q query parameter.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func SearchNotes(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, noteCollection initialisers.INoteCollection, bookCollection initialisers.IBookCollection) {
+	notes, err := m.SearchNotes(noteCollection, bookCollection, r.URL.Query().Get("q"))
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"notes": notes})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
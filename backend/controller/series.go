@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+
+	"github.com/gorilla/mux"
+)
+
+/*
+GetSeriesListHandler lists every series, ordered by name.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetSeriesListHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, seriesCollection initialisers.ISeriesCollection) {
+	series, err := m.GetAllSeries(seriesCollection)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"series": series})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+CreateSeriesHandler creates a series from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func CreateSeriesHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, seriesCollection initialisers.ISeriesCollection) {
+	var input model.SeriesInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	_, series, err := m.CreateSeries(seriesCollection, input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("v1/series/%s", series.ID))
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"series": series})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, headers)
+}
+
+/*
+GetSeries retrieves a series with its books in reading order.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetSeries(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, seriesCollection initialisers.ISeriesCollection, bookCollection initialisers.IBookCollection) {
+	series, err := m.GetSeries(seriesCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"series": series})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+UpdateSeries applies the fields present in the JSON request body to a series.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func UpdateSeries(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, seriesCollection initialisers.ISeriesCollection, bookCollection initialisers.IBookCollection) {
+	series, err := m.GetSeries(seriesCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	var input struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+	}
+
+	err = v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	if input.Name != nil {
+		series.Name = *input.Name
+	}
+
+	if input.Description != nil {
+		series.Description = *input.Description
+	}
+
+	series.Version++
+
+	err = m.UpdateSeries(seriesCollection, bookCollection, series)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	for _, book := range series.Entries {
+		book.Series = series.Name
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"series": series})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+DeleteSeries deletes a series. Its books keep the series name but lose the link and position.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func DeleteSeries(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, seriesCollection initialisers.ISeriesCollection, bookCollection initialisers.IBookCollection) {
+	err := m.DeleteSeries(seriesCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "series successfully deleted"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+SetSeriesEntry puts a book into a series at the position in the JSON request body ({"position": 2.5}),
+moving it if it is already part of a series.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func SetSeriesEntry(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, seriesCollection initialisers.ISeriesCollection, bookCollection initialisers.IBookCollection) {
+	var input struct {
+		Position float64 `json:"position"`
+	}
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	book, err := m.SetSeriesEntry(seriesCollection, bookCollection, vars["id"], vars["bookId"], input.Position)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"book": book})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+RemoveSeriesEntry takes a book out of a series.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func RemoveSeriesEntry(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+	vars := mux.Vars(r)
+
+	err := m.RemoveSeriesEntry(bookCollection, vars["id"], vars["bookId"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "book successfully removed from series"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetNextUnread returns the next book to read in a series, or null once the whole series has been read.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetNextUnread(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, seriesCollection initialisers.ISeriesCollection, bookCollection initialisers.IBookCollection) {
+	book, err := m.NextUnread(seriesCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"next": book})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
@@ -0,0 +1,343 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+
+	"github.com/gorilla/mux"
+)
+
+/*
+ShelfList displays the shelves page listing every shelf.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func ShelfList(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection) {
+	shelves, err := m.GetShelves(shelfCollection)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	err = v.ShelfList(w, r, shelves)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+}
+
+/*
+ShelfView displays a shelf's page with its books in shelf order.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func ShelfView(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection, bookCollection initialisers.IBookCollection) {
+	id := r.URL.Query().Get("id")
+
+	if len(id) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	shelf, err := m.GetShelf(shelfCollection, bookCollection, id)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	err = v.ShelfView(w, r, shelf)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+}
+
+/*
+GetShelvesHandler lists every shelf, ordered by name.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetShelvesHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection) {
+	shelves, err := m.GetShelves(shelfCollection)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"shelves": shelves})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+CreateShelfHandler creates an empty shelf from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func CreateShelfHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection) {
+	var input model.ShelfInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	_, shelf, err := m.CreateShelf(shelfCollection, input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("v1/shelves/%s", shelf.ID))
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"shelf": shelf})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, headers)
+}
+
+/*
+GetShelf retrieves a shelf with its books in shelf order.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetShelf(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection, bookCollection initialisers.IBookCollection) {
+	shelf, err := m.GetShelf(shelfCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"shelf": shelf})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+UpdateShelf applies the name and description present in the JSON request body to a shelf.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func UpdateShelf(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection, bookCollection initialisers.IBookCollection) {
+	shelf, err := m.GetShelf(shelfCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	var input struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+	}
+
+	err = v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	if input.Name != nil {
+		shelf.Name = *input.Name
+	}
+
+	if input.Description != nil {
+		shelf.Description = *input.Description
+	}
+
+	shelf.Version++
+
+	err = m.UpdateShelf(shelfCollection, shelf)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"shelf": shelf})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+DeleteShelf deletes a shelf. The books on it are not affected.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func DeleteShelf(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection) {
+	err := m.DeleteShelf(shelfCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "shelf successfully deleted"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+AddToShelf puts a book on a shelf, at the end or at the position in the optional JSON request body ({"position": 1}).
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func AddToShelf(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection, bookCollection initialisers.IBookCollection) {
+	var input struct {
+		Position int `json:"position"`
+	}
+
+	if r.ContentLength != 0 {
+		err := v.ReadJSON(w, r, &input)
+
+		if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+			return
+		}
+	}
+
+	vars := mux.Vars(r)
+
+	shelf, err := m.AddToShelf(shelfCollection, bookCollection, vars["id"], vars["bookId"], input.Position)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"shelf": shelf})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+RemoveFromShelf takes a book off a shelf.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func RemoveFromShelf(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection) {
+	vars := mux.Vars(r)
+
+	shelf, err := m.RemoveFromShelf(shelfCollection, vars["id"], vars["bookId"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"shelf": shelf})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+ReorderShelf puts the books on a shelf in the order given by the JSON request body ({"bookIds": [...]}).
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func ReorderShelf(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection) {
+	var input struct {
+		BookIDs []string `json:"bookIds"`
+	}
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	shelf, err := m.ReorderShelf(shelfCollection, mux.Vars(r)["id"], input.BookIDs)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"shelf": shelf})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetBookShelves lists the shelves a book is on.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetBookShelves(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, shelfCollection initialisers.IShelfCollection) {
+	shelves, err := m.GetBookShelves(shelfCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"shelves": shelves})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+)
+
+/*
+StatsView displays the statistics page summarising the library.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func StatsView(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+	stats, err := m.GetStats(bookCollection)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	err = v.StatsView(w, r, stats)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+}
+
+/*
+GetStatsHandler returns the library statistics: counts per genre, a rating histogram, books per publication
+decade, page totals, books added per month and the top-rated books.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetStatsHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, bookCollection initialisers.IBookCollection) {
+	stats, err := m.GetStats(bookCollection)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"stats": stats})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
@@ -0,0 +1,272 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/initialisers"
+	"readinglistapp/model"
+	"readinglistapp/view"
+
+	"github.com/gorilla/mux"
+)
+
+/*
+GetTagsHandler lists every tag with its usage count, or autocompletes the prefix query parameter (?prefix=sci).
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetTagsHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, tagCollection initialisers.ITagCollection, bookCollection initialisers.IBookCollection) {
+	var tags interface{}
+	var err error
+
+	if query := r.URL.Query(); query.Has("prefix") {
+		tags, err = m.SuggestTags(tagCollection, query.Get("prefix"))
+	} else {
+		tags, err = m.GetTags(tagCollection, bookCollection)
+	}
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"tags": tags})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+GetTagTree lists the tag taxonomy as a tree of top-level tags with their children nested inside.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func GetTagTree(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, tagCollection initialisers.ITagCollection, bookCollection initialisers.IBookCollection) {
+	tags, err := m.GetTagTree(tagCollection, bookCollection)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"tags": tags})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+CreateTagHandler adds a tag to the taxonomy from the JSON request body.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+*/
+func CreateTagHandler(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, tagCollection initialisers.ITagCollection) {
+	var input model.TagInput
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	_, tag, err := m.CreateTag(tagCollection, input)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("v1/tags/%s", tag.ID))
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"tag": tag})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, jsonResponse, headers)
+}
+
+/*
+GetTag retrieves a tag with its usage count.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func GetTag(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, tagCollection initialisers.ITagCollection, bookCollection initialisers.IBookCollection) {
+	tag, err := m.GetTag(tagCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"tag": tag})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+UpdateTag renames or moves a tag with the name and parentId present in the JSON request body.
+An empty parentId makes it a top-level tag. Renaming a tag rewrites the books that have it.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func UpdateTag(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, tagCollection initialisers.ITagCollection, bookCollection initialisers.IBookCollection, transactor initialisers.ITransactor) {
+	tag, err := m.GetTag(tagCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	var input struct {
+		Name     *string `json:"name"`
+		ParentID *string `json:"parentId"`
+	}
+
+	err = v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	if input.Name != nil {
+		tag.Name = *input.Name
+	}
+
+	if input.ParentID != nil {
+		tag.ParentID = *input.ParentID
+	}
+
+	tag.Version++
+
+	err = m.UpdateTag(tagCollection, bookCollection, transactor, tag)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"tag": tag})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+DeleteTag deletes a tag, removing it from every book and moving its children up to its parent.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func DeleteTag(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, tagCollection initialisers.ITagCollection, bookCollection initialisers.IBookCollection) {
+	err := m.DeleteTag(tagCollection, bookCollection, mux.Vars(r)["id"])
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"message": "tag successfully deleted"})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+MergeTags merges the tags listed in the request body ({"sourceIds": [...]}) into the tag in the URL,
+retagging their books and moving their children under it.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func MergeTags(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, tagCollection initialisers.ITagCollection, bookCollection initialisers.IBookCollection, transactor initialisers.ITransactor) {
+	var input struct {
+		SourceIDs []string `json:"sourceIds"`
+	}
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	tag, err := m.MergeTags(tagCollection, bookCollection, transactor, mux.Vars(r)["id"], input.SourceIDs)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"tag": tag})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
+
+/*
+SetBookTags replaces the tags of a book with those in the JSON request body ({"tags": [...]}).
+Every tag must already be in the taxonomy.
+
+Parameters:
+
+	param1: http.ResponseWriter
+	param2: *http.Request
+*/
+func SetBookTags(w http.ResponseWriter, r *http.Request, v view.IViewFuncs, m model.IModelFuncs, tagCollection initialisers.ITagCollection, bookCollection initialisers.IBookCollection) {
+	var input struct {
+		Tags []string `json:"tags"`
+	}
+
+	err := v.ReadJSON(w, r, &input)
+
+	if helper.IsHTTPStatusError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	book, err := m.SetBookTags(tagCollection, bookCollection, mux.Vars(r)["id"], input.Tags)
+
+	if isStorageError(w, err) {
+		return
+	}
+
+	jsonResponse, err := v.RenderJSON(view.Envelope{"book": book})
+
+	if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, jsonResponse, nil)
+}
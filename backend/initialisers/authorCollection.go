@@ -0,0 +1,137 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IAuthorCollection interface {
+	Create(author *data.Author) (interface{}, error)
+	Delete(id string) error
+	Get(id string) (*data.Author, error)
+	GetAll() ([]*data.Author, error)
+	Update(author *data.Author) error
+}
+
+/*
+AuthorCollection stores authors in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type AuthorCollection struct {
+	Store
+}
+
+/*
+NewAuthorCollection creates an AuthorCollection backed by the "authors" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer AuthorCollection
+*/
+func NewAuthorCollection(db *DB) *AuthorCollection {
+	return &AuthorCollection{Store: newStore(db, "authors")}
+}
+
+/*
+Create inserts a new author, setting CreatedAt when it is not set.
+
+Parameters:
+param1: pointer Author
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (ac *AuthorCollection) Create(author *data.Author) (interface{}, error) {
+	if author.CreatedAt.IsZero() {
+		author.CreatedAt = time.Now()
+	}
+
+	data := data.AuthorData{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: author.CreatedAt,
+		Name:      author.Name,
+		SortName:  author.SortName,
+		BirthYear: author.BirthYear,
+		DeathYear: author.DeathYear,
+		Bio:       author.Bio,
+		Aliases:   author.Aliases,
+		Version:   author.Version,
+	}
+
+	insertedID, err := ac.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	author.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+Get retrieves an author by ID. If there is no such author, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the author
+
+Returns:
+return1: pointer Author
+return2: error
+*/
+func (ac *AuthorCollection) Get(id string) (*data.Author, error) {
+	return findByID[data.Author](&ac.Store, id)
+}
+
+/*
+GetAll retrieves every author ordered by sort name.
+
+Returns:
+return1: []*Author
+return2: error
+*/
+func (ac *AuthorCollection) GetAll() ([]*data.Author, error) {
+	return findAll[data.Author](&ac.Store, bson.D{}, options.Find().SetSort(bson.D{{Key: "sortname", Value: 1}}))
+}
+
+/*
+Update replaces the fields of the author with the matching ID.
+If there is no such author, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Author
+
+Returns:
+return1: error
+*/
+func (ac *AuthorCollection) Update(author *data.Author) error {
+	return ac.updateByID(author.ID, bson.D{
+		{Key: "name", Value: author.Name},
+		{Key: "sortname", Value: author.SortName},
+		{Key: "birthyear", Value: author.BirthYear},
+		{Key: "deathyear", Value: author.DeathYear},
+		{Key: "bio", Value: author.Bio},
+		{Key: "aliases", Value: author.Aliases},
+		{Key: "version", Value: author.Version},
+	})
+}
+
+/*
+Delete removes the author with the given ID.
+
+Parameters:
+param1: string, ID of the author
+
+Returns:
+return1: error
+*/
+func (ac *AuthorCollection) Delete(id string) error {
+	return ac.deleteByID(id)
+}
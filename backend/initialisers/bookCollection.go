@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"readinglistapp/internal/data"
 	"time"
 
@@ -14,11 +13,29 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	ErrRecordNotFound  = errors.New("record not found")
+	ErrDuplicateRecord = errors.New("duplicate record")
+	ErrEditConflict    = errors.New("edit conflict")
+)
+
+// DefaultOperationTimeout bounds a single storage operation, including its retries, when no Timeout is configured.
+const DefaultOperationTimeout = 30 * time.Second
+
 type IBookCollection interface {
 	Create(book *data.Book) (interface{}, error)
 	Delete(id string) error
+	ForEach(fn func(book *data.Book) error) error
 	Get(id string) (*data.Book, error)
 	GetAll() ([]*data.Book, error)
+	GetByAuthor(authorID string) ([]*data.Book, error)
+	GetByIDs(ids []string) ([]*data.Book, error)
+	GetByISBN(owner, isbn13 string) (*data.Book, error)
+	GetBySeries(seriesID string) ([]*data.Book, error)
+	GetDeleted() ([]*data.Book, error)
+	GetFiltered(filter data.BookFilter) ([]*data.Book, error)
+	Purge(id string) error
+	Restore(id string) error
 	Update(book *data.Book) error
 }
 
@@ -28,10 +45,17 @@ type ICollection interface {
 	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (cur *mongo.Cursor, err error)
 	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
 	DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error)
 }
 
+/*
+BookCollection stores books in MongoDB.
+Every operation is bounded by Timeout.
+Reads (Get, GetAll, GetByAuthor, GetByISBN, GetBySeries, GetDeleted, GetFiltered, Stats) are idempotent and retried by the Store on transient errors.
+Deleted books go to the trash: they keep their document, with deletedat set, and every read but GetDeleted leaves them out.
+*/
 type BookCollection struct {
-	Collection ICollection
+	Store
 }
 
 func NewBookCollection(client *DB) *BookCollection {
@@ -41,7 +65,7 @@ func NewBookCollection(client *DB) *BookCollection {
 /*
 NewBookModel creates a new instance of BookCollection, which represents a collection of books in the MongoDB database.
 It takes a pointer to a DB struct as input, representing the database connection, and returns a pointer to a BookCollection.
-The BookCollection is initialized with the collection named "books" in the "readinglist" database.
+The BookCollection is initialized with the collection named "books" in the configured database ("readinglist" by default).
 
 Parameters:
 
@@ -52,13 +76,14 @@ Returns:
 return1: pointer BookCollection
 */
 func NewBookModel(db *DB) *BookCollection {
-	return &BookCollection{Collection: db.client.Database("readinglist").Collection("books")}
+	return &BookCollection{Store: newStore(db, "books")}
 }
 
 /*
 Insert inserts a new book into the BookCollection.
 It takes a pointer to a Book struct as input and returns the ID of the inserted document and an error.
 If the CreatedAt timestamp is not set in the input book, it sets the current time as the CreatedAt timestamp.
+A book that already has a valid ID, such as one restored from a backup, keeps it; otherwise a new one is set.
 
 Parameters:
 param1: pointer Book
@@ -68,38 +93,69 @@ return1: interface{}, ID of the inserted document
 return2: error
 */
 func (bc *BookCollection) Create(book *data.Book) (interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	// Set CreatedAt timestamp if not already set
 	if book.CreatedAt.IsZero() {
 		book.CreatedAt = time.Now()
 	}
 
-	data := data.BookData{
-		ID:        primitive.NewObjectID(),
-		CreatedAt: book.CreatedAt,
-		Title:     book.Title,
-		Published: book.Published,
-		Pages:     book.Pages,
-		Genres:    book.Genres,
-		Rating:    book.Rating,
-		Version:   book.Version,
+	id, err := primitive.ObjectIDFromHex(book.ID)
+	if err != nil {
+		id = primitive.NewObjectID()
 	}
 
-	result, err := bc.Collection.InsertOne(ctx, data)
+	data := newBookData(book, id)
 
+	insertedID, err := bc.insert(data)
 	if err != nil {
 		return nil, err
 	}
 
-	return result.InsertedID, nil
+	book.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+ForEach calls fn with every book, oldest first, decoding them from the cursor one at a time so the
+whole collection is never held in memory. It stops at the first error fn returns.
+Unlike the other reads it is not retried, as fn may already have run for some books.
+
+Parameters:
+param1: func(book *data.Book) error
+
+Returns:
+return1: error
+*/
+func (bc *BookCollection) ForEach(fn func(book *data.Book) error) error {
+	ctx, cancel := bc.context()
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := bc.Collection.Find(ctx, notDeleted(bson.D{}), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var book data.Book
+		if err := cursor.Decode(&book); err != nil {
+			return err
+		}
+
+		if err := fn(&book); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
 }
 
 /*
 Get retrieves a book from the BookCollection by its ID.
 It takes a string representing the ID of the book as input and returns a pointer to the retrieved Book struct and an error.
-If the document with the specified ID is not found, it returns a "record not found" error.
+If the document with the specified ID is not found, it returns ErrRecordNotFound.
 
 Parameters:
 param1: string, ID of the book
@@ -114,23 +170,24 @@ func (bc *BookCollection) Get(id string) (*data.Book, error) {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	filter := bson.D{{Key: "_id", Value: objID}}
-
-	var result data.Book
+	return findOne[data.Book](&bc.Store, notDeleted(bson.D{{Key: "_id", Value: objID}}))
+}
 
-	err = bc.Collection.FindOne(ctx, filter).Decode(&result)
+/*
+GetByISBN retrieves a book of an owner from the BookCollection by its normalised ISBN-13.
+ISBNs are unique per owner, so the same ISBN may belong to a book of each owner.
+If no book of the owner has that ISBN, it returns ErrRecordNotFound.
 
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("record not found")
-		}
-		return nil, err
-	}
+Parameters:
+param1: string, owner, empty for the books nobody owns
+param2: string, ISBN-13 without hyphens
 
-	return &result, nil
+Returns:
+return1: pointer Book
+return2: error
+*/
+func (bc *BookCollection) GetByISBN(owner, isbn13 string) (*data.Book, error) {
+	return findOne[data.Book](&bc.Store, notDeleted(bson.D{{Key: "owner", Value: owner}, {Key: "isbn13", Value: isbn13}}))
 }
 
 /*
@@ -142,52 +199,123 @@ return1: []*Book, slice of pointers to Book structs
 return2: error
 */
 func (bc *BookCollection) GetAll() ([]*data.Book, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	return bc.find(notDeleted(bson.D{}))
+}
 
-	cur, err := bc.Collection.Find(ctx, bson.D{})
+/*
+GetByAuthor retrieves the books linked to an author.
 
-	if err != nil {
-		log.Fatal(err)
-	}
+Parameters:
+param1: string, ID of the author
 
-	var results []*data.Book
+Returns:
+return1: []*Book, slice of pointers to Book structs
+return2: error
+*/
+func (bc *BookCollection) GetByAuthor(authorID string) ([]*data.Book, error) {
+	return bc.find(notDeleted(bson.D{{Key: "authorids", Value: authorID}}))
+}
 
-	for cur.Next(context.TODO()) {
-		var elem data.BookData
-		err := cur.Decode(&elem)
+/*
+GetByIDs retrieves the books with the given IDs, in no particular order. IDs without a book are skipped.
 
-		if err != nil {
-			log.Fatal(err)
-		}
+Parameters:
+param1: []string, IDs of the books
+
+Returns:
+return1: []*Book, slice of pointers to Book structs
+return2: error
+*/
+func (bc *BookCollection) GetByIDs(ids []string) ([]*data.Book, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
 
-		idStr := elem.ID.Hex()
-
-		book := data.Book{
-			ID:        idStr,
-			CreatedAt: elem.CreatedAt,
-			Title:     elem.Title,
-			Published: elem.Published,
-			Pages:     elem.Pages,
-			Genres:    elem.Genres,
-			Rating:    elem.Rating,
-			Version:   elem.Version,
+	for _, id := range ids {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
 		}
+	}
 
-		results = append(results, &book)
+	if len(objIDs) == 0 {
+		return nil, nil
 	}
 
-	if err := cur.Err(); err != nil {
-		log.Fatal(err)
-		return nil, err
+	return bc.find(notDeleted(bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: objIDs}}}}))
+}
+
+/*
+GetBySeries retrieves the books in a series.
+
+Parameters:
+param1: string, ID of the series
+
+Returns:
+return1: []*Book, slice of pointers to Book structs
+return2: error
+*/
+func (bc *BookCollection) GetBySeries(seriesID string) ([]*data.Book, error) {
+	return bc.find(notDeleted(bson.D{{Key: "seriesid", Value: seriesID}}))
+}
+
+/*
+GetFiltered retrieves the books matching every field set on the filter.
+
+Parameters:
+param1: data.BookFilter
+
+Returns:
+return1: []*Book, slice of pointers to Book structs
+return2: error
+*/
+func (bc *BookCollection) GetFiltered(filter data.BookFilter) ([]*data.Book, error) {
+	query := bson.D{}
+
+	if len(filter.Owners) > 0 {
+		query = append(query, bson.E{Key: "owner", Value: bson.D{{Key: "$in", Value: filter.Owners}}})
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = append(query, bson.E{Key: "status", Value: bson.D{{Key: "$in", Value: filter.Statuses}}})
+	}
+
+	if len(filter.Tags) > 0 {
+		query = append(query, bson.E{Key: "tags", Value: bson.D{{Key: "$in", Value: filter.Tags}}})
 	}
 
-	return results, nil
+	if len(filter.AuthorIDs) > 0 {
+		query = append(query, bson.E{Key: "authorids", Value: bson.D{{Key: "$in", Value: filter.AuthorIDs}}})
+	}
+
+	if filter.IncludeDeleted {
+		return bc.find(query)
+	}
+
+	return bc.find(notDeleted(query))
+}
+
+/*
+GetDeleted retrieves the books in the trash, the most recently deleted first.
+
+Returns:
+return1: []*Book, slice of pointers to Book structs
+return2: error
+*/
+func (bc *BookCollection) GetDeleted() ([]*data.Book, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "deletedat", Value: -1}})
+
+	return bc.find(inTrash(bson.D{}), opts)
+}
+
+/*
+find retrieves the books matching filter.
+*/
+func (bc *BookCollection) find(filter interface{}, opts ...*options.FindOptions) ([]*data.Book, error) {
+	return findAll[data.Book](&bc.Store, filter, opts...)
 }
 
 /*
 Update updates a book in the BookCollection.
 It takes a pointer to a Book struct as input and updates the document with the corresponding ID in the collection.
+Books in the trash are updated too, so renames and merges reach them, and stay in the trash.
 It returns an error.
 
 Parameters:
@@ -202,28 +330,23 @@ func (bc *BookCollection) Update(book *data.Book) error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := bc.context()
 	defer cancel()
 
 	// Create a filter to find the document by its ID
 	filter := bson.D{{Key: "_id", Value: objID}}
 
 	// Create an update with the changes to apply
-	update := bson.D{
-		{Key: "$set", Value: bson.D{
-			{Key: "title", Value: book.Title},
-			{Key: "published", Value: book.Published},
-			{Key: "pages", Value: book.Pages},
-			{Key: "genres", Value: book.Genres},
-			{Key: "rating", Value: book.Rating},
-			{Key: "version", Value: book.Version},
-		}},
+	fields, err := bookFields(newBookData(book, objID))
+	if err != nil {
+		return err
 	}
+	update := bson.D{{Key: "$set", Value: fields}}
 
 	// Perform the update operation
 	result, err := bc.Collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return err
+		return translateWriteError(err)
 	}
 
 	fmt.Printf("\nThe document has been updated. ModifiedCount: %v, UpdatedCount: %v, UpdatedID: %v", result.ModifiedCount, result.UpsertedCount, result.UpsertedID)
@@ -232,8 +355,8 @@ func (bc *BookCollection) Update(book *data.Book) error {
 }
 
 /*
-Delete removes a book from the BookCollection by its ID.
-It takes a string representing the ID of the book as input and returns an error.
+Delete moves a book to the trash by setting its deletedat time. It can be restored until it is purged.
+Deleting a book that is already in the trash, or doesn't exist, does nothing.
 
 Parameters:
 param1: string, ID of the book
@@ -247,24 +370,163 @@ func (bc *BookCollection) Delete(id string) error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := bc.context()
 	defer cancel()
 
-	// Create a filter to find the document by its ID
-	filter := bson.D{{Key: "_id", Value: objID}}
+	filter := notDeleted(bson.D{{Key: "_id", Value: objID}})
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "deletedat", Value: time.Now()}}}}
+
+	_, err = bc.Collection.UpdateOne(ctx, filter, update)
 
-	_, err = bc.Collection.DeleteMany(ctx, filter)
+	return err
+}
+
+/*
+Restore takes a book out of the trash.
+If the book is not in the trash, it returns ErrRecordNotFound; if another book has its ISBN, ErrDuplicateRecord.
+
+Parameters:
+param1: string, ID of the book
 
+Returns:
+return1: error
+*/
+func (bc *BookCollection) Restore(id string) error {
+	objID, err := parseToObjectID(id)
 	if err != nil {
 		return err
 	}
 
+	filter := inTrash(bson.D{{Key: "_id", Value: objID}})
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "deletedat", Value: ""}}}}
+
+	return bc.updateOne(filter, update)
+}
+
+/*
+Purge permanently removes a book from the trash.
+If the book is not in the trash, it returns ErrRecordNotFound: books must be deleted before they are purged.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: error
+*/
+func (bc *BookCollection) Purge(id string) error {
+	objID, err := parseToObjectID(id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := bc.deleteMany(inTrash(bson.D{{Key: "_id", Value: objID}}))
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrRecordNotFound
+	}
+
 	return nil
 }
 
+/*
+newBookData converts a book to the document stored for it under id.
+*/
+func newBookData(book *data.Book, id primitive.ObjectID) data.BookData {
+	return data.BookData{
+		ID:             id,
+		CreatedAt:      book.CreatedAt,
+		Owner:          book.Owner,
+		Title:          book.Title,
+		Authors:        book.Authors,
+		AuthorIDs:      book.AuthorIDs,
+		ISBN10:         book.ISBN10,
+		ISBN13:         book.ISBN13,
+		Publisher:      book.Publisher,
+		Language:       book.Language,
+		Description:    book.Description,
+		Edition:        book.Edition,
+		Series:         book.Series,
+		SeriesID:       book.SeriesID,
+		SeriesPosition: book.SeriesPosition,
+		Status:         book.Status,
+		CurrentPage:    book.CurrentPage,
+		StartedAt:      book.StartedAt,
+		FinishedAt:     book.FinishedAt,
+		AbandonedAt:    book.AbandonedAt,
+		Published:      book.Published,
+		Pages:          book.Pages,
+		Genres:         book.Genres,
+		Tags:           book.Tags,
+		Rating:         book.Rating,
+		Version:        book.Version,
+		DeletedAt:      book.DeletedAt,
+	}
+}
+
+/*
+bookFields returns the fields of a stored book an update may set, in document order. The ID, creation time and
+trash time are left out: they are only changed by Create, Delete and Restore.
+*/
+func bookFields(book data.BookData) (bson.D, error) {
+	raw, err := bson.Marshal(book)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	fields := doc[:0]
+	for _, field := range doc {
+		switch field.Key {
+		case "_id", "createdat", "deletedat":
+		default:
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
+}
+
+/*
+notDeleted narrows a filter to the books that aren't in the trash. A missing deletedat matches too.
+*/
+func notDeleted(filter bson.D) bson.D {
+	return append(filter, bson.E{Key: "deletedat", Value: nil})
+}
+
+/*
+inTrash narrows a filter to the books in the trash.
+*/
+func inTrash(filter bson.D) bson.D {
+	return append(filter, bson.E{Key: "deletedat", Value: bson.D{{Key: "$ne", Value: nil}}})
+}
+
+/*
+WithContext returns a copy of the collection whose operations derive their context from ctx.
+Passing a mongo.SessionContext makes every operation part of that session's transaction.
+
+Parameters:
+param1: context.Context
+
+Returns:
+return1: pointer BookCollection
+*/
+func (bc *BookCollection) WithContext(ctx context.Context) *BookCollection {
+	copied := *bc
+	copied.parent = ctx
+	return &copied
+}
+
 /*
 parseToObjectID converts a string representation of ObjectID to a primitive.ObjectID object.
 It takes a string representing the ObjectID as input and returns the corresponding primitive.ObjectID object and an error.
+An empty or malformed ID can never match a document, so it is reported as ErrRecordNotFound.
 
 Parameters:
 param1: string, representation of ObjectID
@@ -275,12 +537,12 @@ return2: error
 */
 func parseToObjectID(id string) (primitive.ObjectID, error) {
 	if len(id) <= 0 {
-		return primitive.NilObjectID, errors.New("record could not be found")
+		return primitive.NilObjectID, ErrRecordNotFound
 	}
 	// Parse the string representation of ObjectID into a primitive.ObjectID object
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return primitive.NilObjectID, err
+		return primitive.NilObjectID, fmt.Errorf("%w: %v", ErrRecordNotFound, err)
 	}
 	return objID, nil
 }
@@ -0,0 +1,366 @@
+package initialisers
+
+import (
+	"errors"
+	"fmt"
+	"readinglistapp/internal/data"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("storage circuit breaker is open")
+
+/*
+CircuitOpenError is returned instead of calling the storage backend while the breaker is open.
+RetryAfter tells the client when the backend will be probed again.
+*/
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", ErrCircuitOpen, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+/*
+BreakerOptions configures when a CircuitBreaker trips and how it recovers.
+
+The breaker opens after FailureThreshold consecutive failures, or when at least MinRequests calls
+were made in the current Window and the share of failures reaches ErrorRate.
+After OpenTimeout it lets HalfOpenProbes calls through: a success closes it again, a failure re-opens it.
+*/
+type BreakerOptions struct {
+	FailureThreshold int
+	ErrorRate        float64
+	MinRequests      int
+	Window           time.Duration
+	OpenTimeout      time.Duration
+	HalfOpenProbes   int
+}
+
+/*
+BreakerStatus is a snapshot of the breaker, reported by the readiness endpoint.
+*/
+type BreakerStatus struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	WindowRequests      int          `json:"windowRequests"`
+	WindowFailures      int          `json:"windowFailures"`
+	RetryAfter          string       `json:"retryAfter,omitempty"`
+}
+
+/*
+CircuitBreaker decorates an IBookCollection so that, once the backend is degraded,
+requests fail fast with a *CircuitOpenError instead of all waiting for the operation timeout.
+*/
+type CircuitBreaker struct {
+	next IBookCollection
+	opts BreakerOptions
+	now  func() time.Time
+
+	mu                  sync.Mutex
+	state               BreakerState
+	consecutiveFailures int
+	requests            int
+	failures            int
+	windowStart         time.Time
+	openedAt            time.Time
+	probes              int
+}
+
+/*
+NewCircuitBreaker wraps the collection with a circuit breaker.
+
+Parameters:
+
+	param1: IBookCollection, the storage backend to protect
+	param2: BreakerOptions
+
+Returns:
+
+	return1: pointer CircuitBreaker
+*/
+func NewCircuitBreaker(next IBookCollection, opts BreakerOptions) *CircuitBreaker {
+	if opts.HalfOpenProbes < 1 {
+		opts.HalfOpenProbes = 1
+	}
+
+	return &CircuitBreaker{next: next, opts: opts, now: time.Now, state: BreakerClosed}
+}
+
+func (cb *CircuitBreaker) Create(book *data.Book) (interface{}, error) {
+	return call(cb, func() (interface{}, error) { return cb.next.Create(book) })
+}
+
+func (cb *CircuitBreaker) Delete(id string) error {
+	return cb.call(func() error { return cb.next.Delete(id) })
+}
+
+/*
+ForEach counts as one call. An error returned by fn, such as a client that went away during an export,
+doesn't count as a failure of the backend.
+*/
+func (cb *CircuitBreaker) ForEach(fn func(book *data.Book) error) error {
+	var fnErr, err error
+	cbErr := cb.call(func() error {
+		err = cb.next.ForEach(func(book *data.Book) error {
+			fnErr = fn(book)
+			return fnErr
+		})
+
+		if fnErr != nil {
+			return nil
+		}
+		return err
+	})
+
+	if cbErr != nil {
+		return cbErr
+	}
+	return err
+}
+
+func (cb *CircuitBreaker) Get(id string) (*data.Book, error) {
+	return call(cb, func() (*data.Book, error) { return cb.next.Get(id) })
+}
+
+func (cb *CircuitBreaker) GetAll() ([]*data.Book, error) {
+	return call(cb, func() ([]*data.Book, error) { return cb.next.GetAll() })
+}
+
+func (cb *CircuitBreaker) GetByAuthor(authorID string) ([]*data.Book, error) {
+	return call(cb, func() ([]*data.Book, error) { return cb.next.GetByAuthor(authorID) })
+}
+
+func (cb *CircuitBreaker) GetByIDs(ids []string) ([]*data.Book, error) {
+	return call(cb, func() ([]*data.Book, error) { return cb.next.GetByIDs(ids) })
+}
+
+func (cb *CircuitBreaker) GetByISBN(owner, isbn13 string) (*data.Book, error) {
+	return call(cb, func() (*data.Book, error) { return cb.next.GetByISBN(owner, isbn13) })
+}
+
+func (cb *CircuitBreaker) GetBySeries(seriesID string) ([]*data.Book, error) {
+	return call(cb, func() ([]*data.Book, error) { return cb.next.GetBySeries(seriesID) })
+}
+
+func (cb *CircuitBreaker) GetDeleted() ([]*data.Book, error) {
+	return call(cb, func() ([]*data.Book, error) { return cb.next.GetDeleted() })
+}
+
+func (cb *CircuitBreaker) GetFiltered(filter data.BookFilter) ([]*data.Book, error) {
+	return call(cb, func() ([]*data.Book, error) { return cb.next.GetFiltered(filter) })
+}
+
+func (cb *CircuitBreaker) Purge(id string) error {
+	return cb.call(func() error { return cb.next.Purge(id) })
+}
+
+func (cb *CircuitBreaker) Restore(id string) error {
+	return cb.call(func() error { return cb.next.Restore(id) })
+}
+
+/*
+Stats computes the library statistics through the wrapped collection when it implements IBookStats,
+and from every book otherwise, so wrapping a collection doesn't change how its statistics are computed.
+*/
+func (cb *CircuitBreaker) Stats() (*data.LibraryStats, error) {
+	return call(cb, func() (*data.LibraryStats, error) {
+		if stats, ok := cb.next.(IBookStats); ok {
+			return stats.Stats()
+		}
+
+		books, err := cb.next.GetAll()
+		if err != nil {
+			return nil, err
+		}
+
+		return ComputeLibraryStats(books), nil
+	})
+}
+
+func (cb *CircuitBreaker) Update(book *data.Book) error {
+	return cb.call(func() error { return cb.next.Update(book) })
+}
+
+/*
+Status returns a snapshot of the breaker's state and counters.
+
+Returns:
+
+	return1: BreakerStatus
+*/
+func (cb *CircuitBreaker) Status() BreakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := BreakerStatus{
+		State:               cb.state,
+		ConsecutiveFailures: cb.consecutiveFailures,
+		WindowRequests:      cb.requests,
+		WindowFailures:      cb.failures,
+	}
+
+	if cb.state == BreakerOpen {
+		status.RetryAfter = cb.retryAfter(cb.now()).String()
+	}
+
+	return status
+}
+
+/*
+call runs fn through the breaker, rejecting it while open and recording its outcome.
+*/
+func (cb *CircuitBreaker) call(fn func() error) error {
+	if err := cb.allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+
+	cb.record(isBackendFailure(err))
+
+	return err
+}
+
+/*
+call runs a storage operation that returns a value through the breaker, like CircuitBreaker.call.
+Every guarded collection method is a one-line call to it or to CircuitBreaker.call.
+
+Parameters:
+
+	param1: pointer CircuitBreaker
+	param2: fn func() (T, error), the operation
+
+Returns:
+
+	return1: T, the zero value when the breaker rejected the call
+	return2: error
+*/
+func call[T any](cb *CircuitBreaker, fn func() (T, error)) (T, error) {
+	var result T
+	err := cb.call(func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
+
+/*
+allow decides whether a call may reach the backend, moving from open to half-open once OpenTimeout has passed.
+*/
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+
+	if cb.state == BreakerOpen {
+		if now.Sub(cb.openedAt) < cb.opts.OpenTimeout {
+			return &CircuitOpenError{RetryAfter: cb.retryAfter(now)}
+		}
+
+		cb.state = BreakerHalfOpen
+		cb.probes = 0
+	}
+
+	if cb.state == BreakerHalfOpen {
+		if cb.probes >= cb.opts.HalfOpenProbes {
+			return &CircuitOpenError{RetryAfter: time.Second}
+		}
+
+		cb.probes++
+	}
+
+	return nil
+}
+
+/*
+record updates the counters with the outcome of a call and trips or resets the breaker.
+*/
+func (cb *CircuitBreaker) record(failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+
+	if cb.state == BreakerHalfOpen {
+		if failed {
+			cb.trip(now)
+		} else {
+			cb.reset(now)
+		}
+		return
+	}
+
+	if cb.opts.Window > 0 && now.Sub(cb.windowStart) >= cb.opts.Window {
+		cb.windowStart = now
+		cb.requests = 0
+		cb.failures = 0
+	}
+
+	cb.requests++
+
+	if !failed {
+		cb.consecutiveFailures = 0
+		return
+	}
+
+	cb.failures++
+	cb.consecutiveFailures++
+
+	tooManyConsecutive := cb.opts.FailureThreshold > 0 && cb.consecutiveFailures >= cb.opts.FailureThreshold
+	errorRateExceeded := cb.opts.ErrorRate > 0 && cb.requests >= cb.opts.MinRequests &&
+		float64(cb.failures)/float64(cb.requests) >= cb.opts.ErrorRate
+
+	if tooManyConsecutive || errorRateExceeded {
+		cb.trip(now)
+	}
+}
+
+func (cb *CircuitBreaker) trip(now time.Time) {
+	cb.state = BreakerOpen
+	cb.openedAt = now
+}
+
+func (cb *CircuitBreaker) reset(now time.Time) {
+	cb.state = BreakerClosed
+	cb.consecutiveFailures = 0
+	cb.requests = 0
+	cb.failures = 0
+	cb.windowStart = now
+}
+
+func (cb *CircuitBreaker) retryAfter(now time.Time) time.Duration {
+	if remaining := cb.opts.OpenTimeout - now.Sub(cb.openedAt); remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
+/*
+isBackendFailure reports whether an error means the backend is unhealthy.
+Client errors such as unknown IDs, duplicate keys or edit conflicts don't count, nor does a deployment without transactions.
+*/
+func isBackendFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return !errors.Is(err, ErrRecordNotFound) && !errors.Is(err, ErrDuplicateRecord) &&
+		!errors.Is(err, ErrEditConflict) && !errors.Is(err, ErrTransactionsUnsupported)
+}
@@ -0,0 +1,198 @@
+package initialisers
+
+import (
+	"errors"
+	"readinglistapp/internal/data"
+	"testing"
+	"time"
+)
+
+type stubBookCollection struct {
+	err   error
+	calls int
+}
+
+func (s *stubBookCollection) Create(book *data.Book) (interface{}, error) {
+	s.calls++
+	return nil, s.err
+}
+func (s *stubBookCollection) Delete(id string) error { s.calls++; return s.err }
+func (s *stubBookCollection) ForEach(fn func(book *data.Book) error) error {
+	s.calls++
+	if s.err != nil {
+		return s.err
+	}
+	return fn(&data.Book{})
+}
+func (s *stubBookCollection) Get(id string) (*data.Book, error) {
+	s.calls++
+	return &data.Book{}, s.err
+}
+func (s *stubBookCollection) GetAll() ([]*data.Book, error) { s.calls++; return nil, s.err }
+func (s *stubBookCollection) GetByAuthor(authorID string) ([]*data.Book, error) {
+	s.calls++
+	return nil, s.err
+}
+func (s *stubBookCollection) GetByIDs(ids []string) ([]*data.Book, error) {
+	s.calls++
+	return nil, s.err
+}
+func (s *stubBookCollection) GetByISBN(owner, isbn13 string) (*data.Book, error) {
+	s.calls++
+	return &data.Book{}, s.err
+}
+func (s *stubBookCollection) GetBySeries(seriesID string) ([]*data.Book, error) {
+	s.calls++
+	return nil, s.err
+}
+func (s *stubBookCollection) GetDeleted() ([]*data.Book, error) { s.calls++; return nil, s.err }
+func (s *stubBookCollection) GetFiltered(filter data.BookFilter) ([]*data.Book, error) {
+	s.calls++
+	return nil, s.err
+}
+func (s *stubBookCollection) Purge(id string) error        { s.calls++; return s.err }
+func (s *stubBookCollection) Restore(id string) error      { s.calls++; return s.err }
+func (s *stubBookCollection) Update(book *data.Book) error { s.calls++; return s.err }
+
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+	stub := &stubBookCollection{err: errors.New("server selection timeout")}
+	now := time.Now()
+
+	cb := NewCircuitBreaker(stub, BreakerOptions{FailureThreshold: 3, ErrorRate: 1, MinRequests: 100, Window: time.Minute, OpenTimeout: 10 * time.Second})
+	cb.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		cb.GetAll()
+	}
+
+	if state := cb.Status().State; state != BreakerOpen {
+		t.Fatalf("Expected breaker to be open but got %s", state)
+	}
+
+	_, err := cb.GetAll()
+
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected a CircuitOpenError but got %v", err)
+	}
+
+	if openErr.RetryAfter != 10*time.Second || stub.calls != 3 {
+		t.Errorf("Expected a fast failure with RetryAfter 10s, got %s after %d calls", openErr.RetryAfter, stub.calls)
+	}
+
+	// After the open timeout a single probe is let through, a success closes the breaker.
+	now = now.Add(11 * time.Second)
+	stub.err = nil
+
+	if _, err := cb.GetAll(); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if state := cb.Status().State; state != BreakerClosed {
+		t.Errorf("Expected breaker to be closed but got %s", state)
+	}
+}
+
+func TestCircuitBreakerReopensOnFailedProbe(t *testing.T) {
+	stub := &stubBookCollection{err: errors.New("connection refused")}
+	now := time.Now()
+
+	cb := NewCircuitBreaker(stub, BreakerOptions{FailureThreshold: 1, ErrorRate: 1, MinRequests: 100, Window: time.Minute, OpenTimeout: time.Second})
+	cb.now = func() time.Time { return now }
+
+	cb.Delete("507f1f77bcf86cd799439011")
+
+	now = now.Add(2 * time.Second)
+	cb.Delete("507f1f77bcf86cd799439011")
+
+	if state := cb.Status().State; state != BreakerOpen {
+		t.Errorf("Expected breaker to re-open but got %s", state)
+	}
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	stub := &stubBookCollection{}
+
+	cb := NewCircuitBreaker(stub, BreakerOptions{FailureThreshold: 100, ErrorRate: 0.5, MinRequests: 4, Window: time.Minute, OpenTimeout: time.Second})
+
+	for i, fail := range []bool{false, true, false, true} {
+		stub.err = nil
+		if fail {
+			stub.err = errors.New("write concern timeout")
+		}
+		cb.Update(&data.Book{})
+
+		if i < 3 && cb.Status().State != BreakerClosed {
+			t.Fatalf("Breaker opened before MinRequests was reached")
+		}
+	}
+
+	if state := cb.Status().State; state != BreakerOpen {
+		t.Errorf("Expected breaker to be open at a 50%% error rate but got %s", state)
+	}
+}
+
+func TestCircuitBreakerIgnoresNotFound(t *testing.T) {
+	stub := &stubBookCollection{err: ErrRecordNotFound}
+
+	cb := NewCircuitBreaker(stub, BreakerOptions{FailureThreshold: 1, ErrorRate: 1, MinRequests: 1, Window: time.Minute, OpenTimeout: time.Second})
+
+	cb.Get("507f1f77bcf86cd799439011")
+
+	if state := cb.Status().State; state != BreakerClosed {
+		t.Errorf("Expected not found errors to keep the breaker closed but got %s", state)
+	}
+}
+
+// stubTransactor calls fn with its books and fails with err when fn succeeds.
+type stubTransactor struct {
+	books IBookCollection
+	err   error
+}
+
+func (s *stubTransactor) BookTransaction(fn func(books IBookCollection) error) error {
+	if err := fn(s.books); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func TestCircuitBreakerGuardsTransactions(t *testing.T) {
+	stub := &stubTransactor{books: &stubBookCollection{}}
+
+	cb := NewCircuitBreaker(&stubBookCollection{}, BreakerOptions{FailureThreshold: 1, ErrorRate: 1, MinRequests: 1, Window: time.Minute, OpenTimeout: time.Second})
+	transactor := cb.GuardTransactions(stub)
+
+	invalid := errors.New("invalid operation")
+	if err := transactor.BookTransaction(func(IBookCollection) error { return invalid }); err != invalid {
+		t.Fatalf("Expected the error of fn but got %v", err)
+	}
+
+	if state := cb.Status().State; state != BreakerClosed {
+		t.Errorf("Expected a transaction aborted by fn to keep the breaker closed but got %s", state)
+	}
+
+	stub.err = errors.New("transaction numbers are only allowed on a replica set member")
+	transactor.BookTransaction(func(IBookCollection) error { return nil })
+
+	if state := cb.Status().State; state != BreakerOpen {
+		t.Errorf("Expected a failed commit to open the breaker but got %s", state)
+	}
+
+	if err := transactor.BookTransaction(func(IBookCollection) error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen but got %v", err)
+	}
+}
+
+func TestCircuitBreakerForEachIgnoresCallbackErrors(t *testing.T) {
+	cb := NewCircuitBreaker(&stubBookCollection{}, BreakerOptions{FailureThreshold: 1, ErrorRate: 1, MinRequests: 1, Window: time.Minute, OpenTimeout: time.Second})
+
+	gone := errors.New("broken pipe")
+	if err := cb.ForEach(func(*data.Book) error { return gone }); err != gone {
+		t.Fatalf("Expected the error of fn but got %v", err)
+	}
+
+	if state := cb.Status().State; state != BreakerClosed {
+		t.Errorf("Expected an error from fn to keep the breaker closed but got %s", state)
+	}
+}
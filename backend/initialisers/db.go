@@ -3,12 +3,16 @@ package initialisers
 import (
 	"context"
 	"fmt"
-	"os"
+	"log"
+	"readinglistapp/settings"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 type IDB interface {
@@ -16,11 +20,15 @@ type IDB interface {
 }
 
 type DB struct {
-	client *mongo.Client
+	client           *mongo.Client
+	name             string
+	operationTimeout time.Duration
+	readRetries      int
+	backoff          Backoff
 }
 
-func NewDB() (*DB, error) {
-	dbClient, err := connectToDatabase()
+func NewDB(cfg settings.DBConfig) (*DB, error) {
+	dbClient, err := connectToDatabase(cfg)
 
 	if err != nil {
 		return nil, err
@@ -30,38 +38,126 @@ func NewDB() (*DB, error) {
 }
 
 /*
-ConnectToDatabase establishes a connection to the MongoDB database using the provided environment
-variable DB_URL.
-It creates a new MongoDB client and attempts to ping the server to confirm the
-connection.
+ConnectToDatabase establishes a connection to the MongoDB database using the configured DB URL,
+pool size, timeouts, read preference and write concern.
+It creates a new MongoDB client and pings the server to confirm the connection, retrying the ping
+with exponential backoff and jitter up to cfg.ConnectAttempts times so the app survives Mongo starting slowly.
 If successful, it returns a pointer to the DB struct containing the client.
-If there is an error during connection or ping, it returns nil and the error.
+If there is an error during connection or every ping fails, it returns nil and the error.
+
+Parameters:
+
+	param1: settings.DBConfig
 
 Returns:
 
 	return1: pointer DB
 	return2: error
 */
-func connectToDatabase() (*DB, error) {
-	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
-	opts := options.Client().ApplyURI(os.Getenv("DB_URL")).SetServerAPIOptions(serverAPI)
+func connectToDatabase(cfg settings.DBConfig) (*DB, error) {
+	opts, err := clientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create a new client and connect to the server
+	// Create a new client, the driver connects lazily in the background
 	client, err := mongo.Connect(context.TODO(), opts)
 	if err != nil {
 		return nil, err
 	}
 
+	backoff := Backoff{Initial: cfg.RetryBackoff, Max: cfg.RetryMaxBackoff}
+	attempt := 0
+
 	// Send a ping to confirm a successful connection
-	if err := client.Database("admin").RunCommand(context.TODO(), bson.D{{"ping", 1}}).Err(); err != nil {
+	err = retry(context.Background(), cfg.ConnectAttempts, backoff, func(error) bool { return true }, func() error {
+		attempt++
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout+cfg.ServerSelectionTimeout)
+		defer cancel()
+
+		err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).Err()
+		if err != nil {
+			log.Printf("MongoDB ping attempt %d/%d failed: %v", attempt, cfg.ConnectAttempts, err)
+		}
+
+		return err
+	})
+
+	if err != nil {
+		client.Disconnect(context.TODO())
 		return nil, err
 	}
+
 	fmt.Println("Pinged your deployment. You successfully connected to MongoDB!")
 
-	_, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	return &DB{
+		client:           client,
+		name:             cfg.Name,
+		operationTimeout: cfg.OperationTimeout,
+		readRetries:      cfg.ReadRetries,
+		backoff:          backoff,
+	}, nil
+}
+
+/*
+clientOptions builds the MongoDB client options from the configuration.
+*/
+func clientOptions(cfg settings.DBConfig) (*options.ClientOptions, error) {
+	mode, err := readpref.ModeFromString(cfg.ReadPreference)
+	if err != nil {
+		return nil, err
+	}
 
-	return &DB{client: client}, nil
+	readPreference, err := readpref.New(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	writeConcern := writeconcern.Majority()
+	if cfg.WriteConcern != "majority" {
+		w, err := strconv.Atoi(cfg.WriteConcern)
+		if err != nil {
+			return nil, err
+		}
+		writeConcern = &writeconcern.WriteConcern{W: w}
+	}
+
+	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
+
+	opts := options.Client().
+		ApplyURI(cfg.URL).
+		SetServerAPIOptions(serverAPI).
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMinPoolSize(cfg.MinPoolSize).
+		SetConnectTimeout(cfg.ConnectTimeout).
+		SetServerSelectionTimeout(cfg.ServerSelectionTimeout).
+		SetReadPreference(readPreference).
+		SetWriteConcern(writeConcern)
+
+	return opts, nil
+}
+
+/*
+Database returns the configured MongoDB database, used by the migrations.
+*/
+func (db *DB) Database() *mongo.Database {
+	return db.client.Database(db.name)
+}
+
+/*
+Ping checks the database is reachable.
+
+Parameters:
+
+	param1: context.Context
+
+Returns:
+
+	return1: error
+*/
+func (db *DB) Ping(ctx context.Context) error {
+	return db.client.Ping(ctx, nil)
 }
 
 /*
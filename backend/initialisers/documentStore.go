@@ -0,0 +1,268 @@
+package initialisers
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+IDocumentStore reads and writes whole collections as the documents they store, without decoding them,
+so that backups keep every field of every collection. A backend that stores the related collections implements it
+to have them restored; without one a restore only brings the books back.
+*/
+type IDocumentStore interface {
+	ForEachDocument(collection string, fn func(doc bson.Raw) error) error
+	RestoreDocuments(collection string, docs []bson.Raw, replace bool) (created, deleted, skipped int, err error)
+}
+
+/*
+DocumentStore reads and writes the collections of the configured database. Each call is bounded by Timeout.
+*/
+type DocumentStore struct {
+	Database *mongo.Database
+	Timeout  time.Duration
+}
+
+/*
+NewDocumentStore creates a DocumentStore for the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer DocumentStore
+*/
+func NewDocumentStore(db *DB) *DocumentStore {
+	return &DocumentStore{
+		Database: db.Database(),
+		Timeout:  db.operationTimeout,
+	}
+}
+
+/*
+ForEachDocument calls fn with every document of a collection in _id order, reading them from a cursor one at a time.
+It stops at the first error fn returns and, like BookCollection.ForEach, is not retried.
+
+Parameters:
+param1: string, name of the collection
+param2: func(doc bson.Raw) error
+
+Returns:
+return1: error
+*/
+func (ds *DocumentStore) ForEachDocument(collection string, fn func(doc bson.Raw) error) error {
+	ctx, cancel := operationContext(ds.Timeout)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := ds.Database.Collection(collection).Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		if err := fn(cursor.Current); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+/*
+RestoreDocuments writes documents back into a collection. Without replace the documents are merged: those whose _id,
+or another unique key, is already taken are skipped and left as they are.
+With replace the collection becomes the documents, all at once: they replace the stored ones in one transaction,
+or, on a standalone server, are written to a staging collection with the same indexes that is then renamed over it.
+A replace that fails, on a duplicate key or a timeout, leaves the collection as it was.
+
+Parameters:
+param1: string, name of the collection
+param2: []bson.Raw, documents with their _id
+param3: bool, replace the collection instead of merging into it
+
+Returns:
+return1: int, documents inserted
+return2: int, documents deleted by replace
+return3: int, documents skipped
+return4: error
+*/
+func (ds *DocumentStore) RestoreDocuments(collection string, docs []bson.Raw, replace bool) (int, int, int, error) {
+	if replace {
+		return ds.replaceDocuments(collection, docs)
+	}
+
+	ctx, cancel := operationContext(ds.Timeout)
+	defer cancel()
+
+	coll := ds.Database.Collection(collection)
+
+	created, skipped := 0, 0
+
+	for _, doc := range docs {
+		_, err := coll.InsertOne(ctx, doc)
+
+		switch {
+		case err == nil:
+			created++
+		case mongo.IsDuplicateKeyError(err):
+			skipped++
+		default:
+			return created, 0, skipped, translateWriteError(err)
+		}
+	}
+
+	return created, 0, skipped, nil
+}
+
+/*
+replaceDocuments swaps the documents of a collection for docs in one transaction, falling back to
+stageDocuments when the server can't run transactions.
+*/
+func (ds *DocumentStore) replaceDocuments(collection string, docs []bson.Raw) (int, int, int, error) {
+	ctx, cancel := operationContext(ds.Timeout)
+	defer cancel()
+
+	session, err := ds.Database.Client().StartSession()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer session.EndSession(context.Background())
+
+	deleted := 0
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		coll := ds.Database.Collection(collection)
+
+		result, err := coll.DeleteMany(sc, bson.D{})
+		if err != nil {
+			return nil, err
+		}
+		deleted = int(result.DeletedCount)
+
+		if len(docs) == 0 {
+			return nil, nil
+		}
+
+		_, err = coll.InsertMany(sc, documents(docs))
+		return nil, err
+	})
+
+	if isTransactionsUnsupported(err) {
+		return ds.stageDocuments(ctx, collection, docs)
+	}
+	if err != nil {
+		return 0, 0, 0, translateWriteError(err)
+	}
+
+	return len(docs), deleted, 0, nil
+}
+
+/*
+stageDocuments replaces a collection without a transaction: docs go to a staging collection given the indexes of
+the original, so they are checked against the same unique keys, and renameCollection then swaps it for the
+original in one step. Until the rename the original is untouched, and a failed staging collection is dropped.
+*/
+func (ds *DocumentStore) stageDocuments(ctx context.Context, collection string, docs []bson.Raw) (int, int, int, error) {
+	coll := ds.Database.Collection(collection)
+	staging := ds.Database.Collection(collection + "_restore")
+
+	// A staging collection left by an interrupted restore is stale
+	if err := staging.Drop(ctx); err != nil {
+		return 0, 0, 0, err
+	}
+
+	deleted, err := coll.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	err = ds.stage(ctx, coll, staging, docs)
+	if err == nil {
+		err = renameCollection(ctx, staging, coll)
+	}
+	if err != nil {
+		staging.Drop(context.Background())
+		return 0, 0, 0, translateWriteError(err)
+	}
+
+	return len(docs), int(deleted), 0, nil
+}
+
+/*
+stage creates the staging collection with the indexes of the original, then inserts docs into it.
+*/
+func (ds *DocumentStore) stage(ctx context.Context, coll, staging *mongo.Collection, docs []bson.Raw) error {
+	if err := ds.Database.CreateCollection(ctx, staging.Name()); err != nil {
+		return err
+	}
+
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var specs []bson.M
+	if err := cursor.All(ctx, &specs); err != nil {
+		return err
+	}
+
+	indexes := bson.A{}
+	for _, spec := range specs {
+		if spec["name"] == "_id_" {
+			continue
+		}
+		// Older servers list the namespace of the index, which would name the original collection
+		delete(spec, "ns")
+		indexes = append(indexes, spec)
+	}
+
+	if len(indexes) > 0 {
+		command := bson.D{{Key: "createIndexes", Value: staging.Name()}, {Key: "indexes", Value: indexes}}
+		if err := ds.Database.RunCommand(ctx, command).Err(); err != nil {
+			return err
+		}
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	_, err = staging.InsertMany(ctx, documents(docs))
+	return err
+}
+
+/*
+renameCollection renames from over to, dropping to, as one operation of the server.
+*/
+func renameCollection(ctx context.Context, from, to *mongo.Collection) error {
+	namespace := func(coll *mongo.Collection) string {
+		return coll.Database().Name() + "." + coll.Name()
+	}
+
+	command := bson.D{
+		{Key: "renameCollection", Value: namespace(from)},
+		{Key: "to", Value: namespace(to)},
+		{Key: "dropTarget", Value: true},
+	}
+
+	return from.Database().Client().Database("admin").RunCommand(ctx, command).Err()
+}
+
+// documents turns raw documents into the slice InsertMany takes.
+func documents(docs []bson.Raw) []interface{} {
+	values := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		values[i] = doc
+	}
+
+	return values
+}
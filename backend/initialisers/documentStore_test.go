@@ -0,0 +1,119 @@
+package initialisers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testDatabase connects to the server of TEST_DB_URL and returns a fresh database, dropped after the test.
+func testDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(url))
+	if err != nil {
+		t.Fatalf("connecting to %s: %v", url, err)
+	}
+
+	db := client.Database(fmt.Sprintf("readinglist_test_%d", time.Now().UnixNano()))
+
+	t.Cleanup(func() {
+		_ = db.Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+
+	return db
+}
+
+func rawDocuments(t *testing.T, names ...string) []bson.Raw {
+	t.Helper()
+
+	docs := make([]bson.Raw, len(names))
+	for i, name := range names {
+		doc, err := bson.Marshal(bson.D{{Key: "_id", Value: fmt.Sprintf("s%d", i+1)}, {Key: "name", Value: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs[i] = doc
+	}
+
+	return docs
+}
+
+func TestRestoreDocumentsReplace(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+	ds := &DocumentStore{Database: db, Timeout: 10 * time.Second}
+
+	shelves := db.Collection("shelves")
+
+	_, err := shelves.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetName("shelves_name").SetUnique(true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := shelves.InsertMany(ctx, documents(rawDocuments(t, "Favourites", "To read"))); err != nil {
+		t.Fatal(err)
+	}
+
+	replace := map[string]func(docs []bson.Raw) (int, int, int, error){
+		"transaction or staging": func(docs []bson.Raw) (int, int, int, error) {
+			return ds.RestoreDocuments("shelves", docs, true)
+		},
+		"staging": func(docs []bson.Raw) (int, int, int, error) {
+			return ds.stageDocuments(ctx, "shelves", docs)
+		},
+	}
+
+	for name, replace := range replace {
+		t.Run(name, func(t *testing.T) {
+			_, _, _, err := replace(rawDocuments(t, "Classics", "Classics"))
+			if !errors.Is(err, ErrDuplicateRecord) {
+				t.Fatalf("got error %v, expected a duplicate shelf name", err)
+			}
+
+			if count, _ := shelves.CountDocuments(ctx, bson.D{}); count != 2 {
+				t.Fatalf("got %d shelves, expected the collection left as it was", count)
+			}
+
+			created, deleted, _, err := replace(rawDocuments(t, "Classics", "Poetry"))
+			if err != nil || created != 2 || deleted != 2 {
+				t.Fatalf("got %d created, %d deleted and error %v, expected the 2 shelves replaced", created, deleted, err)
+			}
+
+			// The unique index is kept, whichever way the collection was replaced
+			if _, err := shelves.InsertOne(ctx, bson.D{{Key: "name", Value: "Poetry"}}); !mongo.IsDuplicateKeyError(err) {
+				t.Errorf("got error %v, expected the unique index on name kept", err)
+			}
+
+			if names, _ := db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: "shelves_restore"}}); len(names) != 0 {
+				t.Errorf("got %v, expected no staging collection left", names)
+			}
+
+			if _, err := shelves.DeleteMany(ctx, bson.D{}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := shelves.InsertMany(ctx, documents(rawDocuments(t, "Favourites", "To read"))); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
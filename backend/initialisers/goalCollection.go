@@ -0,0 +1,140 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IGoalCollection interface {
+	Create(goal *data.Goal) (interface{}, error)
+	Delete(id string) error
+	Get(id string) (*data.Goal, error)
+	GetByOwner(owner string) ([]*data.Goal, error)
+	Update(goal *data.Goal) error
+}
+
+/*
+GoalCollection stores reading goals in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type GoalCollection struct {
+	Store
+}
+
+/*
+NewGoalCollection creates a GoalCollection backed by the "goals" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer GoalCollection
+*/
+func NewGoalCollection(db *DB) *GoalCollection {
+	return &GoalCollection{Store: newStore(db, "goals")}
+}
+
+/*
+Create inserts a new goal, setting CreatedAt when it is not set.
+A second goal for the same owner, metric and period returns ErrDuplicateRecord.
+
+Parameters:
+param1: pointer Goal
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (gc *GoalCollection) Create(goal *data.Goal) (interface{}, error) {
+	if goal.CreatedAt.IsZero() {
+		goal.CreatedAt = time.Now()
+	}
+
+	data := data.GoalData{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: goal.CreatedAt,
+		Owner:     goal.Owner,
+		Metric:    goal.Metric,
+		Year:      goal.Year,
+		Month:     goal.Month,
+		Target:    goal.Target,
+		Version:   goal.Version,
+	}
+
+	insertedID, err := gc.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	goal.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+Get retrieves a goal by ID. If there is no such goal, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the goal
+
+Returns:
+return1: pointer Goal
+return2: error
+*/
+func (gc *GoalCollection) Get(id string) (*data.Goal, error) {
+	return findByID[data.Goal](&gc.Store, id)
+}
+
+/*
+GetByOwner retrieves the goals of an owner, the latest year first and yearly goals before monthly ones.
+
+Parameters:
+param1: string, owner
+
+Returns:
+return1: []*Goal
+return2: error
+*/
+func (gc *GoalCollection) GetByOwner(owner string) ([]*data.Goal, error) {
+	sort := bson.D{{Key: "year", Value: -1}, {Key: "month", Value: 1}, {Key: "metric", Value: 1}}
+
+	return findAll[data.Goal](&gc.Store, bson.D{{Key: "owner", Value: owner}}, options.Find().SetSort(sort))
+}
+
+/*
+Update replaces the fields of the goal with the matching ID.
+If there is no such goal, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Goal
+
+Returns:
+return1: error
+*/
+func (gc *GoalCollection) Update(goal *data.Goal) error {
+	return gc.updateByID(goal.ID, bson.D{
+		{Key: "metric", Value: goal.Metric},
+		{Key: "year", Value: goal.Year},
+		{Key: "month", Value: goal.Month},
+		{Key: "target", Value: goal.Target},
+		{Key: "version", Value: goal.Version},
+	})
+}
+
+/*
+Delete removes the goal with the given ID.
+
+Parameters:
+param1: string, ID of the goal
+
+Returns:
+return1: error
+*/
+func (gc *GoalCollection) Delete(id string) error {
+	return gc.deleteByID(id)
+}
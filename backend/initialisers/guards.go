@@ -0,0 +1,473 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+)
+
+// The guards below decorate the other collections with a CircuitBreaker, so that every collection stored in the
+// database shares one breaker state. Each method is a one-line call through the breaker.
+
+/*
+GuardAuthors decorates an author collection with this breaker. Authors are stored in the same database
+as books, so failures of either count towards one breaker state.
+
+Parameters:
+
+	param1: IAuthorCollection
+
+Returns:
+
+	return1: IAuthorCollection
+*/
+func (cb *CircuitBreaker) GuardAuthors(next IAuthorCollection) IAuthorCollection {
+	return &authorBreaker{cb: cb, next: next}
+}
+
+type authorBreaker struct {
+	cb   *CircuitBreaker
+	next IAuthorCollection
+}
+
+func (ab *authorBreaker) Create(author *data.Author) (interface{}, error) {
+	return call(ab.cb, func() (interface{}, error) { return ab.next.Create(author) })
+}
+
+func (ab *authorBreaker) Delete(id string) error {
+	return ab.cb.call(func() error { return ab.next.Delete(id) })
+}
+
+func (ab *authorBreaker) Get(id string) (*data.Author, error) {
+	return call(ab.cb, func() (*data.Author, error) { return ab.next.Get(id) })
+}
+
+func (ab *authorBreaker) GetAll() ([]*data.Author, error) {
+	return call(ab.cb, func() ([]*data.Author, error) { return ab.next.GetAll() })
+}
+
+func (ab *authorBreaker) Update(author *data.Author) error {
+	return ab.cb.call(func() error { return ab.next.Update(author) })
+}
+
+/*
+GuardSeries decorates a series collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: ISeriesCollection
+
+Returns:
+
+	return1: ISeriesCollection
+*/
+func (cb *CircuitBreaker) GuardSeries(next ISeriesCollection) ISeriesCollection {
+	return &seriesBreaker{cb: cb, next: next}
+}
+
+type seriesBreaker struct {
+	cb   *CircuitBreaker
+	next ISeriesCollection
+}
+
+func (sb *seriesBreaker) Create(series *data.Series) (interface{}, error) {
+	return call(sb.cb, func() (interface{}, error) { return sb.next.Create(series) })
+}
+
+func (sb *seriesBreaker) Delete(id string) error {
+	return sb.cb.call(func() error { return sb.next.Delete(id) })
+}
+
+func (sb *seriesBreaker) Get(id string) (*data.Series, error) {
+	return call(sb.cb, func() (*data.Series, error) { return sb.next.Get(id) })
+}
+
+func (sb *seriesBreaker) GetAll() ([]*data.Series, error) {
+	return call(sb.cb, func() ([]*data.Series, error) { return sb.next.GetAll() })
+}
+
+func (sb *seriesBreaker) Update(series *data.Series) error {
+	return sb.cb.call(func() error { return sb.next.Update(series) })
+}
+
+/*
+GuardReadingSessions decorates a reading session collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: IReadingSessionCollection
+
+Returns:
+
+	return1: IReadingSessionCollection
+*/
+func (cb *CircuitBreaker) GuardReadingSessions(next IReadingSessionCollection) IReadingSessionCollection {
+	return &readingSessionBreaker{cb: cb, next: next}
+}
+
+type readingSessionBreaker struct {
+	cb   *CircuitBreaker
+	next IReadingSessionCollection
+}
+
+func (rb *readingSessionBreaker) Create(session *data.ReadingSession) (interface{}, error) {
+	return call(rb.cb, func() (interface{}, error) { return rb.next.Create(session) })
+}
+
+func (rb *readingSessionBreaker) DeleteByBook(bookID string) error {
+	return rb.cb.call(func() error { return rb.next.DeleteByBook(bookID) })
+}
+
+func (rb *readingSessionBreaker) GetByBook(bookID string) ([]*data.ReadingSession, error) {
+	return call(rb.cb, func() ([]*data.ReadingSession, error) { return rb.next.GetByBook(bookID) })
+}
+
+func (rb *readingSessionBreaker) GetBetween(from, to time.Time) ([]*data.ReadingSession, error) {
+	return call(rb.cb, func() ([]*data.ReadingSession, error) { return rb.next.GetBetween(from, to) })
+}
+
+func (rb *readingSessionBreaker) GetOpen(bookID string) (*data.ReadingSession, error) {
+	return call(rb.cb, func() (*data.ReadingSession, error) { return rb.next.GetOpen(bookID) })
+}
+
+func (rb *readingSessionBreaker) Update(session *data.ReadingSession) error {
+	return rb.cb.call(func() error { return rb.next.Update(session) })
+}
+
+/*
+GuardGoals decorates a goal collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: IGoalCollection
+
+Returns:
+
+	return1: IGoalCollection
+*/
+func (cb *CircuitBreaker) GuardGoals(next IGoalCollection) IGoalCollection {
+	return &goalBreaker{cb: cb, next: next}
+}
+
+type goalBreaker struct {
+	cb   *CircuitBreaker
+	next IGoalCollection
+}
+
+func (gb *goalBreaker) Create(goal *data.Goal) (interface{}, error) {
+	return call(gb.cb, func() (interface{}, error) { return gb.next.Create(goal) })
+}
+
+func (gb *goalBreaker) Delete(id string) error {
+	return gb.cb.call(func() error { return gb.next.Delete(id) })
+}
+
+func (gb *goalBreaker) Get(id string) (*data.Goal, error) {
+	return call(gb.cb, func() (*data.Goal, error) { return gb.next.Get(id) })
+}
+
+func (gb *goalBreaker) GetByOwner(owner string) ([]*data.Goal, error) {
+	return call(gb.cb, func() ([]*data.Goal, error) { return gb.next.GetByOwner(owner) })
+}
+
+func (gb *goalBreaker) Update(goal *data.Goal) error {
+	return gb.cb.call(func() error { return gb.next.Update(goal) })
+}
+
+/*
+GuardShelves decorates a shelf collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: IShelfCollection
+
+Returns:
+
+	return1: IShelfCollection
+*/
+func (cb *CircuitBreaker) GuardShelves(next IShelfCollection) IShelfCollection {
+	return &shelfBreaker{cb: cb, next: next}
+}
+
+type shelfBreaker struct {
+	cb   *CircuitBreaker
+	next IShelfCollection
+}
+
+func (sh *shelfBreaker) Create(shelf *data.Shelf) (interface{}, error) {
+	return call(sh.cb, func() (interface{}, error) { return sh.next.Create(shelf) })
+}
+
+func (sh *shelfBreaker) Delete(id string) error {
+	return sh.cb.call(func() error { return sh.next.Delete(id) })
+}
+
+func (sh *shelfBreaker) Get(id string) (*data.Shelf, error) {
+	return call(sh.cb, func() (*data.Shelf, error) { return sh.next.Get(id) })
+}
+
+func (sh *shelfBreaker) GetAll() ([]*data.Shelf, error) {
+	return call(sh.cb, func() ([]*data.Shelf, error) { return sh.next.GetAll() })
+}
+
+func (sh *shelfBreaker) GetByBook(bookID string) ([]*data.Shelf, error) {
+	return call(sh.cb, func() ([]*data.Shelf, error) { return sh.next.GetByBook(bookID) })
+}
+
+func (sh *shelfBreaker) Update(shelf *data.Shelf) error {
+	return sh.cb.call(func() error { return sh.next.Update(shelf) })
+}
+
+/*
+GuardReviews decorates a review collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: IReviewCollection
+
+Returns:
+
+	return1: IReviewCollection
+*/
+func (cb *CircuitBreaker) GuardReviews(next IReviewCollection) IReviewCollection {
+	return &reviewBreaker{cb: cb, next: next}
+}
+
+type reviewBreaker struct {
+	cb   *CircuitBreaker
+	next IReviewCollection
+}
+
+func (rb *reviewBreaker) Create(review *data.Review) (interface{}, error) {
+	return call(rb.cb, func() (interface{}, error) { return rb.next.Create(review) })
+}
+
+func (rb *reviewBreaker) Delete(id string) error {
+	return rb.cb.call(func() error { return rb.next.Delete(id) })
+}
+
+func (rb *reviewBreaker) Get(id string) (*data.Review, error) {
+	return call(rb.cb, func() (*data.Review, error) { return rb.next.Get(id) })
+}
+
+func (rb *reviewBreaker) DeleteByBook(bookID string) error {
+	return rb.cb.call(func() error { return rb.next.DeleteByBook(bookID) })
+}
+
+func (rb *reviewBreaker) GetByBook(bookID string) ([]*data.Review, error) {
+	return call(rb.cb, func() ([]*data.Review, error) { return rb.next.GetByBook(bookID) })
+}
+
+func (rb *reviewBreaker) Update(review *data.Review) error {
+	return rb.cb.call(func() error { return rb.next.Update(review) })
+}
+
+/*
+GuardNotes decorates a note collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: INoteCollection
+
+Returns:
+
+	return1: INoteCollection
+*/
+func (cb *CircuitBreaker) GuardNotes(next INoteCollection) INoteCollection {
+	return &noteBreaker{cb: cb, next: next}
+}
+
+type noteBreaker struct {
+	cb   *CircuitBreaker
+	next INoteCollection
+}
+
+func (nb *noteBreaker) Create(note *data.Note) (interface{}, error) {
+	return call(nb.cb, func() (interface{}, error) { return nb.next.Create(note) })
+}
+
+func (nb *noteBreaker) Delete(id string) error {
+	return nb.cb.call(func() error { return nb.next.Delete(id) })
+}
+
+func (nb *noteBreaker) Get(id string) (*data.Note, error) {
+	return call(nb.cb, func() (*data.Note, error) { return nb.next.Get(id) })
+}
+
+func (nb *noteBreaker) DeleteByBook(bookID string) error {
+	return nb.cb.call(func() error { return nb.next.DeleteByBook(bookID) })
+}
+
+func (nb *noteBreaker) GetByBook(bookID string) ([]*data.Note, error) {
+	return call(nb.cb, func() ([]*data.Note, error) { return nb.next.GetByBook(bookID) })
+}
+
+func (nb *noteBreaker) Search(text string) ([]*data.Note, error) {
+	return call(nb.cb, func() ([]*data.Note, error) { return nb.next.Search(text) })
+}
+
+func (nb *noteBreaker) Update(note *data.Note) error {
+	return nb.cb.call(func() error { return nb.next.Update(note) })
+}
+
+/*
+GuardHighlights decorates a highlight collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: IHighlightCollection
+
+Returns:
+
+	return1: IHighlightCollection
+*/
+func (cb *CircuitBreaker) GuardHighlights(next IHighlightCollection) IHighlightCollection {
+	return &highlightBreaker{cb: cb, next: next}
+}
+
+type highlightBreaker struct {
+	cb   *CircuitBreaker
+	next IHighlightCollection
+}
+
+func (hb *highlightBreaker) Create(highlight *data.Highlight) (interface{}, error) {
+	return call(hb.cb, func() (interface{}, error) { return hb.next.Create(highlight) })
+}
+
+func (hb *highlightBreaker) Delete(id string) error {
+	return hb.cb.call(func() error { return hb.next.Delete(id) })
+}
+
+func (hb *highlightBreaker) Get(id string) (*data.Highlight, error) {
+	return call(hb.cb, func() (*data.Highlight, error) { return hb.next.Get(id) })
+}
+
+func (hb *highlightBreaker) GetAll(tag string) ([]*data.Highlight, error) {
+	return call(hb.cb, func() ([]*data.Highlight, error) { return hb.next.GetAll(tag) })
+}
+
+func (hb *highlightBreaker) DeleteByBook(bookID string) error {
+	return hb.cb.call(func() error { return hb.next.DeleteByBook(bookID) })
+}
+
+func (hb *highlightBreaker) GetByBook(bookID string) ([]*data.Highlight, error) {
+	return call(hb.cb, func() ([]*data.Highlight, error) { return hb.next.GetByBook(bookID) })
+}
+
+func (hb *highlightBreaker) Update(highlight *data.Highlight) error {
+	return hb.cb.call(func() error { return hb.next.Update(highlight) })
+}
+
+/*
+GuardTags decorates a tag collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: ITagCollection
+
+Returns:
+
+	return1: ITagCollection
+*/
+func (cb *CircuitBreaker) GuardTags(next ITagCollection) ITagCollection {
+	return &tagBreaker{cb: cb, next: next}
+}
+
+type tagBreaker struct {
+	cb   *CircuitBreaker
+	next ITagCollection
+}
+
+func (tb *tagBreaker) Create(tag *data.Tag) (interface{}, error) {
+	return call(tb.cb, func() (interface{}, error) { return tb.next.Create(tag) })
+}
+
+func (tb *tagBreaker) Delete(id string) error {
+	return tb.cb.call(func() error { return tb.next.Delete(id) })
+}
+
+func (tb *tagBreaker) Get(id string) (*data.Tag, error) {
+	return call(tb.cb, func() (*data.Tag, error) { return tb.next.Get(id) })
+}
+
+func (tb *tagBreaker) GetAll() ([]*data.Tag, error) {
+	return call(tb.cb, func() ([]*data.Tag, error) { return tb.next.GetAll() })
+}
+
+func (tb *tagBreaker) GetByPrefix(prefix string, limit int64) ([]*data.Tag, error) {
+	return call(tb.cb, func() ([]*data.Tag, error) { return tb.next.GetByPrefix(prefix, limit) })
+}
+
+func (tb *tagBreaker) Update(tag *data.Tag) error {
+	return tb.cb.call(func() error { return tb.next.Update(tag) })
+}
+
+/*
+GuardUsers decorates a user collection with this breaker, sharing its state like GuardAuthors.
+
+Parameters:
+
+	param1: IUserCollection
+
+Returns:
+
+	return1: IUserCollection
+*/
+func (cb *CircuitBreaker) GuardUsers(next IUserCollection) IUserCollection {
+	return &userBreaker{cb: cb, next: next}
+}
+
+type userBreaker struct {
+	cb   *CircuitBreaker
+	next IUserCollection
+}
+
+func (ub *userBreaker) Create(user *data.User) (interface{}, error) {
+	return call(ub.cb, func() (interface{}, error) { return ub.next.Create(user) })
+}
+
+func (ub *userBreaker) GetAll() ([]*data.User, error) {
+	return call(ub.cb, func() ([]*data.User, error) { return ub.next.GetAll() })
+}
+
+/*
+GuardTransactions decorates a transactor with this breaker, sharing its state like GuardAuthors.
+A transaction counts as one call. When fn itself fails, the transaction is aborted because of the
+request rather than the backend, so it doesn't count as a failure.
+
+Parameters:
+
+	param1: ITransactor
+
+Returns:
+
+	return1: ITransactor
+*/
+func (cb *CircuitBreaker) GuardTransactions(next ITransactor) ITransactor {
+	return &transactionBreaker{cb: cb, next: next}
+}
+
+type transactionBreaker struct {
+	cb   *CircuitBreaker
+	next ITransactor
+}
+
+func (tb *transactionBreaker) BookTransaction(fn func(books IBookCollection) error) error {
+	var txErr error
+	err := tb.cb.call(func() error {
+		var aborted error
+		txErr = tb.next.BookTransaction(func(books IBookCollection) error {
+			aborted = fn(books)
+			return aborted
+		})
+
+		if aborted != nil {
+			return nil
+		}
+		return txErr
+	})
+
+	if err != nil {
+		return err
+	}
+	return txErr
+}
@@ -0,0 +1,182 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IHighlightCollection interface {
+	Create(highlight *data.Highlight) (interface{}, error)
+	Delete(id string) error
+	DeleteByBook(bookID string) error
+	Get(id string) (*data.Highlight, error)
+	GetAll(tag string) ([]*data.Highlight, error)
+	GetByBook(bookID string) ([]*data.Highlight, error)
+	Update(highlight *data.Highlight) error
+}
+
+/*
+HighlightCollection stores highlights in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type HighlightCollection struct {
+	Store
+}
+
+/*
+NewHighlightCollection creates a HighlightCollection backed by the "highlights" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer HighlightCollection
+*/
+func NewHighlightCollection(db *DB) *HighlightCollection {
+	return &HighlightCollection{Store: newStore(db, "highlights")}
+}
+
+/*
+Create inserts a new highlight, setting CreatedAt when it is not set.
+
+Parameters:
+param1: pointer Highlight
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (hc *HighlightCollection) Create(highlight *data.Highlight) (interface{}, error) {
+	if highlight.CreatedAt.IsZero() {
+		highlight.CreatedAt = time.Now()
+	}
+
+	data := data.HighlightData{
+		ID:        primitive.NewObjectID(),
+		BookID:    highlight.BookID,
+		Quote:     highlight.Quote,
+		Page:      highlight.Page,
+		Location:  highlight.Location,
+		Comment:   highlight.Comment,
+		Tags:      highlight.Tags,
+		CreatedAt: highlight.CreatedAt,
+		Version:   highlight.Version,
+	}
+
+	insertedID, err := hc.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	highlight.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+Get retrieves a highlight by ID. If there is no such highlight, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the highlight
+
+Returns:
+return1: pointer Highlight
+return2: error
+*/
+func (hc *HighlightCollection) Get(id string) (*data.Highlight, error) {
+	return findByID[data.Highlight](&hc.Store, id)
+}
+
+/*
+GetAll retrieves the highlights of every book, most recent first, optionally only those with a tag.
+
+Parameters:
+param1: string, tag, or empty for every highlight
+
+Returns:
+return1: []*Highlight
+return2: error
+*/
+func (hc *HighlightCollection) GetAll(tag string) ([]*data.Highlight, error) {
+	filter := bson.D{}
+	if tag != "" {
+		filter = bson.D{{Key: "tags", Value: tag}}
+	}
+
+	return hc.find(filter, bson.D{{Key: "createdat", Value: -1}})
+}
+
+/*
+GetByBook retrieves the highlights of a book in reading order: by page, then by when they were made.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: []*Highlight
+return2: error
+*/
+func (hc *HighlightCollection) GetByBook(bookID string) ([]*data.Highlight, error) {
+	return hc.find(bson.D{{Key: "bookid", Value: bookID}}, bson.D{{Key: "page", Value: 1}, {Key: "createdat", Value: 1}})
+}
+
+/*
+Update replaces the fields of the highlight with the matching ID.
+If there is no such highlight, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Highlight
+
+Returns:
+return1: error
+*/
+func (hc *HighlightCollection) Update(highlight *data.Highlight) error {
+	return hc.updateByID(highlight.ID, bson.D{
+		{Key: "quote", Value: highlight.Quote},
+		{Key: "page", Value: highlight.Page},
+		{Key: "location", Value: highlight.Location},
+		{Key: "comment", Value: highlight.Comment},
+		{Key: "tags", Value: highlight.Tags},
+		{Key: "version", Value: highlight.Version},
+	})
+}
+
+/*
+Delete removes the highlight with the given ID.
+
+Parameters:
+param1: string, ID of the highlight
+
+Returns:
+return1: error
+*/
+func (hc *HighlightCollection) Delete(id string) error {
+	return hc.deleteByID(id)
+}
+
+/*
+DeleteByBook removes the highlights of a book, as when it is purged.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: error
+*/
+func (hc *HighlightCollection) DeleteByBook(bookID string) error {
+	_, err := hc.deleteMany(bson.D{{Key: "bookid", Value: bookID}})
+
+	return err
+}
+
+/*
+find retrieves the highlights matching filter in the given order.
+*/
+func (hc *HighlightCollection) find(filter interface{}, sort bson.D) ([]*data.Highlight, error) {
+	return findAll[data.Highlight](&hc.Store, filter, options.Find().SetSort(sort))
+}
@@ -0,0 +1,175 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type INoteCollection interface {
+	Create(note *data.Note) (interface{}, error)
+	Delete(id string) error
+	DeleteByBook(bookID string) error
+	Get(id string) (*data.Note, error)
+	GetByBook(bookID string) ([]*data.Note, error)
+	Search(text string) ([]*data.Note, error)
+	Update(note *data.Note) error
+}
+
+/*
+NoteCollection stores private book notes in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type NoteCollection struct {
+	Store
+}
+
+/*
+NewNoteCollection creates a NoteCollection backed by the "notes" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer NoteCollection
+*/
+func NewNoteCollection(db *DB) *NoteCollection {
+	return &NoteCollection{Store: newStore(db, "notes")}
+}
+
+/*
+Create inserts a new note, setting CreatedAt when it is not set.
+
+Parameters:
+param1: pointer Note
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (nc *NoteCollection) Create(note *data.Note) (interface{}, error) {
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = time.Now()
+	}
+
+	data := data.NoteData{
+		ID:        primitive.NewObjectID(),
+		BookID:    note.BookID,
+		Body:      note.Body,
+		CreatedAt: note.CreatedAt,
+		EditedAt:  note.EditedAt,
+		Version:   note.Version,
+	}
+
+	insertedID, err := nc.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	note.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+Get retrieves a note by ID. If there is no such note, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the note
+
+Returns:
+return1: pointer Note
+return2: error
+*/
+func (nc *NoteCollection) Get(id string) (*data.Note, error) {
+	return findByID[data.Note](&nc.Store, id)
+}
+
+/*
+GetByBook retrieves the notes of a book, most recent first.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: []*Note
+return2: error
+*/
+func (nc *NoteCollection) GetByBook(bookID string) ([]*data.Note, error) {
+	return nc.find(bson.D{{Key: "bookid", Value: bookID}}, options.Find().SetSort(bson.D{{Key: "createdat", Value: -1}}))
+}
+
+/*
+Search retrieves the notes whose text matches the words or "quoted phrases" in text, best matches first.
+It relies on the notes_body text index.
+
+Parameters:
+param1: string, text to search for
+
+Returns:
+return1: []*Note
+return2: error
+*/
+func (nc *NoteCollection) Search(text string) ([]*data.Note, error) {
+	filter := bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: text}}}}
+	score := bson.D{{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}}
+
+	return nc.find(filter, options.Find().SetProjection(score).SetSort(score))
+}
+
+/*
+Update replaces the body and edit time of the note with the matching ID.
+If there is no such note, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Note
+
+Returns:
+return1: error
+*/
+func (nc *NoteCollection) Update(note *data.Note) error {
+	return nc.updateByID(note.ID, bson.D{
+		{Key: "body", Value: note.Body},
+		{Key: "editedat", Value: note.EditedAt},
+		{Key: "version", Value: note.Version},
+	})
+}
+
+/*
+Delete removes the note with the given ID.
+
+Parameters:
+param1: string, ID of the note
+
+Returns:
+return1: error
+*/
+func (nc *NoteCollection) Delete(id string) error {
+	return nc.deleteByID(id)
+}
+
+/*
+DeleteByBook removes the notes of a book, as when it is purged.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: error
+*/
+func (nc *NoteCollection) DeleteByBook(bookID string) error {
+	_, err := nc.deleteMany(bson.D{{Key: "bookid", Value: bookID}})
+
+	return err
+}
+
+/*
+find retrieves the notes matching filter in the given order.
+*/
+func (nc *NoteCollection) find(filter interface{}, opts *options.FindOptions) ([]*data.Note, error) {
+	return findAll[data.Note](&nc.Store, filter, opts)
+}
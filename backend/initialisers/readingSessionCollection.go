@@ -0,0 +1,166 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IReadingSessionCollection interface {
+	Create(session *data.ReadingSession) (interface{}, error)
+	DeleteByBook(bookID string) error
+	GetByBook(bookID string) ([]*data.ReadingSession, error)
+	GetBetween(from, to time.Time) ([]*data.ReadingSession, error)
+	GetOpen(bookID string) (*data.ReadingSession, error)
+	Update(session *data.ReadingSession) error
+}
+
+/*
+ReadingSessionCollection stores reading sessions in MongoDB, with the same timeout and read retry
+behaviour as BookCollection.
+*/
+type ReadingSessionCollection struct {
+	Store
+}
+
+/*
+NewReadingSessionCollection creates a ReadingSessionCollection backed by the "reading_sessions" collection
+of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer ReadingSessionCollection
+*/
+func NewReadingSessionCollection(db *DB) *ReadingSessionCollection {
+	return &ReadingSessionCollection{Store: newStore(db, "reading_sessions")}
+}
+
+/*
+Create inserts a new reading session and sets its ID.
+
+Parameters:
+param1: pointer ReadingSession
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (rc *ReadingSessionCollection) Create(session *data.ReadingSession) (interface{}, error) {
+	data := data.ReadingSessionData{
+		ID:        primitive.NewObjectID(),
+		BookID:    session.BookID,
+		StartedAt: session.StartedAt,
+		EndedAt:   session.EndedAt,
+		StartPage: session.StartPage,
+		EndPage:   session.EndPage,
+		Pages:     session.Pages,
+		Minutes:   session.Minutes,
+		Manual:    session.Manual,
+	}
+
+	insertedID, err := rc.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	session.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+GetByBook retrieves the sessions of a book, most recent first.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: []*ReadingSession
+return2: error
+*/
+func (rc *ReadingSessionCollection) GetByBook(bookID string) ([]*data.ReadingSession, error) {
+	return rc.find(bson.D{{Key: "bookid", Value: bookID}}, -1)
+}
+
+/*
+GetBetween retrieves the sessions started in [from, to), oldest first.
+
+Parameters:
+param1: time.Time, from
+param2: time.Time, to
+
+Returns:
+return1: []*ReadingSession
+return2: error
+*/
+func (rc *ReadingSessionCollection) GetBetween(from, to time.Time) ([]*data.ReadingSession, error) {
+	filter := bson.D{{Key: "startedat", Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lt", Value: to}}}}
+
+	return rc.find(filter, 1)
+}
+
+/*
+GetOpen retrieves the book's timed session that hasn't been stopped yet.
+If there is none, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: pointer ReadingSession
+return2: error
+*/
+func (rc *ReadingSessionCollection) GetOpen(bookID string) (*data.ReadingSession, error) {
+	filter := bson.D{{Key: "bookid", Value: bookID}, {Key: "endedat", Value: nil}}
+
+	return findOne[data.ReadingSession](&rc.Store, filter)
+}
+
+/*
+Update replaces the fields of the session with the matching ID.
+
+Parameters:
+param1: pointer ReadingSession
+
+Returns:
+return1: error
+*/
+func (rc *ReadingSessionCollection) Update(session *data.ReadingSession) error {
+	return rc.updateByID(session.ID, bson.D{
+		{Key: "startedat", Value: session.StartedAt},
+		{Key: "endedat", Value: session.EndedAt},
+		{Key: "startpage", Value: session.StartPage},
+		{Key: "endpage", Value: session.EndPage},
+		{Key: "pages", Value: session.Pages},
+		{Key: "minutes", Value: session.Minutes},
+	})
+}
+
+/*
+DeleteByBook removes the reading sessions of a book, as when it is purged.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: error
+*/
+func (rc *ReadingSessionCollection) DeleteByBook(bookID string) error {
+	_, err := rc.deleteMany(bson.D{{Key: "bookid", Value: bookID}})
+
+	return err
+}
+
+/*
+find retrieves the sessions matching filter sorted by start time, ascending for order 1 and descending for -1.
+*/
+func (rc *ReadingSessionCollection) find(filter interface{}, order int) ([]*data.ReadingSession, error) {
+	return findAll[data.ReadingSession](&rc.Store, filter, options.Find().SetSort(bson.D{{Key: "startedat", Value: order}}))
+}
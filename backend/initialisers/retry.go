@@ -0,0 +1,102 @@
+package initialisers
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+Backoff computes exponential retry delays with full jitter: the nth retry waits a random
+duration between 0 and min(Max, Initial * 2^n).
+*/
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+/*
+Delay returns the jittered delay before the given retry (0 based).
+
+Parameters:
+
+	param1: attempt int
+
+Returns:
+
+	return1: time.Duration
+*/
+func (b Backoff) Delay(attempt int) time.Duration {
+	if b.Initial <= 0 {
+		return 0
+	}
+
+	ceiling := b.Initial
+	for i := 0; i < attempt && (b.Max <= 0 || ceiling < b.Max); i++ {
+		ceiling *= 2
+	}
+
+	if b.Max > 0 && ceiling > b.Max {
+		ceiling = b.Max
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+/*
+retry calls fn up to attempts times while it returns an error accepted by retryable,
+sleeping for the backoff delay in between. It gives up early when ctx is done.
+
+Parameters:
+
+	param1: ctx context.Context
+	param2: attempts int
+	param3: Backoff
+	param4: retryable func(error) bool
+	param5: fn func() error
+
+Returns:
+
+	return1: error, the last error returned by fn
+*/
+func retry(ctx context.Context, attempts int, backoff Backoff, retryable func(error) bool, fn func() error) error {
+	var err error
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = fn(); err == nil || !retryable(err) || attempt == attempts-1 {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Delay(attempt)):
+		}
+	}
+
+	return err
+}
+
+/*
+isTransientError reports whether a MongoDB error is worth retrying: network errors, timeouts and
+errors the server labelled as retryable. Our own context expiring is never transient.
+*/
+func isTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
+	}
+
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) {
+		return labeled.HasErrorLabel("RetryableReadError") || labeled.HasErrorLabel("TransientTransactionError")
+	}
+
+	return false
+}
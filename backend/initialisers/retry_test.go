@@ -0,0 +1,75 @@
+package initialisers
+
+import (
+	"context"
+	"errors"
+	"readinglistapp/internal/data"
+	"readinglistapp/internal/mocks"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		ceiling := backoff.Initial << attempt
+		if ceiling > backoff.Max {
+			ceiling = backoff.Max
+		}
+
+		if delay := backoff.Delay(attempt); delay < 0 || delay > ceiling {
+			t.Errorf("attempt %d: delay %s outside [0, %s]", attempt, delay, ceiling)
+		}
+	}
+}
+
+func TestGetRetriesTransientErrors(t *testing.T) {
+	mockCollection := &mocks.MockCollection{}
+	bookCollection := &BookCollection{Store: Store{Collection: mockCollection, ReadRetries: 2, Backoff: Backoff{Initial: time.Millisecond, Max: time.Millisecond}}}
+
+	calls := 0
+	networkErr := mongo.CommandError{Message: "connection reset", Labels: []string{"NetworkError"}}
+
+	mockCollection.FindOneFunc = func(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+		calls++
+		if calls < 3 {
+			return mongo.NewSingleResultFromDocument(bson.D{}, networkErr, nil)
+		}
+		return mongo.NewSingleResultFromDocument(&data.Book{Title: "Retried"}, nil, bson.DefaultRegistry)
+	}
+
+	book, err := bookCollection.Get("507f1f77bcf86cd799439011")
+
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if calls != 3 || book.Title != "Retried" {
+		t.Errorf("Expected 3 calls and the retried book, got %d calls and %+v", calls, book)
+	}
+}
+
+func TestGetDoesNotRetryPermanentErrors(t *testing.T) {
+	mockCollection := &mocks.MockCollection{}
+	bookCollection := &BookCollection{Store: Store{Collection: mockCollection, ReadRetries: 2}}
+
+	calls := 0
+
+	mockCollection.FindOneFunc = func(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+		calls++
+		return mongo.NewSingleResultFromDocument(bson.D{}, errors.New("unauthorized"), nil)
+	}
+
+	if _, err := bookCollection.Get("507f1f77bcf86cd799439011"); err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 call but got %d", calls)
+	}
+}
@@ -0,0 +1,151 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IReviewCollection interface {
+	Create(review *data.Review) (interface{}, error)
+	Delete(id string) error
+	DeleteByBook(bookID string) error
+	Get(id string) (*data.Review, error)
+	GetByBook(bookID string) ([]*data.Review, error)
+	Update(review *data.Review) error
+}
+
+/*
+ReviewCollection stores book reviews in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type ReviewCollection struct {
+	Store
+}
+
+/*
+NewReviewCollection creates a ReviewCollection backed by the "reviews" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer ReviewCollection
+*/
+func NewReviewCollection(db *DB) *ReviewCollection {
+	return &ReviewCollection{Store: newStore(db, "reviews")}
+}
+
+/*
+Create inserts a new review, setting CreatedAt when it is not set.
+
+Parameters:
+param1: pointer Review
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (rc *ReviewCollection) Create(review *data.Review) (interface{}, error) {
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = time.Now()
+	}
+
+	data := data.ReviewData{
+		ID:        primitive.NewObjectID(),
+		BookID:    review.BookID,
+		Body:      review.Body,
+		Spoiler:   review.Spoiler,
+		CreatedAt: review.CreatedAt,
+		EditedAt:  review.EditedAt,
+		Version:   review.Version,
+	}
+
+	insertedID, err := rc.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	review.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+Get retrieves a review by ID. If there is no such review, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the review
+
+Returns:
+return1: pointer Review
+return2: error
+*/
+func (rc *ReviewCollection) Get(id string) (*data.Review, error) {
+	return findByID[data.Review](&rc.Store, id)
+}
+
+/*
+GetByBook retrieves the reviews of a book, most recent first.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: []*Review
+return2: error
+*/
+func (rc *ReviewCollection) GetByBook(bookID string) ([]*data.Review, error) {
+	return findAll[data.Review](&rc.Store, bson.D{{Key: "bookid", Value: bookID}}, options.Find().SetSort(bson.D{{Key: "createdat", Value: -1}}))
+}
+
+/*
+Update replaces the body, spoiler flag and edit time of the review with the matching ID.
+If there is no such review, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Review
+
+Returns:
+return1: error
+*/
+func (rc *ReviewCollection) Update(review *data.Review) error {
+	return rc.updateByID(review.ID, bson.D{
+		{Key: "body", Value: review.Body},
+		{Key: "spoiler", Value: review.Spoiler},
+		{Key: "editedat", Value: review.EditedAt},
+		{Key: "version", Value: review.Version},
+	})
+}
+
+/*
+Delete removes the review with the given ID.
+
+Parameters:
+param1: string, ID of the review
+
+Returns:
+return1: error
+*/
+func (rc *ReviewCollection) Delete(id string) error {
+	return rc.deleteByID(id)
+}
+
+/*
+DeleteByBook removes the reviews of a book, as when it is purged.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: error
+*/
+func (rc *ReviewCollection) DeleteByBook(bookID string) error {
+	_, err := rc.deleteMany(bson.D{{Key: "bookid", Value: bookID}})
+
+	return err
+}
@@ -0,0 +1,129 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ISeriesCollection interface {
+	Create(series *data.Series) (interface{}, error)
+	Delete(id string) error
+	Get(id string) (*data.Series, error)
+	GetAll() ([]*data.Series, error)
+	Update(series *data.Series) error
+}
+
+/*
+SeriesCollection stores series in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type SeriesCollection struct {
+	Store
+}
+
+/*
+NewSeriesCollection creates a SeriesCollection backed by the "series" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer SeriesCollection
+*/
+func NewSeriesCollection(db *DB) *SeriesCollection {
+	return &SeriesCollection{Store: newStore(db, "series")}
+}
+
+/*
+Create inserts a new series, setting CreatedAt when it is not set.
+
+Parameters:
+param1: pointer Series
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (sc *SeriesCollection) Create(series *data.Series) (interface{}, error) {
+	if series.CreatedAt.IsZero() {
+		series.CreatedAt = time.Now()
+	}
+
+	data := data.SeriesData{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   series.CreatedAt,
+		Name:        series.Name,
+		Description: series.Description,
+		Version:     series.Version,
+	}
+
+	insertedID, err := sc.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	series.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+Get retrieves a series by ID. If there is no such series, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the series
+
+Returns:
+return1: pointer Series
+return2: error
+*/
+func (sc *SeriesCollection) Get(id string) (*data.Series, error) {
+	return findByID[data.Series](&sc.Store, id)
+}
+
+/*
+GetAll retrieves every series ordered by name.
+
+Returns:
+return1: []*Series
+return2: error
+*/
+func (sc *SeriesCollection) GetAll() ([]*data.Series, error) {
+	return findAll[data.Series](&sc.Store, bson.D{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+}
+
+/*
+Update replaces the fields of the series with the matching ID.
+If there is no such series, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Series
+
+Returns:
+return1: error
+*/
+func (sc *SeriesCollection) Update(series *data.Series) error {
+	return sc.updateByID(series.ID, bson.D{
+		{Key: "name", Value: series.Name},
+		{Key: "description", Value: series.Description},
+		{Key: "version", Value: series.Version},
+	})
+}
+
+/*
+Delete removes the series with the given ID.
+
+Parameters:
+param1: string, ID of the series
+
+Returns:
+return1: error
+*/
+func (sc *SeriesCollection) Delete(id string) error {
+	return sc.deleteByID(id)
+}
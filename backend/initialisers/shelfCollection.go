@@ -0,0 +1,171 @@
+package initialisers
+
+import (
+	"errors"
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IShelfCollection interface {
+	Create(shelf *data.Shelf) (interface{}, error)
+	Delete(id string) error
+	Get(id string) (*data.Shelf, error)
+	GetAll() ([]*data.Shelf, error)
+	GetByBook(bookID string) ([]*data.Shelf, error)
+	Update(shelf *data.Shelf) error
+}
+
+/*
+ShelfCollection stores shelves in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type ShelfCollection struct {
+	Store
+}
+
+/*
+NewShelfCollection creates a ShelfCollection backed by the "shelves" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer ShelfCollection
+*/
+func NewShelfCollection(db *DB) *ShelfCollection {
+	return &ShelfCollection{Store: newStore(db, "shelves")}
+}
+
+/*
+Create inserts a new shelf, setting CreatedAt when it is not set.
+A second shelf with the same name returns ErrDuplicateRecord.
+
+Parameters:
+param1: pointer Shelf
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (sh *ShelfCollection) Create(shelf *data.Shelf) (interface{}, error) {
+	if shelf.CreatedAt.IsZero() {
+		shelf.CreatedAt = time.Now()
+	}
+
+	data := data.ShelfData{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   shelf.CreatedAt,
+		Name:        shelf.Name,
+		Description: shelf.Description,
+		BookIDs:     shelf.BookIDs,
+		Version:     shelf.Version,
+	}
+
+	insertedID, err := sh.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	shelf.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+Get retrieves a shelf by ID. If there is no such shelf, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the shelf
+
+Returns:
+return1: pointer Shelf
+return2: error
+*/
+func (sh *ShelfCollection) Get(id string) (*data.Shelf, error) {
+	return findByID[data.Shelf](&sh.Store, id)
+}
+
+/*
+GetAll retrieves every shelf ordered by name.
+
+Returns:
+return1: []*Shelf
+return2: error
+*/
+func (sh *ShelfCollection) GetAll() ([]*data.Shelf, error) {
+	return sh.find(bson.D{})
+}
+
+/*
+GetByBook retrieves the shelves a book is on, ordered by name.
+
+Parameters:
+param1: string, ID of the book
+
+Returns:
+return1: []*Shelf
+return2: error
+*/
+func (sh *ShelfCollection) GetByBook(bookID string) ([]*data.Shelf, error) {
+	return sh.find(bson.D{{Key: "bookids", Value: bookID}})
+}
+
+/*
+Update replaces the fields of the shelf with the matching ID, including the order of its books.
+shelf.Version must be one more than the stored version, so a change made from a stale copy of the shelf
+returns ErrEditConflict instead of overwriting a concurrent one. If there is no such shelf, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Shelf
+
+Returns:
+return1: error
+*/
+func (sh *ShelfCollection) Update(shelf *data.Shelf) error {
+	objID, err := parseToObjectID(shelf.ID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{{Key: "_id", Value: objID}, {Key: "version", Value: shelf.Version - 1}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "name", Value: shelf.Name},
+		{Key: "description", Value: shelf.Description},
+		{Key: "bookids", Value: shelf.BookIDs},
+		{Key: "version", Value: shelf.Version},
+	}}}
+
+	err = sh.updateOne(filter, update)
+	if errors.Is(err, ErrRecordNotFound) {
+		if _, getErr := sh.Get(shelf.ID); getErr == nil {
+			return ErrEditConflict
+		}
+	}
+
+	return err
+}
+
+/*
+Delete removes the shelf with the given ID. Its books are not affected.
+
+Parameters:
+param1: string, ID of the shelf
+
+Returns:
+return1: error
+*/
+func (sh *ShelfCollection) Delete(id string) error {
+	return sh.deleteByID(id)
+}
+
+/*
+find retrieves the shelves matching filter, ordered by name.
+*/
+func (sh *ShelfCollection) find(filter interface{}) ([]*data.Shelf, error) {
+	return findAll[data.Shelf](&sh.Store, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+}
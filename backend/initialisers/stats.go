@@ -0,0 +1,309 @@
+package initialisers
+
+import (
+	"math"
+	"readinglistapp/internal/data"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TopRatedBooks is how many books LibraryStats lists as top rated.
+const TopRatedBooks = 10
+
+/*
+IBookStats is implemented by book collections that can compute the library statistics themselves,
+such as BookCollection with a MongoDB aggregation. Collections without it get ComputeLibraryStats over every book.
+*/
+type IBookStats interface {
+	Stats() (*data.LibraryStats, error)
+}
+
+/*
+Stats computes the library statistics in a single MongoDB aggregation, one $facet per figure.
+Books in the trash are left out.
+
+Returns:
+return1: pointer LibraryStats
+return2: error
+*/
+func (bc *BookCollection) Stats() (*data.LibraryStats, error) {
+	ctx, cancel := bc.context()
+	defer cancel()
+
+	count := bson.D{{Key: "$sum", Value: 1}}
+	rated := bson.D{{Key: "$match", Value: bson.D{{Key: "rating", Value: bson.D{{Key: "$gt", Value: 0}}}}}}
+
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: notDeleted(bson.D{})}},
+		bson.D{{Key: "$facet", Value: bson.D{
+			{Key: "books", Value: bson.A{
+				bson.D{{Key: "$count", Value: "count"}},
+			}},
+			{Key: "unrated", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "rating", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$gt", Value: 0}}}}}}}},
+				bson.D{{Key: "$count", Value: "count"}},
+			}},
+			{Key: "genres", Value: bson.A{
+				bson.D{{Key: "$unwind", Value: "$genres"}},
+				bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$genres"}, {Key: "count", Value: count}}}},
+			}},
+			{Key: "ratings", Value: bson.A{
+				rated,
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$min", Value: bson.A{5, bson.D{{Key: "$max", Value: bson.A{1, bson.D{{Key: "$floor", Value: "$rating"}}}}}}}}},
+					{Key: "count", Value: count},
+				}}},
+			}},
+			{Key: "decades", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "published", Value: bson.D{{Key: "$gt", Value: 0}}}}}},
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$subtract", Value: bson.A{"$published", bson.D{{Key: "$mod", Value: bson.A{"$published", 10}}}}}}},
+					{Key: "count", Value: count},
+				}}},
+			}},
+			{Key: "pages", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "pages", Value: bson.D{{Key: "$gt", Value: 0}}}}}},
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: nil},
+					{Key: "total", Value: bson.D{{Key: "$sum", Value: "$pages"}}},
+					{Key: "average", Value: bson.D{{Key: "$avg", Value: "$pages"}}},
+					{Key: "books", Value: count},
+				}}},
+			}},
+			{Key: "added", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "createdat", Value: bson.D{{Key: "$type", Value: "date"}}}}}},
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{{Key: "format", Value: "%Y-%m"}, {Key: "date", Value: "$createdat"}}}}},
+					{Key: "count", Value: count},
+				}}},
+			}},
+			{Key: "toprated", Value: bson.A{
+				rated,
+				bson.D{{Key: "$sort", Value: bson.D{{Key: "rating", Value: -1}, {Key: "title", Value: 1}}}},
+				bson.D{{Key: "$limit", Value: TopRatedBooks}},
+				bson.D{{Key: "$project", Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$toString", Value: "$_id"}}},
+					{Key: "title", Value: 1},
+					{Key: "authors", Value: 1},
+					{Key: "rating", Value: 1},
+				}}},
+			}},
+		}}},
+	}
+
+	type group struct {
+		ID    interface{} `bson:"_id"`
+		Count int         `bson:"count"`
+	}
+
+	var facets []struct {
+		Books    []group
+		Unrated  []group
+		Genres   []group
+		Ratings  []group
+		Decades  []group
+		Pages    []data.PageStats
+		Added    []group
+		TopRated []data.RatedBook
+	}
+
+	err := bc.retryRead(ctx, func() error {
+		cur, err := bc.Collection.Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+
+		return cur.All(ctx, &facets)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &data.LibraryStats{
+		Genres:        []data.GenreCount{},
+		Decades:       []data.DecadeCount{},
+		AddedPerMonth: []data.MonthCount{},
+		TopRated:      []data.RatedBook{},
+	}
+
+	if len(facets) == 0 {
+		sortLibraryStats(stats)
+		return stats, nil
+	}
+
+	result := facets[0]
+
+	if len(result.Books) > 0 {
+		stats.Books = result.Books[0].Count
+	}
+
+	if len(result.Unrated) > 0 {
+		stats.Unrated = result.Unrated[0].Count
+	}
+
+	for _, genre := range result.Genres {
+		name, _ := genre.ID.(string)
+		stats.Genres = append(stats.Genres, data.GenreCount{Genre: name, Count: genre.Count})
+	}
+
+	for _, rating := range result.Ratings {
+		stats.Ratings = append(stats.Ratings, data.RatingCount{Rating: int(toFloat(rating.ID)), Count: rating.Count})
+	}
+
+	for _, decade := range result.Decades {
+		stats.Decades = append(stats.Decades, data.DecadeCount{Decade: int(toFloat(decade.ID)), Count: decade.Count})
+	}
+
+	if len(result.Pages) > 0 {
+		stats.Pages = result.Pages[0]
+	}
+
+	for _, month := range result.Added {
+		name, _ := month.ID.(string)
+		stats.AddedPerMonth = append(stats.AddedPerMonth, data.MonthCount{Month: name, Count: month.Count})
+	}
+
+	if result.TopRated != nil {
+		stats.TopRated = result.TopRated
+	}
+
+	sortLibraryStats(stats)
+
+	return stats, nil
+}
+
+// toFloat reads a number the aggregation returned as an int32, int64 or double.
+func toFloat(value interface{}) float64 {
+	switch n := value.(type) {
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+/*
+ComputeLibraryStats works out the library statistics from the books themselves. It gives the same figures
+as the aggregation BookCollection.Stats runs in MongoDB, so backends without one can use it.
+
+Parameters:
+param1: []*Book, every book in the library
+
+Returns:
+return1: pointer LibraryStats
+*/
+func ComputeLibraryStats(books []*data.Book) *data.LibraryStats {
+	stats := &data.LibraryStats{Books: len(books)}
+
+	genres := make(map[string]int)
+	ratings := make(map[int]int)
+	decades := make(map[int]int)
+	months := make(map[string]int)
+	var rated []*data.Book
+
+	for _, book := range books {
+		for _, genre := range book.Genres {
+			genres[genre]++
+		}
+
+		if book.Rating > 0 {
+			ratings[ratingBucket(book.Rating)]++
+			rated = append(rated, book)
+		} else {
+			stats.Unrated++
+		}
+
+		if book.Published > 0 {
+			decades[book.Published-book.Published%10]++
+		}
+
+		if book.Pages > 0 {
+			stats.Pages.Total += book.Pages
+			stats.Pages.Books++
+		}
+
+		if !book.CreatedAt.IsZero() {
+			months[book.CreatedAt.UTC().Format("2006-01")]++
+		}
+	}
+
+	if stats.Pages.Books > 0 {
+		stats.Pages.Average = float64(stats.Pages.Total) / float64(stats.Pages.Books)
+	}
+
+	stats.Genres = []data.GenreCount{}
+	for genre, count := range genres {
+		stats.Genres = append(stats.Genres, data.GenreCount{Genre: genre, Count: count})
+	}
+
+	stats.Ratings = []data.RatingCount{}
+	for rating, count := range ratings {
+		stats.Ratings = append(stats.Ratings, data.RatingCount{Rating: rating, Count: count})
+	}
+
+	stats.Decades = []data.DecadeCount{}
+	for decade, count := range decades {
+		stats.Decades = append(stats.Decades, data.DecadeCount{Decade: decade, Count: count})
+	}
+
+	stats.AddedPerMonth = []data.MonthCount{}
+	for month, count := range months {
+		stats.AddedPerMonth = append(stats.AddedPerMonth, data.MonthCount{Month: month, Count: count})
+	}
+
+	sort.SliceStable(rated, func(i, j int) bool {
+		if rated[i].Rating != rated[j].Rating {
+			return rated[i].Rating > rated[j].Rating
+		}
+		return rated[i].Title < rated[j].Title
+	})
+
+	stats.TopRated = []data.RatedBook{}
+	for _, book := range rated {
+		if len(stats.TopRated) == TopRatedBooks {
+			break
+		}
+		stats.TopRated = append(stats.TopRated, data.RatedBook{ID: book.ID, Title: book.Title, Authors: book.Authors, Rating: book.Rating})
+	}
+
+	sortLibraryStats(stats)
+
+	return stats
+}
+
+// ratingBucket is the whole-star histogram bucket of a rating, from 1 to 5.
+func ratingBucket(rating float64) int {
+	return int(math.Min(5, math.Max(1, math.Floor(rating))))
+}
+
+/*
+sortLibraryStats puts the figures in their documented order, whichever way they were computed:
+genres by count then name, and ratings, decades and months ascending.
+Every star from 1 to 5 gets a rating bucket, even when no book has that rating.
+*/
+func sortLibraryStats(stats *data.LibraryStats) {
+	sort.Slice(stats.Genres, func(i, j int) bool {
+		if stats.Genres[i].Count != stats.Genres[j].Count {
+			return stats.Genres[i].Count > stats.Genres[j].Count
+		}
+		return stats.Genres[i].Genre < stats.Genres[j].Genre
+	})
+
+	counts := make(map[int]int, len(stats.Ratings))
+	for _, bucket := range stats.Ratings {
+		counts[bucket.Rating] = bucket.Count
+	}
+
+	stats.Ratings = make([]data.RatingCount, 0, 5)
+	for rating := 1; rating <= 5; rating++ {
+		stats.Ratings = append(stats.Ratings, data.RatingCount{Rating: rating, Count: counts[rating]})
+	}
+
+	sort.Slice(stats.Decades, func(i, j int) bool { return stats.Decades[i].Decade < stats.Decades[j].Decade })
+	sort.Slice(stats.AddedPerMonth, func(i, j int) bool { return stats.AddedPerMonth[i].Month < stats.AddedPerMonth[j].Month })
+}
@@ -0,0 +1,48 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComputeLibraryStats(t *testing.T) {
+	march := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	books := []*data.Book{
+		{ID: "1", Title: "Dune", Genres: []string{"Sci-Fi", "Classic"}, Rating: 4.5, Published: 1965, Pages: 412, CreatedAt: march},
+		{ID: "2", Title: "Emma", Genres: []string{"Classic"}, Rating: 4.5, Published: 1815, Pages: 474, CreatedAt: march},
+		{ID: "3", Title: "Hyperion", Genres: []string{"Sci-Fi"}, Rating: 0.5, Published: 1989, CreatedAt: march.AddDate(0, 1, 0)},
+		{ID: "4", Title: "Untitled"},
+	}
+
+	stats := ComputeLibraryStats(books)
+
+	if stats.Books != 4 || stats.Unrated != 1 {
+		t.Errorf("got %d books and %d unrated, expected 4 and 1", stats.Books, stats.Unrated)
+	}
+
+	if expected := []data.GenreCount{{Genre: "Classic", Count: 2}, {Genre: "Sci-Fi", Count: 2}}; !reflect.DeepEqual(stats.Genres, expected) {
+		t.Errorf("got genres %v, expected %v", stats.Genres, expected)
+	}
+
+	if expected := []data.RatingCount{{Rating: 1, Count: 1}, {Rating: 2}, {Rating: 3}, {Rating: 4, Count: 2}, {Rating: 5}}; !reflect.DeepEqual(stats.Ratings, expected) {
+		t.Errorf("got ratings %v, expected %v", stats.Ratings, expected)
+	}
+
+	if expected := []data.DecadeCount{{Decade: 1810, Count: 1}, {Decade: 1960, Count: 1}, {Decade: 1980, Count: 1}}; !reflect.DeepEqual(stats.Decades, expected) {
+		t.Errorf("got decades %v, expected %v", stats.Decades, expected)
+	}
+
+	if expected := (data.PageStats{Total: 886, Average: 443, Books: 2}); stats.Pages != expected {
+		t.Errorf("got pages %+v, expected %+v", stats.Pages, expected)
+	}
+
+	if expected := []data.MonthCount{{Month: "2024-03", Count: 2}, {Month: "2024-04", Count: 1}}; !reflect.DeepEqual(stats.AddedPerMonth, expected) {
+		t.Errorf("got added per month %v, expected %v", stats.AddedPerMonth, expected)
+	}
+
+	if len(stats.TopRated) != 3 || stats.TopRated[0].Title != "Dune" || stats.TopRated[1].Title != "Emma" {
+		t.Errorf("got top rated %v, expected Dune, Emma then Hyperion", stats.TopRated)
+	}
+}
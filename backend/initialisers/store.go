@@ -0,0 +1,315 @@
+package initialisers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+Store holds what every MongoDB collection shares: the collection itself, the timeout bounding each operation and
+how idempotent reads are retried on transient errors, ReadRetries times waiting Backoff in between.
+The typed collections embed it and only describe their documents.
+*/
+type Store struct {
+	Collection  ICollection
+	Timeout     time.Duration
+	ReadRetries int
+	Backoff     Backoff
+
+	// parent, when set, is the context every operation derives from, such as a transaction's mongo.SessionContext.
+	parent context.Context
+}
+
+/*
+newStore creates a Store for the named collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+param2: string, name of the collection
+
+Returns:
+
+return1: Store
+*/
+func newStore(db *DB, name string) Store {
+	return Store{
+		Collection:  db.client.Database(db.name).Collection(name),
+		Timeout:     db.operationTimeout,
+		ReadRetries: db.readRetries,
+		Backoff:     db.backoff,
+	}
+}
+
+/*
+context returns a context bounded by the store's operation timeout.
+*/
+func (s *Store) context() (context.Context, context.CancelFunc) {
+	if s.parent != nil {
+		return boundedContext(s.parent, s.Timeout)
+	}
+
+	return operationContext(s.Timeout)
+}
+
+/*
+retryRead runs an idempotent read, retrying it on transient errors.
+
+Parameters:
+param1: context.Context, bounds the total time spent including retries
+param2: func() error, the read operation
+
+Returns:
+return1: error
+*/
+func (s *Store) retryRead(ctx context.Context, read func() error) error {
+	return retry(ctx, s.ReadRetries+1, s.Backoff, isTransientError, read)
+}
+
+/*
+insert inserts a document, reporting unique index violations as ErrDuplicateRecord.
+
+Parameters:
+param1: interface{}, the document
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (s *Store) insert(document interface{}) (interface{}, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	result, err := s.Collection.InsertOne(ctx, document)
+	if err != nil {
+		return nil, translateWriteError(err)
+	}
+
+	return result.InsertedID, nil
+}
+
+/*
+updateOne applies update to the document matching filter.
+If no document matches, it returns ErrRecordNotFound.
+
+Parameters:
+param1: interface{}, filter
+param2: interface{}, update
+
+Returns:
+return1: error
+*/
+func (s *Store) updateOne(filter, update interface{}) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	result, err := s.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return translateWriteError(err)
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+/*
+updateByID sets fields on the document with the given ID.
+If there is no such document, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the document
+param2: bson.D, fields to set
+
+Returns:
+return1: error
+*/
+func (s *Store) updateByID(id string, fields bson.D) error {
+	objID, err := parseToObjectID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.updateOne(bson.D{{Key: "_id", Value: objID}}, bson.D{{Key: "$set", Value: fields}})
+}
+
+/*
+deleteMany removes the documents matching filter.
+
+Parameters:
+param1: interface{}, filter
+
+Returns:
+return1: int64, number of documents removed
+return2: error
+*/
+func (s *Store) deleteMany(filter interface{}) (int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	result, err := s.Collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+/*
+deleteByID removes the document with the given ID. Deleting a missing document does nothing.
+
+Parameters:
+param1: string, ID of the document
+
+Returns:
+return1: error
+*/
+func (s *Store) deleteByID(id string) error {
+	objID, err := parseToObjectID(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.deleteMany(bson.D{{Key: "_id", Value: objID}})
+
+	return err
+}
+
+/*
+findOne decodes the first document matching filter into a T, retrying transient errors.
+If no document matches, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Store
+param2: interface{}, filter
+param3: ...*options.FindOneOptions
+
+Returns:
+return1: pointer T
+return2: error
+*/
+func findOne[T any](s *Store, filter interface{}, opts ...*options.FindOneOptions) (*T, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	var result T
+
+	err := s.retryRead(ctx, func() error {
+		return s.Collection.FindOne(ctx, filter, opts...).Decode(&result)
+	})
+
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+/*
+findByID decodes the document with the given ID into a T.
+If there is no such document, it returns ErrRecordNotFound.
+
+Parameters:
+param1: pointer Store
+param2: string, ID of the document
+
+Returns:
+return1: pointer T
+return2: error
+*/
+func findByID[T any](s *Store, id string) (*T, error) {
+	objID, err := parseToObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return findOne[T](s, bson.D{{Key: "_id", Value: objID}})
+}
+
+/*
+findAll decodes every document matching filter into a T, retrying transient errors.
+A retry starts over, so a failed attempt never leaves partial results.
+
+Parameters:
+param1: pointer Store
+param2: interface{}, filter
+param3: ...*options.FindOptions
+
+Returns:
+return1: []*T
+return2: error
+*/
+func findAll[T any](s *Store, filter interface{}, opts ...*options.FindOptions) ([]*T, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	var results []*T
+
+	err := s.retryRead(ctx, func() error {
+		results = nil
+
+		cur, err := s.Collection.Find(ctx, filter, opts...)
+		if err != nil {
+			return err
+		}
+
+		defer cur.Close(ctx)
+
+		for cur.Next(ctx) {
+			var elem T
+			if err := cur.Decode(&elem); err != nil {
+				return err
+			}
+
+			results = append(results, &elem)
+		}
+
+		return cur.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+/*
+translateWriteError reports unique index violations as ErrDuplicateRecord.
+*/
+func translateWriteError(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %v", ErrDuplicateRecord, err)
+	}
+
+	return err
+}
+
+/*
+operationContext returns a context bounded by timeout, or DefaultOperationTimeout when it isn't set.
+*/
+func operationContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	return boundedContext(context.Background(), timeout)
+}
+
+/*
+boundedContext derives a context from parent bounded by timeout, or DefaultOperationTimeout when it isn't set.
+*/
+func boundedContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = DefaultOperationTimeout
+	}
+
+	return context.WithTimeout(parent, timeout)
+}
@@ -0,0 +1,156 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ITagCollection interface {
+	Create(tag *data.Tag) (interface{}, error)
+	Delete(id string) error
+	Get(id string) (*data.Tag, error)
+	GetAll() ([]*data.Tag, error)
+	GetByPrefix(prefix string, limit int64) ([]*data.Tag, error)
+	Update(tag *data.Tag) error
+}
+
+/*
+TagCollection stores the tag taxonomy in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type TagCollection struct {
+	Store
+}
+
+/*
+NewTagCollection creates a TagCollection backed by the "tags" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer TagCollection
+*/
+func NewTagCollection(db *DB) *TagCollection {
+	return &TagCollection{Store: newStore(db, "tags")}
+}
+
+/*
+Create inserts a new tag, setting CreatedAt when it is not set.
+A tag with the same name as an existing one returns ErrDuplicateRecord.
+
+Parameters:
+param1: pointer Tag
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (tc *TagCollection) Create(tag *data.Tag) (interface{}, error) {
+	if tag.CreatedAt.IsZero() {
+		tag.CreatedAt = time.Now()
+	}
+
+	data := data.TagData{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: tag.CreatedAt,
+		Name:      tag.Name,
+		ParentID:  tag.ParentID,
+		Version:   tag.Version,
+	}
+
+	insertedID, err := tc.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tag.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+Get retrieves a tag by ID. If there is no such tag, it returns ErrRecordNotFound.
+
+Parameters:
+param1: string, ID of the tag
+
+Returns:
+return1: pointer Tag
+return2: error
+*/
+func (tc *TagCollection) Get(id string) (*data.Tag, error) {
+	return findByID[data.Tag](&tc.Store, id)
+}
+
+/*
+GetAll retrieves every tag, ordered by name.
+
+Returns:
+return1: []*Tag
+return2: error
+*/
+func (tc *TagCollection) GetAll() ([]*data.Tag, error) {
+	return tc.find(bson.D{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+}
+
+/*
+GetByPrefix retrieves up to limit tags whose name starts with prefix, ordered by name.
+
+Parameters:
+param1: string, start of the name
+param2: int64, maximum number of tags
+
+Returns:
+return1: []*Tag
+return2: error
+*/
+func (tc *TagCollection) GetByPrefix(prefix string, limit int64) ([]*data.Tag, error) {
+	filter := bson.D{{Key: "name", Value: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}}}
+
+	return tc.find(filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetLimit(limit))
+}
+
+/*
+Update replaces the name and parent of the tag with the matching ID.
+If there is no such tag, it returns ErrRecordNotFound; if the new name is taken, ErrDuplicateRecord.
+
+Parameters:
+param1: pointer Tag
+
+Returns:
+return1: error
+*/
+func (tc *TagCollection) Update(tag *data.Tag) error {
+	return tc.updateByID(tag.ID, bson.D{
+		{Key: "name", Value: tag.Name},
+		{Key: "parentid", Value: tag.ParentID},
+		{Key: "version", Value: tag.Version},
+	})
+}
+
+/*
+Delete removes the tag with the given ID.
+
+Parameters:
+param1: string, ID of the tag
+
+Returns:
+return1: error
+*/
+func (tc *TagCollection) Delete(id string) error {
+	return tc.deleteByID(id)
+}
+
+/*
+find retrieves the tags matching filter.
+*/
+func (tc *TagCollection) find(filter interface{}, opts *options.FindOptions) ([]*data.Tag, error) {
+	return findAll[data.Tag](&tc.Store, filter, opts)
+}
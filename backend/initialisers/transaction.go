@@ -0,0 +1,88 @@
+package initialisers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrTransactionsUnsupported is returned by BookTransaction when the server can't run transactions, as a standalone one.
+var ErrTransactionsUnsupported = errors.New("transactions are not supported by this MongoDB deployment")
+
+type ITransactor interface {
+	BookTransaction(fn func(books IBookCollection) error) error
+}
+
+/*
+Transactor runs changes to several books as one MongoDB transaction.
+Transactions need a replica set or a sharded cluster; on a standalone server BookTransaction fails with
+ErrTransactionsUnsupported on the first operation of fn, before anything is written.
+*/
+type Transactor struct {
+	client *mongo.Client
+	books  *BookCollection
+}
+
+/*
+NewTransactor creates a Transactor for the books collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer Transactor
+*/
+func NewTransactor(db *DB) *Transactor {
+	return &Transactor{
+		client: db.client,
+		books:  NewBookCollection(db),
+	}
+}
+
+/*
+BookTransaction calls fn with a book collection whose operations all belong to one transaction.
+The transaction is committed when fn returns nil and aborted when it returns an error, which is returned as is.
+fn may be called more than once when the transaction is retried after a transient error, so it must not keep state between calls.
+
+Parameters:
+param1: func(books IBookCollection) error
+
+Returns:
+return1: error
+*/
+func (t *Transactor) BookTransaction(fn func(books IBookCollection) error) error {
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(context.Background(), func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(t.books.WithContext(sc))
+	})
+
+	if isTransactionsUnsupported(err) {
+		return fmt.Errorf("%w: %v", ErrTransactionsUnsupported, err)
+	}
+
+	return err
+}
+
+/*
+isTransactionsUnsupported reports whether the server refused a transaction because it isn't part of a replica set
+or sharded cluster.
+*/
+func isTransactionsUnsupported(err error) bool {
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+
+	// IllegalOperation, "Transaction numbers are only allowed on a replica set member or mongos"
+	return cmdErr.Code == 20 && strings.Contains(cmdErr.Message, "Transaction numbers")
+}
@@ -0,0 +1,80 @@
+package initialisers
+
+import (
+	"readinglistapp/internal/data"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IUserCollection interface {
+	Create(user *data.User) (interface{}, error)
+	GetAll() ([]*data.User, error)
+}
+
+/*
+UserCollection stores the users in MongoDB, with the same timeout and read retry behaviour as BookCollection.
+*/
+type UserCollection struct {
+	Store
+}
+
+/*
+NewUserCollection creates a UserCollection backed by the "users" collection of the configured database.
+
+Parameters:
+
+param1: pointer DB
+
+Returns:
+
+return1: pointer UserCollection
+*/
+func NewUserCollection(db *DB) *UserCollection {
+	return &UserCollection{Store: newStore(db, "users")}
+}
+
+/*
+Create inserts a new user, setting CreatedAt when it is not set.
+A user with the same name as an existing one returns ErrDuplicateRecord.
+
+Parameters:
+param1: pointer User
+
+Returns:
+return1: interface{}, ID of the inserted document
+return2: error
+*/
+func (uc *UserCollection) Create(user *data.User) (interface{}, error) {
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+
+	data := data.UserData{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: user.CreatedAt,
+		Name:      user.Name,
+	}
+
+	insertedID, err := uc.insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	user.ID = data.ID.Hex()
+
+	return insertedID, nil
+}
+
+/*
+GetAll retrieves every user, ordered by name.
+
+Returns:
+return1: []*User
+return2: error
+*/
+func (uc *UserCollection) GetAll() ([]*data.User, error) {
+	return findAll[data.User](&uc.Store, bson.D{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+}
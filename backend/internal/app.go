@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"readinglistapp/initialisers"
+	"readinglistapp/settings"
+)
+
+/*
+NewApp connects to the database and wires every collection, guarded by one circuit breaker, the same way for
+the server and the command-line tool. Sessions are left to the server, the only one serving pages.
+
+Parameters:
+
+	param1: pointer settings.Config
+
+Returns:
+
+	return1: pointer App
+	return2: error, when the database can't be reached
+*/
+func NewApp(cfg *settings.Config) (*App, error) {
+	db, err := initialisers.NewDB(cfg.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	books := initialisers.NewCircuitBreaker(initialisers.NewBookCollection(db), breakerOptions(cfg))
+
+	app := &App{
+		DB:              db,
+		Books:           books,
+		Authors:         books.GuardAuthors(initialisers.NewAuthorCollection(db)),
+		Series:          books.GuardSeries(initialisers.NewSeriesCollection(db)),
+		ReadingSessions: books.GuardReadingSessions(initialisers.NewReadingSessionCollection(db)),
+		Goals:           books.GuardGoals(initialisers.NewGoalCollection(db)),
+		Shelves:         books.GuardShelves(initialisers.NewShelfCollection(db)),
+		Reviews:         books.GuardReviews(initialisers.NewReviewCollection(db)),
+		Notes:           books.GuardNotes(initialisers.NewNoteCollection(db)),
+		Highlights:      books.GuardHighlights(initialisers.NewHighlightCollection(db)),
+		Tags:            books.GuardTags(initialisers.NewTagCollection(db)),
+		Users:           books.GuardUsers(initialisers.NewUserCollection(db)),
+		Transactor:      books.GuardTransactions(initialisers.NewTransactor(db)),
+		Documents:       initialisers.NewDocumentStore(db),
+		Config:          cfg,
+	}
+
+	app.View = app.NewView()
+	app.Model = app.NewModel()
+
+	return app, nil
+}
+
+/*
+breakerOptions maps the circuit breaker settings of the configuration onto initialisers.BreakerOptions.
+*/
+func breakerOptions(cfg *settings.Config) initialisers.BreakerOptions {
+	return initialisers.BreakerOptions{
+		FailureThreshold: cfg.Breaker.FailureThreshold,
+		ErrorRate:        cfg.Breaker.ErrorRate,
+		MinRequests:      cfg.Breaker.MinRequests,
+		Window:           cfg.Breaker.Window,
+		OpenTimeout:      cfg.Breaker.OpenTimeout,
+		HalfOpenProbes:   cfg.Breaker.HalfOpenProbes,
+	}
+}
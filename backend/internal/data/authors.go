@@ -0,0 +1,31 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Author struct {
+	ID        string    `json:"_id" bson:"_id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Name      string    `json:"name"`
+	SortName  string    `json:"sortName"`
+	BirthYear int       `json:"birthYear,omitempty"`
+	DeathYear int       `json:"deathYear,omitempty"`
+	Bio       string    `json:"bio,omitempty"`
+	Aliases   []string  `json:"aliases,omitempty"`
+	Version   int32     `json:"version,omitempty"`
+}
+
+type AuthorData struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Name      string             `json:"name"`
+	SortName  string             `json:"sortName"`
+	BirthYear int                `json:"birthYear,omitempty"`
+	DeathYear int                `json:"deathYear,omitempty"`
+	Bio       string             `json:"bio,omitempty"`
+	Aliases   []string           `json:"aliases,omitempty"`
+	Version   int32              `json:"version,omitempty"`
+}
@@ -6,24 +6,86 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Reading statuses a book moves through.
+const (
+	StatusWantToRead = "want_to_read"
+	StatusReading    = "reading"
+	StatusFinished   = "finished"
+	StatusAbandoned  = "abandoned"
+)
+
+var Statuses = []string{StatusWantToRead, StatusReading, StatusFinished, StatusAbandoned}
+
+/*
+BookFilter narrows the books returned by IBookCollection.GetFiltered. Empty fields don't filter;
+a book matches a list when it has any of the values in it. Owners may hold "", for the books nobody owns. Books in the trash are left out
+unless IncludeDeleted is set, as when renaming or merging must reach every book.
+*/
+type BookFilter struct {
+	Owners         []string
+	Statuses       []string
+	Tags           []string
+	AuthorIDs      []string
+	IncludeDeleted bool
+}
+
 type Book struct {
-	ID        string    `json:"_id" bson:"_id"`
-	CreatedAt time.Time `json:"createdAt"`
-	Title     string    `json:"title"`
-	Published int       `json:"published,omitempty"`
-	Pages     int       `json:"pages,omitempty"`
-	Genres    []string  `json:"genres,omitempty"`
-	Rating    float64   `json:"rating,omitempty"`
-	Version   int32     `json:"version,omitempty"`
+	ID             string     `json:"_id" bson:"_id"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	Owner          string     `json:"owner,omitempty"`
+	Title          string     `json:"title"`
+	Authors        []string   `json:"authors,omitempty"`
+	AuthorIDs      []string   `json:"authorIds,omitempty"`
+	ISBN10         string     `json:"isbn10,omitempty"`
+	ISBN13         string     `json:"isbn13,omitempty"`
+	Publisher      string     `json:"publisher,omitempty"`
+	Language       string     `json:"language,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	Edition        string     `json:"edition,omitempty"`
+	Series         string     `json:"series,omitempty"`
+	SeriesID       string     `json:"seriesId,omitempty"`
+	SeriesPosition float64    `json:"seriesPosition,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	CurrentPage    int        `json:"currentPage,omitempty"`
+	Percent        float64    `json:"percent" bson:"-"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+	AbandonedAt    *time.Time `json:"abandonedAt,omitempty"`
+	Published      int        `json:"published,omitempty"`
+	Pages          int        `json:"pages,omitempty"`
+	Genres         []string   `json:"genres,omitempty"`
+	Tags           []string   `json:"tags,omitempty"`
+	Rating         float64    `json:"rating,omitempty"`
+	Version        int32      `json:"version,omitempty"`
+	DeletedAt      *time.Time `json:"deletedAt,omitempty"`
 }
 
 type BookData struct {
-	ID        primitive.ObjectID `json:"_id" bson:"_id"`
-	CreatedAt time.Time          `json:"createdAt"`
-	Title     string             `json:"title"`
-	Published int                `json:"published,omitempty"`
-	Pages     int                `json:"pages,omitempty"`
-	Genres    []string           `json:"genres,omitempty"`
-	Rating    float64            `json:"rating,omitempty"`
-	Version   int32              `json:"version,omitempty"`
+	ID             primitive.ObjectID `json:"_id" bson:"_id"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	Owner          string             `json:"owner,omitempty"`
+	Title          string             `json:"title"`
+	Authors        []string           `json:"authors,omitempty"`
+	AuthorIDs      []string           `json:"authorIds,omitempty"`
+	ISBN10         string             `json:"isbn10,omitempty"`
+	ISBN13         string             `json:"isbn13,omitempty"`
+	Publisher      string             `json:"publisher,omitempty"`
+	Language       string             `json:"language,omitempty"`
+	Description    string             `json:"description,omitempty"`
+	Edition        string             `json:"edition,omitempty"`
+	Series         string             `json:"series,omitempty"`
+	SeriesID       string             `json:"seriesId,omitempty"`
+	SeriesPosition float64            `json:"seriesPosition,omitempty"`
+	Status         string             `json:"status,omitempty"`
+	CurrentPage    int                `json:"currentPage,omitempty"`
+	StartedAt      *time.Time         `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time         `json:"finishedAt,omitempty"`
+	AbandonedAt    *time.Time         `json:"abandonedAt,omitempty"`
+	Published      int                `json:"published,omitempty"`
+	Pages          int                `json:"pages,omitempty"`
+	Genres         []string           `json:"genres,omitempty"`
+	Tags           []string           `json:"tags,omitempty"`
+	Rating         float64            `json:"rating,omitempty"`
+	Version        int32              `json:"version,omitempty"`
+	DeletedAt      *time.Time         `json:"deletedAt,omitempty" bson:"deletedat,omitempty"`
 }
@@ -0,0 +1,69 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	GoalMetricBooks = "books"
+	GoalMetricPages = "pages"
+)
+
+var GoalMetrics = []string{GoalMetricBooks, GoalMetricPages}
+
+const (
+	GoalAhead      = "ahead"
+	GoalOnTrack    = "on_track"
+	GoalBehind     = "behind"
+	GoalCompleted  = "completed"
+	GoalMissed     = "missed"
+	GoalNotStarted = "not_started"
+)
+
+/*
+Goal is a target number of books or pages to finish in a year, or in one month of it when Month is set.
+Owner names whose goal it is, as Book.Owner names whose book it is; goals with the same owner, metric and period
+are unique. A goal's progress counts the books of its owner, those nobody owns for a goal without one.
+*/
+type Goal struct {
+	ID        string    `json:"_id" bson:"_id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Owner     string    `json:"owner,omitempty"`
+	Metric    string    `json:"metric"`
+	Year      int       `json:"year"`
+	Month     int       `json:"month,omitempty"`
+	Target    int       `json:"target"`
+	Version   int32     `json:"version,omitempty"`
+}
+
+type GoalData struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Owner     string             `json:"owner,omitempty"`
+	Metric    string             `json:"metric"`
+	Year      int                `json:"year"`
+	Month     int                `json:"month,omitempty"`
+	Target    int                `json:"target"`
+	Version   int32              `json:"version,omitempty"`
+}
+
+/*
+GoalProgress reports how far a goal has got: the books or pages finished so far, how many were expected by now
+at an even pace and where the current pace will end up.
+*/
+type GoalProgress struct {
+	Goal                *Goal      `json:"goal"`
+	Start               time.Time  `json:"start"`
+	End                 time.Time  `json:"end"`
+	Current             int        `json:"current"`
+	Percent             float64    `json:"percent"`
+	Expected            int        `json:"expected"`
+	Difference          int        `json:"difference"`
+	Status              string     `json:"status"`
+	PerDay              float64    `json:"perDay"`
+	NeededPerDay        float64    `json:"neededPerDay,omitempty"`
+	ProjectedTotal      int        `json:"projectedTotal"`
+	ProjectedCompletion *time.Time `json:"projectedCompletion,omitempty"`
+}
@@ -0,0 +1,36 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Highlight is a passage quoted from a book, found by page number or, for e-books, by a location such as "1234"
+or "chapter 3". BookTitle is filled in on listings across the library.
+*/
+type Highlight struct {
+	ID        string    `json:"_id" bson:"_id"`
+	BookID    string    `json:"bookId"`
+	Quote     string    `json:"quote"`
+	Page      int       `json:"page,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	Version   int32     `json:"version,omitempty"`
+	BookTitle string    `json:"bookTitle,omitempty" bson:"-"`
+}
+
+type HighlightData struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`
+	BookID    string             `json:"bookId"`
+	Quote     string             `json:"quote"`
+	Page      int                `json:"page,omitempty"`
+	Location  string             `json:"location,omitempty"`
+	Comment   string             `json:"comment,omitempty"`
+	Tags      []string           `json:"tags,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Version   int32              `json:"version,omitempty"`
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+ReadingSession is a stretch of reading of one book, either timed with start and stop or entered manually.
+A timed session is open until EndedAt is set.
+*/
+type ReadingSession struct {
+	ID        string     `json:"_id" bson:"_id"`
+	BookID    string     `json:"bookId"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	StartPage int        `json:"startPage,omitempty"`
+	EndPage   int        `json:"endPage,omitempty"`
+	Pages     int        `json:"pages"`
+	Minutes   int        `json:"minutes"`
+	Manual    bool       `json:"manual,omitempty"`
+}
+
+type ReadingSessionData struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`
+	BookID    string             `json:"bookId"`
+	StartedAt time.Time          `json:"startedAt"`
+	EndedAt   *time.Time         `json:"endedAt,omitempty"`
+	StartPage int                `json:"startPage,omitempty"`
+	EndPage   int                `json:"endPage,omitempty"`
+	Pages     int                `json:"pages"`
+	Minutes   int                `json:"minutes"`
+	Manual    bool               `json:"manual,omitempty"`
+}
+
+/*
+DailyPages is the number of pages read on one day (UTC, formatted 2006-01-02).
+*/
+type DailyPages struct {
+	Date  string `json:"date"`
+	Pages int    `json:"pages"`
+}
+
+/*
+ReadingStats summarises reading sessions over a period.
+*/
+type ReadingStats struct {
+	From                  time.Time    `json:"from"`
+	To                    time.Time    `json:"to"`
+	Sessions              int          `json:"sessions"`
+	TotalPages            int          `json:"totalPages"`
+	TotalMinutes          int          `json:"totalMinutes"`
+	PagesPerDay           []DailyPages `json:"pagesPerDay,omitempty"`
+	AveragePagesPerDay    float64      `json:"averagePagesPerDay"`
+	PagesPerHour          float64      `json:"pagesPerHour"`
+	BooksFinished         int          `json:"booksFinished"`
+	AverageMinutesPerBook float64      `json:"averageMinutesPerBook"`
+}
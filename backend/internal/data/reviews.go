@@ -0,0 +1,54 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Review is a markdown write-up of a book. Spoiler marks reviews that give the plot away, so they can be hidden
+until asked for. EditedAt is set once the review has been changed.
+*/
+type Review struct {
+	ID        string     `json:"_id" bson:"_id"`
+	BookID    string     `json:"bookId"`
+	Body      string     `json:"body"`
+	Spoiler   bool       `json:"spoiler"`
+	CreatedAt time.Time  `json:"createdAt"`
+	EditedAt  *time.Time `json:"editedAt,omitempty"`
+	Version   int32      `json:"version,omitempty"`
+}
+
+type ReviewData struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`
+	BookID    string             `json:"bookId"`
+	Body      string             `json:"body"`
+	Spoiler   bool               `json:"spoiler"`
+	CreatedAt time.Time          `json:"createdAt"`
+	EditedAt  *time.Time         `json:"editedAt,omitempty"`
+	Version   int32              `json:"version,omitempty"`
+}
+
+/*
+Note is a private markdown note about a book, such as a reminder or a thought while reading.
+BookTitle is filled in on search results.
+*/
+type Note struct {
+	ID        string     `json:"_id" bson:"_id"`
+	BookID    string     `json:"bookId"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"createdAt"`
+	EditedAt  *time.Time `json:"editedAt,omitempty"`
+	Version   int32      `json:"version,omitempty"`
+	BookTitle string     `json:"bookTitle,omitempty" bson:"-"`
+}
+
+type NoteData struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`
+	BookID    string             `json:"bookId"`
+	Body      string             `json:"body"`
+	CreatedAt time.Time          `json:"createdAt"`
+	EditedAt  *time.Time         `json:"editedAt,omitempty"`
+	Version   int32              `json:"version,omitempty"`
+}
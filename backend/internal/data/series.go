@@ -0,0 +1,28 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Series groups books read in order. Membership is stored on the books (SeriesID and SeriesPosition),
+Entries is filled in when a single series is requested.
+*/
+type Series struct {
+	ID          string    `json:"_id" bson:"_id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Version     int32     `json:"version,omitempty"`
+	Entries     []*Book   `json:"entries,omitempty" bson:"-"`
+}
+
+type SeriesData struct {
+	ID          primitive.ObjectID `json:"_id" bson:"_id"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Version     int32              `json:"version,omitempty"`
+}
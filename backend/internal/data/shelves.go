@@ -0,0 +1,30 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Shelf is a named list of books in an order chosen by hand. A book can be on any number of shelves.
+BookIDs holds the order, Books is filled in when a single shelf is requested.
+*/
+type Shelf struct {
+	ID          string    `json:"_id" bson:"_id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	BookIDs     []string  `json:"bookIds"`
+	Version     int32     `json:"version,omitempty"`
+	Books       []*Book   `json:"books,omitempty" bson:"-"`
+}
+
+type ShelfData struct {
+	ID          primitive.ObjectID `json:"_id" bson:"_id"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	BookIDs     []string           `json:"bookIds"`
+	Version     int32              `json:"version,omitempty"`
+}
@@ -0,0 +1,56 @@
+package data
+
+/*
+LibraryStats summarises the whole library. Ratings are grouped by whole stars, rounded down, from 1 to 5;
+ratings under one star count as one star. Unrated books, and books without a publication year or
+page count, are left out of the matching figures.
+*/
+type LibraryStats struct {
+	Books         int           `json:"books"`
+	Unrated       int           `json:"unrated"`
+	Genres        []GenreCount  `json:"genres"`
+	Ratings       []RatingCount `json:"ratings"`
+	Decades       []DecadeCount `json:"decades"`
+	Pages         PageStats     `json:"pages"`
+	AddedPerMonth []MonthCount  `json:"addedPerMonth"`
+	TopRated      []RatedBook   `json:"topRated"`
+}
+
+type GenreCount struct {
+	Genre string `json:"genre"`
+	Count int    `json:"count"`
+}
+
+type RatingCount struct {
+	Rating int `json:"rating"`
+	Count  int `json:"count"`
+}
+
+type DecadeCount struct {
+	Decade int `json:"decade"`
+	Count  int `json:"count"`
+}
+
+/*
+PageStats totals the page counts of the books that have one.
+*/
+type PageStats struct {
+	Total   int     `json:"total"`
+	Average float64 `json:"average"`
+	Books   int     `json:"books"`
+}
+
+/*
+MonthCount is the number of books added in a month, written as "2006-01".
+*/
+type MonthCount struct {
+	Month string `json:"month"`
+	Count int    `json:"count"`
+}
+
+type RatedBook struct {
+	ID      string   `json:"_id" bson:"_id"`
+	Title   string   `json:"title"`
+	Authors []string `json:"authors,omitempty"`
+	Rating  float64  `json:"rating"`
+}
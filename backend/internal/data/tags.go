@@ -0,0 +1,30 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Tag is an entry in the managed tag taxonomy. Books list the names of their tags, so renaming or merging
+a tag rewrites the books that have it. ParentID links a tag to a broader one; top-level tags have none.
+Usage and Children are filled in by the model.
+*/
+type Tag struct {
+	ID        string    `json:"_id" bson:"_id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Name      string    `json:"name"`
+	ParentID  string    `json:"parentId,omitempty"`
+	Version   int32     `json:"version,omitempty"`
+	Usage     int       `json:"usage" bson:"-"`
+	Children  []*Tag    `json:"children,omitempty" bson:"-"`
+}
+
+type TagData struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Name      string             `json:"name"`
+	ParentID  string             `json:"parentId,omitempty"`
+	Version   int32              `json:"version,omitempty"`
+}
@@ -0,0 +1,23 @@
+package data
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+User is someone the reading list keeps books and goals for. Books and goals name their user in Owner;
+names are unique.
+*/
+type User struct {
+	ID        string    `json:"_id" bson:"_id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Name      string    `json:"name"`
+}
+
+type UserData struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Name      string             `json:"name"`
+}
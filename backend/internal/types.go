@@ -4,6 +4,8 @@ import (
 	"log"
 	"readinglistapp/initialisers"
 	"readinglistapp/model"
+	"readinglistapp/session"
+	"readinglistapp/settings"
 	"readinglistapp/view"
 )
 
@@ -14,13 +16,43 @@ type IApp interface {
 	GetView() *view.View
 	GetModel() *model.Model
 	GetDB() *initialisers.DB
-	GetBookCollection() *initialisers.BookCollection
+	GetBookCollection() initialisers.IBookCollection
+	GetAuthorCollection() initialisers.IAuthorCollection
+	GetSeriesCollection() initialisers.ISeriesCollection
+	GetReadingSessionCollection() initialisers.IReadingSessionCollection
+	GetGoalCollection() initialisers.IGoalCollection
+	GetShelfCollection() initialisers.IShelfCollection
+	GetReviewCollection() initialisers.IReviewCollection
+	GetNoteCollection() initialisers.INoteCollection
+	GetHighlightCollection() initialisers.IHighlightCollection
+	GetTagCollection() initialisers.ITagCollection
+	GetUserCollection() initialisers.IUserCollection
+	GetTransactor() initialisers.ITransactor
+	GetDocumentStore() initialisers.IDocumentStore
+	GetBookDependents() model.BookDependents
+	GetSessions() *session.Manager
+	GetConfig() *settings.Config
 }
 
 type App struct {
-	View  *view.View
-	Model *model.Model
-	DB    *initialisers.DB
+	View            *view.View
+	Model           *model.Model
+	DB              *initialisers.DB
+	Books           initialisers.IBookCollection
+	Authors         initialisers.IAuthorCollection
+	Series          initialisers.ISeriesCollection
+	ReadingSessions initialisers.IReadingSessionCollection
+	Goals           initialisers.IGoalCollection
+	Shelves         initialisers.IShelfCollection
+	Reviews         initialisers.IReviewCollection
+	Notes           initialisers.INoteCollection
+	Highlights      initialisers.IHighlightCollection
+	Tags            initialisers.ITagCollection
+	Users           initialisers.IUserCollection
+	Transactor      initialisers.ITransactor
+	Documents       initialisers.IDocumentStore
+	Sessions        *session.Manager
+	Config          *settings.Config
 }
 
 func (a App) GetView() *view.View {
@@ -35,6 +67,14 @@ func (a App) GetDB() *initialisers.DB {
 	return a.DB
 }
 
+func (a App) GetSessions() *session.Manager {
+	return a.Sessions
+}
+
+func (a App) GetConfig() *settings.Config {
+	return a.Config
+}
+
 func (a App) NewView() *view.View {
 	if a.View == nil {
 		a.View = view.NewView()
@@ -54,7 +94,7 @@ func (a App) NewModel() *model.Model {
 func (a App) NewDB() *initialisers.DB {
 	if a.DB == nil {
 		var err error
-		a.DB, err = initialisers.NewDB()
+		a.DB, err = initialisers.NewDB(a.Config.DB)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -62,6 +102,160 @@ func (a App) NewDB() *initialisers.DB {
 	return a.DB
 }
 
-func (a App) GetBookCollection() *initialisers.BookCollection {
+/*
+GetBookCollection returns the book storage shared by every request (decorated with the circuit breaker
+when set up by main), falling back to a plain MongoDB collection.
+*/
+func (a App) GetBookCollection() initialisers.IBookCollection {
+	if a.Books != nil {
+		return a.Books
+	}
+
 	return initialisers.NewBookCollection(a.DB)
 }
+
+/*
+GetAuthorCollection returns the author storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetAuthorCollection() initialisers.IAuthorCollection {
+	if a.Authors != nil {
+		return a.Authors
+	}
+
+	return initialisers.NewAuthorCollection(a.DB)
+}
+
+/*
+GetSeriesCollection returns the series storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetSeriesCollection() initialisers.ISeriesCollection {
+	if a.Series != nil {
+		return a.Series
+	}
+
+	return initialisers.NewSeriesCollection(a.DB)
+}
+
+/*
+GetReadingSessionCollection returns the reading session storage shared by every request, falling back to
+a plain MongoDB collection.
+*/
+func (a App) GetReadingSessionCollection() initialisers.IReadingSessionCollection {
+	if a.ReadingSessions != nil {
+		return a.ReadingSessions
+	}
+
+	return initialisers.NewReadingSessionCollection(a.DB)
+}
+
+/*
+GetGoalCollection returns the reading goal storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetGoalCollection() initialisers.IGoalCollection {
+	if a.Goals != nil {
+		return a.Goals
+	}
+
+	return initialisers.NewGoalCollection(a.DB)
+}
+
+/*
+GetShelfCollection returns the shelf storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetShelfCollection() initialisers.IShelfCollection {
+	if a.Shelves != nil {
+		return a.Shelves
+	}
+
+	return initialisers.NewShelfCollection(a.DB)
+}
+
+/*
+GetReviewCollection returns the review storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetReviewCollection() initialisers.IReviewCollection {
+	if a.Reviews != nil {
+		return a.Reviews
+	}
+
+	return initialisers.NewReviewCollection(a.DB)
+}
+
+/*
+GetNoteCollection returns the note storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetNoteCollection() initialisers.INoteCollection {
+	if a.Notes != nil {
+		return a.Notes
+	}
+
+	return initialisers.NewNoteCollection(a.DB)
+}
+
+/*
+GetHighlightCollection returns the highlight storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetHighlightCollection() initialisers.IHighlightCollection {
+	if a.Highlights != nil {
+		return a.Highlights
+	}
+
+	return initialisers.NewHighlightCollection(a.DB)
+}
+
+/*
+GetTagCollection returns the tag taxonomy storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetTagCollection() initialisers.ITagCollection {
+	if a.Tags != nil {
+		return a.Tags
+	}
+
+	return initialisers.NewTagCollection(a.DB)
+}
+
+/*
+GetUserCollection returns the user storage shared by every request, falling back to a plain MongoDB collection.
+*/
+func (a App) GetUserCollection() initialisers.IUserCollection {
+	if a.Users != nil {
+		return a.Users
+	}
+
+	return initialisers.NewUserCollection(a.DB)
+}
+
+/*
+GetTransactor returns the transaction runner for changes spanning several books, falling back to a plain MongoDB one.
+*/
+func (a App) GetTransactor() initialisers.ITransactor {
+	if a.Transactor != nil {
+		return a.Transactor
+	}
+
+	return initialisers.NewTransactor(a.DB)
+}
+
+/*
+GetDocumentStore returns the raw document access used by backups, falling back to the configured database.
+*/
+func (a App) GetDocumentStore() initialisers.IDocumentStore {
+	if a.Documents != nil {
+		return a.Documents
+	}
+
+	return initialisers.NewDocumentStore(a.DB)
+}
+
+/*
+GetBookDependents returns the collections whose records belong to a book, for purging them with it.
+*/
+func (a App) GetBookDependents() model.BookDependents {
+	return model.BookDependents{
+		Shelves:    a.GetShelfCollection(),
+		Reviews:    a.GetReviewCollection(),
+		Notes:      a.GetNoteCollection(),
+		Highlights: a.GetHighlightCollection(),
+		Sessions:   a.GetReadingSessionCollection(),
+	}
+}
@@ -0,0 +1,187 @@
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid ISBN")
+
+/*
+Clean strips hyphens and spaces from an ISBN and upper-cases a trailing x check digit.
+
+Parameters:
+
+	param1: isbn string
+
+Returns:
+
+	return1: string
+*/
+func Clean(isbn string) string {
+	var b strings.Builder
+
+	for _, r := range isbn {
+		switch {
+		case r == '-' || r == ' ':
+			continue
+		case r == 'x':
+			b.WriteRune('X')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+/*
+Valid10 reports whether the cleaned string is an ISBN-10 with a correct checksum.
+*/
+func Valid10(isbn string) bool {
+	if len(isbn) != 10 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var digit int
+
+		switch c := isbn[i]; {
+		case c >= '0' && c <= '9':
+			digit = int(c - '0')
+		case c == 'X' && i == 9:
+			digit = 10
+		default:
+			return false
+		}
+
+		sum += digit * (10 - i)
+	}
+
+	return sum%11 == 0
+}
+
+/*
+Valid13 reports whether the cleaned string is an ISBN-13 with a correct checksum.
+*/
+func Valid13(isbn string) bool {
+	if len(isbn) != 13 || !allDigits(isbn) {
+		return false
+	}
+
+	if !strings.HasPrefix(isbn, "978") && !strings.HasPrefix(isbn, "979") {
+		return false
+	}
+
+	return check13(isbn[:12]) == isbn[12]
+}
+
+/*
+To13 converts a valid ISBN-10 to its ISBN-13 form.
+
+Parameters:
+
+	param1: isbn10 string
+
+Returns:
+
+	return1: string
+	return2: error
+*/
+func To13(isbn10 string) (string, error) {
+	isbn10 = Clean(isbn10)
+	if !Valid10(isbn10) {
+		return "", ErrInvalid
+	}
+
+	body := "978" + isbn10[:9]
+
+	return body + string(check13(body)), nil
+}
+
+/*
+To10 converts a valid ISBN-13 to its ISBN-10 form.
+Only 978-prefixed ISBN-13s have an ISBN-10 equivalent.
+
+Parameters:
+
+	param1: isbn13 string
+
+Returns:
+
+	return1: string
+	return2: error
+*/
+func To10(isbn13 string) (string, error) {
+	isbn13 = Clean(isbn13)
+	if !Valid13(isbn13) || !strings.HasPrefix(isbn13, "978") {
+		return "", ErrInvalid
+	}
+
+	body := isbn13[3:12]
+
+	return body + string(check10(body)), nil
+}
+
+/*
+Normalize validates an ISBN-10 or ISBN-13, with or without hyphens, and returns it as a bare ISBN-13.
+
+Parameters:
+
+	param1: isbn string
+
+Returns:
+
+	return1: string
+	return2: error
+*/
+func Normalize(isbn string) (string, error) {
+	cleaned := Clean(isbn)
+
+	switch {
+	case Valid13(cleaned):
+		return cleaned, nil
+	case Valid10(cleaned):
+		return To13(cleaned)
+	default:
+		return "", ErrInvalid
+	}
+}
+
+func check13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(body[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func check10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+
+	return byte('0' + check)
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
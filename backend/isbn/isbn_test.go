@@ -0,0 +1,42 @@
+package isbn
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{input: "978-0-306-40615-7", expected: "9780306406157", valid: true},
+		{input: "0-306-40615-2", expected: "9780306406157", valid: true},
+		{input: "0 8044 2957 x", expected: "9780804429573", valid: true},
+		{input: "979-10-90636-07-1", expected: "9791090636071", valid: true},
+		{input: "978-0-306-40615-8", valid: false},
+		{input: "0-306-40615-3", valid: false},
+		{input: "123", valid: false},
+		{input: "", valid: false},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.input)
+
+		if tt.valid && (err != nil || got != tt.expected) {
+			t.Errorf("Normalize(%q) = %q, %v; expected %q", tt.input, got, err, tt.expected)
+		}
+
+		if !tt.valid && err == nil {
+			t.Errorf("Normalize(%q) = %q; expected an error", tt.input, got)
+		}
+	}
+}
+
+func TestTo10(t *testing.T) {
+	if got, err := To10("9780804429573"); err != nil || got != "080442957X" {
+		t.Errorf("To10 = %q, %v; expected 080442957X", got, err)
+	}
+
+	if _, err := To10("9791090636071"); err == nil {
+		t.Errorf("Expected an error converting a 979 ISBN-13")
+	}
+}
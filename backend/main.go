@@ -1,56 +1,21 @@
 package main
 
 import (
-	"fmt"
-	"net/http"
 	"os"
-	"readinglistapp/config"
-	"readinglistapp/initialisers"
-	"readinglistapp/internal"
+	"readinglistapp/cli"
 )
 
-var (
-	app     internal.App
-	DB      *initialisers.DB
-	SiteURL string
-)
-
-/*
-init is called before the main function and initializes the application by loading environment variables
-and connecting to the database.
-If an error occurs during database connection, it panics.
-*/
-func init() {
-	initialisers.LoadEnvVariables()
-	DB = app.NewDB()
-}
-
 /*
-main is the entry point of the application. It sets up the router, initializes the database client,
-and starts the server to listen on the specified port.
+main is the entry point of the application. Without arguments it starts the server; otherwise it runs the
+readinglist command line (`go run . migrate status`, `go run . backup`), the same as cmd/readinglist.
 */
 func main() {
-	port := os.Getenv("PORT")
-
-	app = internal.App{
-		View:  app.NewView(),
-		Model: app.NewModel(),
-		DB:    DB,
+	args := os.Args[1:]
+	if len(args) == 0 {
+		args = []string{"serve"}
 	}
 
-	router := config.SetUpRouter(app)
-
-	defer cleanup(DB.Close)
-
-	fmt.Printf("\nListening on port: %s", port)
-
-	http.ListenAndServe(fmt.Sprintf(":%s", port), router)
-}
-
-/*
-Closes DB connection
-*/
-func cleanup(disconnect func()) {
-	fmt.Println("\nExecuting Clean Up...")
-	defer disconnect()
+	if err := cli.Execute(args); err != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	helper "readinglistapp/helper"
+	"strings"
+)
+
+/*
+AdminToken only lets through requests carrying the admin token as `Authorization: Bearer <token>`.
+Without a configured token the admin endpoints are disabled and answer 404.
+
+Parameters:
+
+	param1: token string, the ADMIN_TOKEN setting
+
+Returns:
+
+	return1: middleware wrapping an http.Handler
+*/
+func AdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				http.NotFound(w, r)
+				return
+			}
+
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				helper.LogHTTPStatusError(w, errors.New("invalid or missing admin token"), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
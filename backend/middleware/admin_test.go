@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		status        int
+	}{
+		{name: "disabled", token: "", authorization: "Bearer ", status: http.StatusNotFound},
+		{name: "missing token", token: "s3cret", authorization: "", status: http.StatusUnauthorized},
+		{name: "wrong token", token: "s3cret", authorization: "Bearer wrong", status: http.StatusUnauthorized},
+		{name: "wrong scheme", token: "s3cret", authorization: "Basic s3cret", status: http.StatusUnauthorized},
+		{name: "valid token", token: "s3cret", authorization: "Bearer s3cret", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/backup", nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+
+		w := httptest.NewRecorder()
+		AdminToken(tt.token)(ok).ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status code %d but got %d", tt.name, tt.status, w.Code)
+		}
+	}
+}
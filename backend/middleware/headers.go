@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	helper "readinglistapp/helper"
+	"strings"
+	"time"
+)
+
+type nonceContextKey string
+
+const cspNonceContextKey = nonceContextKey("cspNonce")
+
+// NoncePlaceholder is replaced with the per-request nonce in SecurityHeadersOptions.ContentSecurityPolicy.
+const NoncePlaceholder = "{nonce}"
+
+/*
+SecurityHeadersOptions controls the headers set by SecurityHeaders.
+Empty values leave the corresponding header unset.
+*/
+type SecurityHeadersOptions struct {
+	ContentSecurityPolicy string
+	CSPReportOnly         bool
+	FrameOptions          string
+	ReferrerPolicy        string
+	PermissionsPolicy     string
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+
+	// TrustedProxies may tell, with X-Forwarded-Proto, that a request reached them over TLS
+	TrustedProxies []netip.Prefix
+}
+
+const defaultCSP = "default-src 'self'; " +
+	"script-src 'self' 'nonce-" + NoncePlaceholder + "'; " +
+	"style-src 'self' https://fonts.googleapis.com; " +
+	"font-src 'self' https://fonts.gstatic.com; " +
+	"img-src 'self' data:; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"form-action 'self'; " +
+	"frame-ancestors 'none'"
+
+/*
+DefaultSecurityHeaders returns the default security header settings for production or any other environment.
+Production enforces the Content-Security-Policy and sends HSTS over TLS, any other environment
+only reports CSP violations and never pins HSTS so local http and self-signed setups keep working.
+
+Parameters:
+
+	param1: production bool
+
+Returns:
+
+	return1: SecurityHeadersOptions
+*/
+func DefaultSecurityHeaders(production bool) SecurityHeadersOptions {
+	opts := SecurityHeadersOptions{
+		ContentSecurityPolicy: defaultCSP,
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		PermissionsPolicy:     "camera=(), microphone=(), geolocation=(), payment=(), usb=()",
+	}
+
+	if production {
+		opts.HSTSMaxAge = 365 * 24 * time.Hour
+		opts.HSTSIncludeSubdomains = true
+	} else {
+		opts.CSPReportOnly = true
+	}
+
+	return opts
+}
+
+/*
+SecurityHeaders sets Content-Security-Policy, X-Content-Type-Options, Referrer-Policy, X-Frame-Options,
+Permissions-Policy and, for requests served over TLS, Strict-Transport-Security on every response.
+A fresh CSP nonce is generated per request and made available to templates through CSPNonce.
+
+Parameters:
+
+	param1: SecurityHeadersOptions
+
+Returns:
+
+	return1: middleware wrapping an http.Handler
+*/
+func SecurityHeaders(opts SecurityHeadersOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nonce, err := newNonce()
+
+			if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+				return
+			}
+
+			h := w.Header()
+
+			if opts.ContentSecurityPolicy != "" {
+				cspHeader := "Content-Security-Policy"
+				if opts.CSPReportOnly {
+					cspHeader = "Content-Security-Policy-Report-Only"
+				}
+				h.Set(cspHeader, strings.ReplaceAll(opts.ContentSecurityPolicy, NoncePlaceholder, nonce))
+			}
+
+			h.Set("X-Content-Type-Options", "nosniff")
+
+			if opts.FrameOptions != "" {
+				h.Set("X-Frame-Options", opts.FrameOptions)
+			}
+
+			if opts.ReferrerPolicy != "" {
+				h.Set("Referrer-Policy", opts.ReferrerPolicy)
+			}
+
+			if opts.PermissionsPolicy != "" {
+				h.Set("Permissions-Policy", opts.PermissionsPolicy)
+			}
+
+			if opts.HSTSMaxAge > 0 && isTLS(r, opts.TrustedProxies) {
+				hsts := fmt.Sprintf("max-age=%d", int(opts.HSTSMaxAge.Seconds()))
+				if opts.HSTSIncludeSubdomains {
+					hsts += "; includeSubDomains"
+				}
+				h.Set("Strict-Transport-Security", hsts)
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), cspNonceContextKey, nonce)))
+		})
+	}
+}
+
+/*
+CSPNonce returns the Content-Security-Policy nonce generated for the request, or an empty string
+when the request didn't pass through SecurityHeaders.
+
+Parameters:
+
+	param1: ctx context.Context
+
+Returns:
+
+	return1: string
+*/
+func CSPNonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(cspNonceContextKey).(string)
+	return nonce
+}
+
+/*
+isTLS reports whether the request reached us over TLS, either directly or via a trusted TLS terminating proxy.
+X-Forwarded-Proto from any other client is ignored, since anyone can send it.
+*/
+func isTLS(r *http.Request, proxies []netip.Prefix) bool {
+	if r.TLS != nil {
+		return true
+	}
+
+	if !strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if proxy.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
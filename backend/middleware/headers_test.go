@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	var nonce string
+
+	handler := SecurityHeaders(DefaultSecurityHeaders(true))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = CSPNonce(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if nonce == "" {
+		t.Fatalf("Expected a CSP nonce in the request context")
+	}
+
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "'nonce-"+nonce+"'") {
+		t.Errorf("Expected CSP to contain the request nonce but got '%s'", csp)
+	}
+
+	if got := w.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("Expected nosniff but got '%s'", got)
+	}
+
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Expected no HSTS over plain HTTP but got '%s'", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Strict-Transport-Security"); !strings.HasPrefix(got, "max-age=") {
+		t.Errorf("Expected HSTS over TLS but got '%s'", got)
+	}
+}
+
+func TestSecurityHeadersDevReportsOnly(t *testing.T) {
+	handler := SecurityHeaders(DefaultSecurityHeaders(false))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Security-Policy") != "" || w.Header().Get("Content-Security-Policy-Report-Only") == "" {
+		t.Errorf("Expected a report-only CSP in dev")
+	}
+
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Expected no HSTS in dev but got '%s'", got)
+	}
+}
+
+func TestSecurityHeadersTrustsForwardedProtoFromProxiesOnly(t *testing.T) {
+	opts := DefaultSecurityHeaders(true)
+	opts.TrustedProxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	handler := SecurityHeaders(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for remote, expected := range map[string]bool{"10.1.2.3:4567": true, "203.0.113.9:4567": false} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remote
+		req.Header.Set("X-Forwarded-Proto", "https")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Strict-Transport-Security") != ""; got != expected {
+			t.Errorf("%s: got HSTS %t, expected %t", remote, got, expected)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	helper "readinglistapp/helper"
+	"readinglistapp/session"
+	"strings"
+)
+
+/*
+Sessions loads the session for every server-rendered page, refreshes its cookie
+and stores it in the request context.
+The JSON API under /v1/ and static assets are stateless and skipped.
+
+Parameters:
+
+	param1: pointer session.Manager
+
+Returns:
+
+	return1: middleware wrapping an http.Handler
+*/
+func Sessions(manager *session.Manager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isHTMLRoute(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			s, err := manager.Load(r)
+
+			if helper.IsHTTPStatusError(w, err, http.StatusInternalServerError) {
+				return
+			}
+
+			manager.WriteCookie(w, s)
+
+			next.ServeHTTP(w, r.WithContext(session.NewContext(r.Context(), s)))
+		})
+	}
+}
+
+/*
+CSRF rejects state-changing requests to server-rendered pages whose csrf_token form field
+(or X-CSRF-Token header) doesn't match the session's CSRF token.
+It must run after Sessions.
+
+Parameters:
+
+	param1: next http.Handler
+
+Returns:
+
+	return1: http.Handler
+*/
+func CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isHTMLRoute(r) || isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		s := session.FromContext(r.Context())
+
+		token := r.Header.Get(session.CSRFHeader)
+		if token == "" {
+			token = r.PostFormValue(session.CSRFFormField)
+		}
+
+		if s == nil || !s.ValidCSRFToken(token) {
+			helper.LogHTTPStatusError(w, errors.New("invalid or missing CSRF token"), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+/*
+isHTMLRoute reports whether the request targets a server-rendered page rather than the JSON API or static files.
+*/
+func isHTMLRoute(r *http.Request) bool {
+	return !strings.HasPrefix(r.URL.Path, "/v1/") && !strings.HasPrefix(r.URL.Path, "/static/")
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"readinglistapp/session"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	manager, err := session.NewManager([]byte("unit-test-secret"), 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s *session.Session
+
+	handler := Sessions(manager)(CSRF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s = session.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/book/create", nil))
+
+	if w.Code != http.StatusOK || s == nil {
+		t.Fatalf("Expected GET to pass with a session, got status %d", w.Code)
+	}
+
+	cookie := w.Result().Cookies()[0]
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "missing token", token: "", status: http.StatusForbidden},
+		{name: "wrong token", token: "wrong", status: http.StatusForbidden},
+		{name: "valid token", token: s.CSRFToken, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		form := url.Values{session.CSRFFormField: {tt.token}}
+		req := httptest.NewRequest(http.MethodPost, "/book/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status code %d but got %d", tt.name, tt.status, w.Code)
+		}
+	}
+}
+
+func TestCSRFSkipsAPI(t *testing.T) {
+	handler := CSRF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/books", strings.NewReader("{}")))
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d but got %d", http.StatusCreated, w.Code)
+	}
+}
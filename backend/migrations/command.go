@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+/*
+RunCommand executes the `migrate` command line: `up [n]`, `down [n]` or `status`.
+Without arguments it applies every pending migration.
+
+Parameters:
+
+	param1: ctx context.Context
+	param2: pointer Migrator
+	param3: args []string, the arguments following `migrate`
+	param4: out io.Writer, where progress is reported
+
+Returns:
+
+	return1: error
+*/
+func RunCommand(ctx context.Context, migrator *Migrator, args []string, out io.Writer) error {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number of steps '%s'", args[1])
+		}
+		steps = n
+	}
+
+	switch action {
+	case "up":
+		done, err := migrator.Up(ctx, steps)
+		report(out, "applied", done)
+		return err
+	case "down":
+		done, err := migrator.Down(ctx, steps)
+		report(out, "rolled back", done)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%4d  %-28s %s\n", status.Version, state, status.Description)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate action '%s', expected up, down or status", action)
+	}
+}
+
+func report(out io.Writer, verb string, migrations []Migration) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(out, "No migrations %s.\n", verb)
+		return
+	}
+
+	for _, migration := range migrations {
+		fmt.Fprintf(out, "%s %d: %s\n", verb, migration.Version, migration.Description)
+	}
+}
@@ -0,0 +1,255 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionName is the collection recording which migrations have been applied.
+const CollectionName = "schema_migrations"
+
+/*
+Migration is a single reversible schema change.
+Versions must be unique, they are applied in ascending order and rolled back in descending order.
+*/
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+/*
+AppliedMigration is the document stored in schema_migrations for every applied migration.
+*/
+type AppliedMigration struct {
+	Version     int       `json:"version" bson:"_id"`
+	Description string    `json:"description" bson:"description"`
+	AppliedAt   time.Time `json:"appliedAt" bson:"appliedAt"`
+}
+
+/*
+MigrationStatus reports whether a known migration has been applied.
+*/
+type MigrationStatus struct {
+	Version     int        `json:"version"`
+	Description string     `json:"description"`
+	AppliedAt   *time.Time `json:"appliedAt,omitempty"`
+}
+
+/*
+Migrator applies and rolls back migrations against a database.
+*/
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+}
+
+/*
+NewMigrator creates a Migrator for the given migrations, sorted by version.
+It returns an error if two migrations share a version or a migration is missing its Up or Down step.
+
+Parameters:
+
+	param1: pointer mongo.Database
+	param2: []Migration
+
+Returns:
+
+	return1: pointer Migrator
+	return2: error
+*/
+func NewMigrator(db *mongo.Database, migrations []Migration) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i, m := range sorted {
+		if m.Up == nil || m.Down == nil {
+			return nil, fmt.Errorf("migration %d is missing its up or down step", m.Version)
+		}
+
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+	}
+
+	return &Migrator{db: db, migrations: sorted}, nil
+}
+
+/*
+Up applies pending migrations in ascending order, recording each one once it succeeded.
+steps limits how many are applied, 0 applies all of them.
+
+Parameters:
+
+	param1: ctx context.Context
+	param2: steps int
+
+Returns:
+
+	return1: []Migration, the migrations applied
+	return2: error
+*/
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	for _, migration := range pending(m.migrations, applied, steps) {
+		if err := migration.Up(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s) up: %w", migration.Version, migration.Description, err)
+		}
+
+		record := AppliedMigration{Version: migration.Version, Description: migration.Description, AppliedAt: time.Now()}
+
+		if _, err := m.db.Collection(CollectionName).InsertOne(ctx, record); err != nil {
+			return done, err
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+/*
+Down rolls back the most recently applied migrations in descending order.
+steps is how many to roll back, defaulting to 1.
+
+Parameters:
+
+	param1: ctx context.Context
+	param2: steps int
+
+Returns:
+
+	return1: []Migration, the migrations rolled back
+	return2: error
+*/
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	for _, migration := range rollback(m.migrations, applied, steps) {
+		if err := migration.Down(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s) down: %w", migration.Version, migration.Description, err)
+		}
+
+		if _, err := m.db.Collection(CollectionName).DeleteOne(ctx, bson.D{{Key: "_id", Value: migration.Version}}); err != nil {
+			return done, err
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+/*
+Status lists every known migration and when it was applied.
+
+Parameters:
+
+	param1: ctx context.Context
+
+Returns:
+
+	return1: []MigrationStatus
+	return2: error
+*/
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Description: migration.Description}
+
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+/*
+applied loads the schema_migrations records keyed by version.
+*/
+func (m *Migrator) applied(ctx context.Context) (map[int]AppliedMigration, error) {
+	cur, err := m.db.Collection(CollectionName).Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []AppliedMigration
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]AppliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return applied, nil
+}
+
+/*
+pending returns the migrations not yet applied, in ascending order, limited to steps when positive.
+*/
+func pending(migrations []Migration, applied map[int]AppliedMigration, steps int) []Migration {
+	var result []Migration
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if steps > 0 && len(result) == steps {
+			break
+		}
+
+		result = append(result, migration)
+	}
+
+	return result
+}
+
+/*
+rollback returns the last steps applied migrations, most recent first.
+*/
+func rollback(migrations []Migration, applied map[int]AppliedMigration, steps int) []Migration {
+	var result []Migration
+
+	for i := len(migrations) - 1; i >= 0 && len(result) < steps; i-- {
+		if _, ok := applied[migrations[i].Version]; ok {
+			result = append(result, migrations[i])
+		}
+	}
+
+	return result
+}
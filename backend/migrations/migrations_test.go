@@ -0,0 +1,157 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func noop(ctx context.Context, db *mongo.Database) error { return nil }
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 3, Description: "third", Up: noop, Down: noop},
+		{Version: 1, Description: "first", Up: noop, Down: noop},
+		{Version: 2, Description: "second", Up: noop, Down: noop},
+	}
+}
+
+func versions(migrations []Migration) []int {
+	var result []int
+	for _, m := range migrations {
+		result = append(result, m.Version)
+	}
+	return result
+}
+
+func TestNewMigratorSortsAndValidates(t *testing.T) {
+	migrator, err := NewMigrator(nil, testMigrations())
+
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if got := versions(migrator.migrations); got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("Expected migrations sorted by version but got %v", got)
+	}
+
+	if _, err := NewMigrator(nil, append(testMigrations(), Migration{Version: 2, Up: noop, Down: noop})); err == nil {
+		t.Errorf("Expected an error for a duplicate version")
+	}
+
+	if _, err := NewMigrator(nil, []Migration{{Version: 1, Up: noop}}); err == nil {
+		t.Errorf("Expected an error for a missing down step")
+	}
+}
+
+func TestPendingAndRollback(t *testing.T) {
+	migrator, _ := NewMigrator(nil, testMigrations())
+	applied := map[int]AppliedMigration{1: {Version: 1}}
+
+	if got := versions(pending(migrator.migrations, applied, 0)); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("Expected pending [2 3] but got %v", got)
+	}
+
+	if got := versions(pending(migrator.migrations, applied, 1)); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected pending [2] but got %v", got)
+	}
+
+	applied[2] = AppliedMigration{Version: 2}
+
+	if got := versions(rollback(migrator.migrations, applied, 5)); len(got) != 2 || got[0] != 2 || got[1] != 1 {
+		t.Errorf("Expected rollback [2 1] but got %v", got)
+	}
+}
+
+func TestRegistryIsValid(t *testing.T) {
+	if _, err := NewMigrator(nil, All()); err != nil {
+		t.Errorf("got error %v, expected nil", err)
+	}
+}
+
+/*
+testDatabase connects to the MongoDB server at TEST_DB_URL and returns a database dropped when the test ends.
+The test is skipped when TEST_DB_URL isn't set.
+*/
+func testDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(url))
+	if err != nil {
+		t.Fatalf("connecting to %s: %v", url, err)
+	}
+
+	db := client.Database(fmt.Sprintf("readinglist_test_%d", time.Now().UnixNano()))
+
+	t.Cleanup(func() {
+		_ = db.Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+
+	return db
+}
+
+func TestLiveISBNIndex(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+
+	migrator, _ := NewMigrator(db, All())
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatalf("got error %v, expected every migration applied", err)
+	}
+
+	books := db.Collection("books")
+	isbn13 := "9780141439587"
+
+	if _, err := books.InsertOne(ctx, bson.D{{Key: "title", Value: "Emma"}, {Key: "owner", Value: ""}, {Key: "isbn13", Value: isbn13}}); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if _, err := books.InsertOne(ctx, bson.D{{Key: "title", Value: "Emma again"}, {Key: "owner", Value: ""}, {Key: "isbn13", Value: isbn13}}); !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("got error %v, expected two books not in the trash to be refused the same ISBN", err)
+	}
+
+	trash := bson.D{{Key: "$set", Value: bson.D{{Key: "deletedat", Value: time.Now()}}}}
+	if _, err := books.UpdateOne(ctx, bson.D{{Key: "title", Value: "Emma"}}, trash); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if _, err := books.InsertOne(ctx, bson.D{{Key: "title", Value: "Emma again"}, {Key: "owner", Value: ""}, {Key: "isbn13", Value: isbn13}}); err != nil {
+		t.Errorf("got error %v, expected the ISBN of a book in the trash to be free", err)
+	}
+
+	restore := bson.D{{Key: "$unset", Value: bson.D{{Key: "deletedat", Value: ""}}}}
+	if _, err := books.UpdateOne(ctx, bson.D{{Key: "title", Value: "Emma"}}, restore); !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("got error %v, expected restoring a book whose ISBN is taken to be refused", err)
+	}
+
+	for _, title := range []string{"Untitled", "Untitled too"} {
+		if _, err := books.InsertOne(ctx, bson.D{{Key: "title", Value: title}, {Key: "owner", Value: ""}, {Key: "isbn13", Value: ""}}); err != nil {
+			t.Errorf("got error %v, expected any number of books without an ISBN", err)
+		}
+	}
+
+	for _, owner := range []string{"ana", "ben"} {
+		if _, err := books.InsertOne(ctx, bson.D{{Key: "title", Value: "Emma"}, {Key: "owner", Value: owner}, {Key: "isbn13", Value: isbn13}}); err != nil {
+			t.Errorf("got error %v, expected each owner to have a book with the ISBN", err)
+		}
+	}
+
+	if _, err := books.InsertOne(ctx, bson.D{{Key: "title", Value: "Emma again"}, {Key: "owner", Value: "ana"}, {Key: "isbn13", Value: isbn13}}); !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("got error %v, expected two books of an owner to be refused the same ISBN", err)
+	}
+}
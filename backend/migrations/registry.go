@@ -0,0 +1,812 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"readinglistapp/isbn"
+	"readinglistapp/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+All returns every migration known to the application. New migrations are appended here
+with the next version number; applied migrations must never be edited.
+*/
+func All() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create books indexes on title, genres, rating and createdAt",
+			Up:          createBookIndexes,
+			Down:        dropBookIndexes,
+		},
+		{
+			Version:     2,
+			Description: "add authors, ISBN, publisher, language, description, edition and series to books",
+			Up:          addBookDetails,
+			Down:        removeBookDetails,
+		},
+		{
+			Version:     3,
+			Description: "normalise book ISBNs and make isbn13 unique",
+			Up:          normaliseBookISBNs,
+			Down:        dropISBNIndex,
+		},
+		{
+			Version:     4,
+			Description: "create authors from book author names and link books to them",
+			Up:          createAuthors,
+			Down:        removeAuthors,
+		},
+		{
+			Version:     5,
+			Description: "create series from book series names and link books to them",
+			Up:          createSeries,
+			Down:        removeSeries,
+		},
+		{
+			Version:     6,
+			Description: "add reading status to books",
+			Up:          addReadingStatus,
+			Down:        removeReadingStatus,
+		},
+		{
+			Version:     7,
+			Description: "create reading_sessions indexes on bookId and startedAt",
+			Up:          createReadingSessionIndexes,
+			Down:        dropReadingSessions,
+		},
+		{
+			Version:     8,
+			Description: "make goals unique per owner, metric and period",
+			Up:          createGoalIndex,
+			Down:        dropGoals,
+		},
+		{
+			Version:     9,
+			Description: "create shelves indexes on name and bookIds",
+			Up:          createShelfIndexes,
+			Down:        dropShelves,
+		},
+		{
+			Version:     10,
+			Description: "create reviews and notes indexes, with a text index on note bodies",
+			Up:          createReviewAndNoteIndexes,
+			Down:        dropReviewsAndNotes,
+		},
+		{
+			Version:     11,
+			Description: "create highlights indexes on bookId, createdAt and tags",
+			Up:          createHighlightIndexes,
+			Down:        dropHighlights,
+		},
+		{
+			Version:     12,
+			Description: "create tags unique name index and books tags index",
+			Up:          createTagIndexes,
+			Down:        dropTags,
+		},
+		{
+			Version:     13,
+			Description: "create books deletedAt index for the trash",
+			Up:          createTrashIndex,
+			Down:        dropTrashIndex,
+		},
+		{
+			Version:     14,
+			Description: "make isbn13 unique among the books that are not in the trash",
+			Up:          createLiveISBNIndex,
+			Down:        dropLiveISBNIndex,
+		},
+		{
+			Version:     15,
+			Description: "add owner to books and index it",
+			Up:          addBookOwner,
+			Down:        removeBookOwner,
+		},
+		{
+			Version:     16,
+			Description: "make isbn13 unique per owner among the books that are not in the trash",
+			Up:          createOwnerISBNIndex,
+			Down:        dropOwnerISBNIndex,
+		},
+		{
+			Version:     17,
+			Description: "create users unique name index",
+			Up:          createUserIndex,
+			Down:        dropUsers,
+		},
+	}
+}
+
+var bookIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "title", Value: 1}}, Options: options.Index().SetName("books_title")},
+	{Keys: bson.D{{Key: "genres", Value: 1}}, Options: options.Index().SetName("books_genres")},
+	{Keys: bson.D{{Key: "rating", Value: -1}}, Options: options.Index().SetName("books_rating")},
+	// BookData.CreatedAt has no bson tag, so the driver stores it lowercased.
+	{Keys: bson.D{{Key: "createdat", Value: -1}}, Options: options.Index().SetName("books_createdAt")},
+}
+
+func createBookIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("books").Indexes().CreateMany(ctx, bookIndexes)
+	return err
+}
+
+func dropBookIndexes(ctx context.Context, db *mongo.Database) error {
+	return dropIndexes(ctx, db.Collection("books"), bookIndexes)
+}
+
+/*
+dropIndexes drops the named indexes, ignoring ones that no longer exist.
+*/
+func dropIndexes(ctx context.Context, collection *mongo.Collection, indexes []mongo.IndexModel) error {
+	for _, index := range indexes {
+		if _, err := collection.Indexes().DropOne(ctx, *index.Options.Name); err != nil && !isIndexNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 27 || cmdErr.Name == "IndexNotFound")
+}
+
+// bookDetailFields are the fields added by migration 2 with the value existing documents receive.
+var bookDetailFields = bson.D{
+	{Key: "authors", Value: bson.A{}},
+	{Key: "isbn10", Value: ""},
+	{Key: "isbn13", Value: ""},
+	{Key: "publisher", Value: ""},
+	{Key: "language", Value: ""},
+	{Key: "description", Value: ""},
+	{Key: "edition", Value: ""},
+	{Key: "series", Value: ""},
+}
+
+var authorsIndex = mongo.IndexModel{Keys: bson.D{{Key: "authors", Value: 1}}, Options: options.Index().SetName("books_authors")}
+
+func addBookDetails(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	for _, field := range bookDetailFields {
+		filter := bson.D{{Key: field.Key, Value: bson.D{{Key: "$exists", Value: false}}}}
+		update := bson.D{{Key: "$set", Value: bson.D{field}}}
+
+		if _, err := books.UpdateMany(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+
+	_, err := books.Indexes().CreateOne(ctx, authorsIndex)
+	return err
+}
+
+func removeBookDetails(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{authorsIndex}); err != nil {
+		return err
+	}
+
+	unset := bson.D{}
+	for _, field := range bookDetailFields {
+		unset = append(unset, bson.E{Key: field.Key, Value: ""})
+	}
+
+	_, err := books.UpdateMany(ctx, bson.D{}, bson.D{{Key: "$unset", Value: unset}})
+	return err
+}
+
+// isbnIndex only covers books with an ISBN, so any number of books may leave it empty.
+var isbnIndex = mongo.IndexModel{
+	Keys: bson.D{{Key: "isbn13", Value: 1}},
+	Options: options.Index().
+		SetName("books_isbn13").
+		SetUnique(true).
+		SetPartialFilterExpression(bson.D{{Key: "isbn13", Value: bson.D{{Key: "$gt", Value: ""}}}}),
+}
+
+/*
+normaliseBookISBNs rewrites existing ISBNs in the form the model stores them: a bare ISBN-13
+and the matching ISBN-10 when there is one. Invalid ISBNs are reported rather than dropped,
+so they can be fixed by hand before the migration is run again.
+*/
+func normaliseBookISBNs(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "isbn10", Value: bson.D{{Key: "$gt", Value: ""}}}},
+		bson.D{{Key: "isbn13", Value: bson.D{{Key: "$gt", Value: ""}}}},
+	}}}
+
+	cur, err := books.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	var invalid []string
+
+	for cur.Next(ctx) {
+		var book struct {
+			ID     interface{} `bson:"_id"`
+			ISBN10 string      `bson:"isbn10"`
+			ISBN13 string      `bson:"isbn13"`
+		}
+
+		if err := cur.Decode(&book); err != nil {
+			return err
+		}
+
+		value := book.ISBN13
+		if value == "" {
+			value = book.ISBN10
+		}
+
+		isbn13, err := isbn.Normalize(value)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%v (%s)", book.ID, value))
+			continue
+		}
+
+		isbn10, _ := isbn.To10(isbn13)
+
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: "isbn13", Value: isbn13}, {Key: "isbn10", Value: isbn10}}}}
+		if _, err := books.UpdateOne(ctx, bson.D{{Key: "_id", Value: book.ID}}, update); err != nil {
+			return err
+		}
+	}
+
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("books with invalid ISBNs: %v", invalid)
+	}
+
+	_, err = books.Indexes().CreateOne(ctx, isbnIndex)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("books share an ISBN, remove the duplicates first: %w", err)
+	}
+
+	return err
+}
+
+func dropISBNIndex(ctx context.Context, db *mongo.Database) error {
+	return dropIndexes(ctx, db.Collection("books"), []mongo.IndexModel{isbnIndex})
+}
+
+var authorIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "sortname", Value: 1}}, Options: options.Index().SetName("authors_sortName")},
+	{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetName("authors_name")},
+	{Keys: bson.D{{Key: "aliases", Value: 1}}, Options: options.Index().SetName("authors_aliases")},
+}
+
+var authorIDsIndex = mongo.IndexModel{Keys: bson.D{{Key: "authorids", Value: 1}}, Options: options.Index().SetName("books_authorIds")}
+
+/*
+createAuthors creates an author for every distinct name in books.authors and links the books to them.
+Authors that already exist by name are reused, so the migration can be re-run after a partial failure.
+*/
+func createAuthors(ctx context.Context, db *mongo.Database) error {
+	authors := db.Collection("authors")
+	books := db.Collection("books")
+
+	if _, err := authors.Indexes().CreateMany(ctx, authorIndexes); err != nil {
+		return err
+	}
+
+	if _, err := books.Indexes().CreateOne(ctx, authorIDsIndex); err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		{Key: "authors.0", Value: bson.D{{Key: "$exists", Value: true}}},
+		{Key: "authorids.0", Value: bson.D{{Key: "$exists", Value: false}}},
+	}
+
+	cur, err := books.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	ids := make(map[string]string)
+
+	for cur.Next(ctx) {
+		var book struct {
+			ID      interface{} `bson:"_id"`
+			Authors []string    `bson:"authors"`
+		}
+
+		if err := cur.Decode(&book); err != nil {
+			return err
+		}
+
+		authorIDs := make([]string, 0, len(book.Authors))
+
+		for _, name := range book.Authors {
+			id, ok := ids[name]
+			if !ok {
+				if id, err = findOrCreateAuthor(ctx, authors, name); err != nil {
+					return err
+				}
+				ids[name] = id
+			}
+
+			authorIDs = append(authorIDs, id)
+		}
+
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: "authorids", Value: authorIDs}}}}
+		if _, err := books.UpdateOne(ctx, bson.D{{Key: "_id", Value: book.ID}}, update); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+func findOrCreateAuthor(ctx context.Context, authors *mongo.Collection, name string) (string, error) {
+	var existing struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+
+	err := authors.FindOne(ctx, bson.D{{Key: "name", Value: name}}).Decode(&existing)
+	if err == nil {
+		return existing.ID.Hex(), nil
+	}
+
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return "", err
+	}
+
+	id := primitive.NewObjectID()
+	author := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "createdat", Value: time.Now()},
+		{Key: "name", Value: name},
+		{Key: "sortname", Value: model.SortName(name)},
+		{Key: "version", Value: 1},
+	}
+
+	if _, err := authors.InsertOne(ctx, author); err != nil {
+		return "", err
+	}
+
+	return id.Hex(), nil
+}
+
+func removeAuthors(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{authorIDsIndex}); err != nil {
+		return err
+	}
+
+	if _, err := books.UpdateMany(ctx, bson.D{}, bson.D{{Key: "$unset", Value: bson.D{{Key: "authorids", Value: ""}}}}); err != nil {
+		return err
+	}
+
+	return db.Collection("authors").Drop(ctx)
+}
+
+var seriesNameIndex = mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetName("series_name")}
+
+var bookSeriesIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "seriesid", Value: 1}, {Key: "seriesposition", Value: 1}},
+	Options: options.Index().SetName("books_seriesId_position"),
+}
+
+/*
+createSeries creates a series for every distinct name in books.series and links the books to it.
+Their position is unknown, so they are linked without one until it is set through /v1/series.
+*/
+func createSeries(ctx context.Context, db *mongo.Database) error {
+	series := db.Collection("series")
+	books := db.Collection("books")
+
+	if _, err := series.Indexes().CreateOne(ctx, seriesNameIndex); err != nil {
+		return err
+	}
+
+	if _, err := books.Indexes().CreateOne(ctx, bookSeriesIndex); err != nil {
+		return err
+	}
+
+	names, err := books.Distinct(ctx, "series", bson.D{
+		{Key: "series", Value: bson.D{{Key: "$gt", Value: ""}}},
+		{Key: "seriesid", Value: bson.D{{Key: "$exists", Value: false}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, value := range names {
+		name, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var existing struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+
+		id := primitive.NewObjectID()
+
+		err := series.FindOne(ctx, bson.D{{Key: "name", Value: name}}).Decode(&existing)
+		switch {
+		case err == nil:
+			id = existing.ID
+		case errors.Is(err, mongo.ErrNoDocuments):
+			record := bson.D{
+				{Key: "_id", Value: id},
+				{Key: "createdat", Value: time.Now()},
+				{Key: "name", Value: name},
+				{Key: "version", Value: 1},
+			}
+
+			if _, err := series.InsertOne(ctx, record); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		filter := bson.D{{Key: "series", Value: name}, {Key: "seriesid", Value: bson.D{{Key: "$exists", Value: false}}}}
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: "seriesid", Value: id.Hex()}, {Key: "seriesposition", Value: 0}}}}
+
+		if _, err := books.UpdateMany(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeSeries(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{bookSeriesIndex}); err != nil {
+		return err
+	}
+
+	unset := bson.D{{Key: "seriesid", Value: ""}, {Key: "seriesposition", Value: ""}}
+	if _, err := books.UpdateMany(ctx, bson.D{}, bson.D{{Key: "$unset", Value: unset}}); err != nil {
+		return err
+	}
+
+	return db.Collection("series").Drop(ctx)
+}
+
+var statusIndex = mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}}, Options: options.Index().SetName("books_status")}
+
+/*
+addReadingStatus gives every book a reading status: finished when it already has a finishedAt date,
+want to read otherwise.
+*/
+func addReadingStatus(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	missing := bson.D{{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{nil, ""}}}}}
+
+	finished := append(bson.D{{Key: "finishedat", Value: bson.D{{Key: "$type", Value: "date"}}}}, missing...)
+	setFinished := mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "status", Value: "finished"}, {Key: "currentpage", Value: "$pages"}}}}}
+	if _, err := books.UpdateMany(ctx, finished, setFinished); err != nil {
+		return err
+	}
+
+	if _, err := books.UpdateMany(ctx, missing, bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "want_to_read"}, {Key: "currentpage", Value: 0}}}}); err != nil {
+		return err
+	}
+
+	_, err := books.Indexes().CreateOne(ctx, statusIndex)
+	return err
+}
+
+func removeReadingStatus(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{statusIndex}); err != nil {
+		return err
+	}
+
+	unset := bson.D{
+		{Key: "status", Value: ""},
+		{Key: "currentpage", Value: ""},
+		{Key: "startedat", Value: ""},
+		{Key: "abandonedat", Value: ""},
+	}
+
+	_, err := books.UpdateMany(ctx, bson.D{}, bson.D{{Key: "$unset", Value: unset}})
+	return err
+}
+
+var readingSessionIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "bookid", Value: 1}, {Key: "startedat", Value: -1}}, Options: options.Index().SetName("readingSessions_bookId_startedAt")},
+	{Keys: bson.D{{Key: "startedat", Value: 1}}, Options: options.Index().SetName("readingSessions_startedAt")},
+}
+
+func createReadingSessionIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("reading_sessions").Indexes().CreateMany(ctx, readingSessionIndexes)
+	return err
+}
+
+func dropReadingSessions(ctx context.Context, db *mongo.Database) error {
+	return db.Collection("reading_sessions").Drop(ctx)
+}
+
+var goalIndex = mongo.IndexModel{
+	Keys: bson.D{{Key: "owner", Value: 1}, {Key: "metric", Value: 1}, {Key: "year", Value: 1}, {Key: "month", Value: 1}},
+	Options: options.Index().
+		SetName("goals_owner_metric_period").
+		SetUnique(true),
+}
+
+func createGoalIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("goals").Indexes().CreateOne(ctx, goalIndex)
+	return err
+}
+
+func dropGoals(ctx context.Context, db *mongo.Database) error {
+	return db.Collection("goals").Drop(ctx)
+}
+
+var shelfIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetName("shelves_name").SetUnique(true)},
+	{Keys: bson.D{{Key: "bookids", Value: 1}}, Options: options.Index().SetName("shelves_bookIds")},
+}
+
+func createShelfIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("shelves").Indexes().CreateMany(ctx, shelfIndexes)
+	return err
+}
+
+func dropShelves(ctx context.Context, db *mongo.Database) error {
+	return db.Collection("shelves").Drop(ctx)
+}
+
+var reviewIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "bookid", Value: 1}, {Key: "createdat", Value: -1}},
+	Options: options.Index().SetName("reviews_bookId_createdAt"),
+}
+
+var noteIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "bookid", Value: 1}, {Key: "createdat", Value: -1}}, Options: options.Index().SetName("notes_bookId_createdAt")},
+	{Keys: bson.D{{Key: "body", Value: "text"}}, Options: options.Index().SetName("notes_body")},
+}
+
+func createReviewAndNoteIndexes(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection("reviews").Indexes().CreateOne(ctx, reviewIndex); err != nil {
+		return err
+	}
+
+	_, err := db.Collection("notes").Indexes().CreateMany(ctx, noteIndexes)
+	return err
+}
+
+func dropReviewsAndNotes(ctx context.Context, db *mongo.Database) error {
+	if err := db.Collection("reviews").Drop(ctx); err != nil {
+		return err
+	}
+
+	return db.Collection("notes").Drop(ctx)
+}
+
+var highlightIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "bookid", Value: 1}, {Key: "page", Value: 1}}, Options: options.Index().SetName("highlights_bookId_page")},
+	{Keys: bson.D{{Key: "createdat", Value: -1}}, Options: options.Index().SetName("highlights_createdAt")},
+	{Keys: bson.D{{Key: "tags", Value: 1}}, Options: options.Index().SetName("highlights_tags")},
+}
+
+func createHighlightIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("highlights").Indexes().CreateMany(ctx, highlightIndexes)
+	return err
+}
+
+func dropHighlights(ctx context.Context, db *mongo.Database) error {
+	return db.Collection("highlights").Drop(ctx)
+}
+
+var tagIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetName("tags_name").SetUnique(true)},
+	{Keys: bson.D{{Key: "parentid", Value: 1}}, Options: options.Index().SetName("tags_parentId")},
+}
+
+var bookTagsIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "tags", Value: 1}},
+	Options: options.Index().SetName("books_tags"),
+}
+
+func createTagIndexes(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection("tags").Indexes().CreateMany(ctx, tagIndexes); err != nil {
+		return err
+	}
+
+	_, err := db.Collection("books").Indexes().CreateOne(ctx, bookTagsIndex)
+	return err
+}
+
+func dropTags(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection("books").Indexes().DropOne(ctx, "books_tags"); err != nil {
+		return err
+	}
+
+	return db.Collection("tags").Drop(ctx)
+}
+
+// BookData.DeletedAt is stored lowercased too; the index serves the trash listing and the automatic purge.
+var bookTrashIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "deletedat", Value: -1}},
+	Options: options.Index().SetName("books_deletedAt"),
+}
+
+func createTrashIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("books").Indexes().CreateOne(ctx, bookTrashIndex)
+	return err
+}
+
+func dropTrashIndex(ctx context.Context, db *mongo.Database) error {
+	return dropIndexes(ctx, db.Collection("books"), []mongo.IndexModel{bookTrashIndex})
+}
+
+/*
+liveISBNIndex replaces isbnIndex: it makes isbn13 unique together with deletedat, so a book can be added with the
+ISBN of one in the trash. Books that are not in the trash have no deletedat, which the index holds as null, so two
+of them can't share an ISBN; books in the trash each have the time they were deleted. Partial indexes can't
+select documents on a missing field, which is why deletedat is part of the key rather than of the filter.
+*/
+var liveISBNIndex = mongo.IndexModel{
+	Keys: bson.D{{Key: "isbn13", Value: 1}, {Key: "deletedat", Value: 1}},
+	Options: options.Index().
+		SetName("books_isbn13_deletedAt").
+		SetUnique(true).
+		SetPartialFilterExpression(bson.D{{Key: "isbn13", Value: bson.D{{Key: "$gt", Value: ""}}}}),
+}
+
+/*
+createLiveISBNIndex swaps isbnIndex for liveISBNIndex. When the server can't create it, isbnIndex is put back.
+*/
+func createLiveISBNIndex(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{isbnIndex}); err != nil {
+		return err
+	}
+
+	if _, err := books.Indexes().CreateOne(ctx, liveISBNIndex); err != nil {
+		if _, restoreErr := books.Indexes().CreateOne(ctx, isbnIndex); restoreErr != nil {
+			return fmt.Errorf("%w, and restoring books_isbn13 failed: %v", err, restoreErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+/*
+dropLiveISBNIndex goes back to isbnIndex, which fails while a book in the trash shares its ISBN with another book.
+*/
+func dropLiveISBNIndex(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{liveISBNIndex}); err != nil {
+		return err
+	}
+
+	_, err := books.Indexes().CreateOne(ctx, isbnIndex)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("books in the trash share an ISBN with other books, purge them first: %w", err)
+	}
+
+	return err
+}
+
+/*
+bookOwnerIndex serves the books of one owner, as listed with the owner filter and counted by goal progress.
+Migration 1 predates owners and, being applied, can't be edited to create it.
+*/
+var bookOwnerIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "owner", Value: 1}},
+	Options: options.Index().SetName("books_owner"),
+}
+
+/*
+addBookOwner gives the books stored before owners the empty owner, which new books without one get too,
+so they are found by it rather than only as a missing field.
+*/
+func addBookOwner(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	missing := bson.D{{Key: "owner", Value: bson.D{{Key: "$exists", Value: false}}}}
+	if _, err := books.UpdateMany(ctx, missing, bson.D{{Key: "$set", Value: bson.D{{Key: "owner", Value: ""}}}}); err != nil {
+		return err
+	}
+
+	_, err := books.Indexes().CreateOne(ctx, bookOwnerIndex)
+	return err
+}
+
+func removeBookOwner(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{bookOwnerIndex}); err != nil {
+		return err
+	}
+
+	_, err := books.UpdateMany(ctx, bson.D{}, bson.D{{Key: "$unset", Value: bson.D{{Key: "owner", Value: ""}}}})
+	return err
+}
+
+/*
+ownerISBNIndex replaces liveISBNIndex: it adds owner to the key, so books of different owners may have the same
+ISBN. It relies on migration 15 having given every book an owner, the books nobody owns an empty one.
+*/
+var ownerISBNIndex = mongo.IndexModel{
+	Keys: bson.D{{Key: "owner", Value: 1}, {Key: "isbn13", Value: 1}, {Key: "deletedat", Value: 1}},
+	Options: options.Index().
+		SetName("books_owner_isbn13_deletedAt").
+		SetUnique(true).
+		SetPartialFilterExpression(bson.D{{Key: "isbn13", Value: bson.D{{Key: "$gt", Value: ""}}}}),
+}
+
+/*
+createOwnerISBNIndex swaps liveISBNIndex for ownerISBNIndex. When the server can't create it, liveISBNIndex is put back.
+*/
+func createOwnerISBNIndex(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{liveISBNIndex}); err != nil {
+		return err
+	}
+
+	if _, err := books.Indexes().CreateOne(ctx, ownerISBNIndex); err != nil {
+		if _, restoreErr := books.Indexes().CreateOne(ctx, liveISBNIndex); restoreErr != nil {
+			return fmt.Errorf("%w, and restoring books_isbn13_deletedAt failed: %v", err, restoreErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+/*
+dropOwnerISBNIndex goes back to liveISBNIndex, which fails while books of different owners share an ISBN.
+*/
+func dropOwnerISBNIndex(ctx context.Context, db *mongo.Database) error {
+	books := db.Collection("books")
+
+	if err := dropIndexes(ctx, books, []mongo.IndexModel{ownerISBNIndex}); err != nil {
+		return err
+	}
+
+	_, err := books.Indexes().CreateOne(ctx, liveISBNIndex)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("books of different owners share an ISBN, give them other ISBNs or purge them first: %w", err)
+	}
+
+	return err
+}
+
+var userIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "name", Value: 1}},
+	Options: options.Index().SetName("users_name").SetUnique(true),
+}
+
+func createUserIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("users").Indexes().CreateOne(ctx, userIndex)
+	return err
+}
+
+func dropUsers(ctx context.Context, db *mongo.Database) error {
+	return db.Collection("users").Drop(ctx)
+}
@@ -0,0 +1,374 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strings"
+	"time"
+)
+
+/*
+Calls the DB to create an author. The sort name defaults to "Surname, Forenames".
+
+Parameters:
+
+	param1: input AuthorInput
+
+Returns:
+
+	return1: database id of inserted value
+	return2: pointer of author data
+	return3: error, a *ValidationError when the input is invalid
+*/
+func (m *Model) CreateAuthor(db initialisers.IAuthorCollection, input AuthorInput) (interface{}, *data.Author, error) {
+	author := &data.Author{
+		Name:      strings.TrimSpace(input.Name),
+		SortName:  strings.TrimSpace(input.SortName),
+		BirthYear: input.BirthYear,
+		DeathYear: input.DeathYear,
+		Bio:       input.Bio,
+		Aliases:   input.Aliases,
+		Version:   1,
+	}
+
+	if err := validateAuthor(author); err != nil {
+		return nil, nil, err
+	}
+
+	id, err := db.Create(author)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, author, nil
+}
+
+/*
+Calls the DB to find an author by id.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: pointer of author data
+	return2: error
+*/
+func (m *Model) GetAuthor(db initialisers.IAuthorCollection, id string) (*data.Author, error) {
+	return db.Get(id)
+}
+
+/*
+Calls the DB to list every author, ordered by sort name.
+
+Returns:
+
+	return1: slice of a pointer of authors
+	return2: error
+*/
+func (m *Model) GetAuthors(db initialisers.IAuthorCollection) ([]*data.Author, error) {
+	return db.GetAll()
+}
+
+/*
+Lists the books linked to an author, failing with ErrRecordNotFound for an unknown author.
+
+Parameters:
+
+	param1: id string, ID of the author
+
+Returns:
+
+	return1: slice of a pointer of books
+	return2: error
+*/
+func (m *Model) GetAuthorBooks(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, id string) ([]*data.Book, error) {
+	if _, err := authors.Get(id); err != nil {
+		return nil, err
+	}
+
+	return books.GetByAuthor(id)
+}
+
+/*
+Calls the DB to update an author. The author names stored on linked books are then updated too, in one transaction;
+books already showing the author's name are left alone, so updating the author again after a partial failure
+finishes the job.
+
+Parameters:
+
+	param1: pointer of author data
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) UpdateAuthor(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, author *data.Author) error {
+	if err := validateAuthor(author); err != nil {
+		return err
+	}
+
+	if err := authors.Update(author); err != nil {
+		return err
+	}
+
+	return rewriteBooks(transactor, books, func(books initialisers.IBookCollection) error {
+		return relinkBooks(authors, books, author.ID, author.ID)
+	})
+}
+
+/*
+Calls the DB to delete an author. Linked books keep the author's name but lose the link.
+The books are rewritten in one transaction before the author is deleted, so deleting it again after a partial
+failure finishes the job.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) DeleteAuthor(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, id string) error {
+	if _, err := authors.Get(id); err != nil {
+		return err
+	}
+
+	err := rewriteBooks(transactor, books, func(books initialisers.IBookCollection) error {
+		return relinkBooks(authors, books, id, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	return authors.Delete(id)
+}
+
+/*
+MergeAuthors folds duplicate authors into the target: their names and aliases become aliases of the target,
+their books are re-pointed to it and they are deleted.
+The books are rewritten in one transaction first, then the target is updated and the duplicates deleted last,
+so merging again after a partial failure finishes the job.
+
+Parameters:
+
+	param1: targetID string, the author to keep
+	param2: sourceIDs []string, the duplicates to merge into it
+
+Returns:
+
+	return1: pointer of the merged author data
+	return2: error
+*/
+func (m *Model) MergeAuthors(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, targetID string, sourceIDs []string) (*data.Author, error) {
+	if len(sourceIDs) == 0 {
+		return nil, &ValidationError{Field: "sourceIds", Message: "must list at least one author"}
+	}
+
+	target, err := authors.Get(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]*data.Author, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return nil, &ValidationError{Field: "sourceIds", Message: "cannot merge an author into itself"}
+		}
+
+		source, err := authors.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	err = rewriteBooks(transactor, books, func(books initialisers.IBookCollection) error {
+		for _, source := range sources {
+			if err := relinkBooks(authors, books, source.ID, target.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range sources {
+		target.Aliases = addAliases(target, append([]string{source.Name}, source.Aliases...))
+	}
+	target.Version++
+
+	if err := authors.Update(target); err != nil {
+		return nil, err
+	}
+
+	for _, source := range sources {
+		if err := authors.Delete(source.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return target, nil
+}
+
+/*
+ResolveAuthors looks up the authors with the given IDs and returns their names in the same order,
+so books can store both the links and the names they display.
+
+Parameters:
+
+	param1: ids []string
+
+Returns:
+
+	return1: []string, the authors' names
+	return2: error, a *ValidationError when an ID is unknown
+*/
+func (m *Model) ResolveAuthors(db initialisers.IAuthorCollection, ids []string) ([]string, error) {
+	return authorNames(db, ids)
+}
+
+func authorNames(db initialisers.IAuthorCollection, ids []string) ([]string, error) {
+	names := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		author, err := db.Get(id)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, &ValidationError{Field: "authorIds", Message: fmt.Sprintf("unknown author %q", id)}
+			}
+			return nil, err
+		}
+		names = append(names, author.Name)
+	}
+
+	return names, nil
+}
+
+/*
+relinkBooks replaces authorID with replacement on every book linked to it, including those in the trash,
+removing the link when replacement is empty, and refreshes the author names stored on those books.
+Books that would be left as they are aren't written.
+*/
+func relinkBooks(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, authorID, replacement string) error {
+	linked, err := books.GetFiltered(data.BookFilter{AuthorIDs: []string{authorID}, IncludeDeleted: true})
+	if err != nil {
+		return err
+	}
+
+	for _, book := range linked {
+		var ids []string
+		seen := make(map[string]bool)
+
+		for _, id := range book.AuthorIDs {
+			if id == authorID {
+				id = replacement
+			}
+
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+
+		names := book.Authors
+		if replacement != "" {
+			// Unlinked books keep the names as plain text.
+			if names, err = authorNames(authors, ids); err != nil {
+				return err
+			}
+		}
+
+		if strings.Join(ids, "\x00") == strings.Join(book.AuthorIDs, "\x00") && strings.Join(names, "\x00") == strings.Join(book.Authors, "\x00") {
+			continue
+		}
+
+		book.AuthorIDs, book.Authors = ids, names
+
+		if err := books.Update(book); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+validateAuthor checks the author's name and years, defaulting the sort name.
+*/
+func validateAuthor(author *data.Author) error {
+	if author.Name == "" {
+		return &ValidationError{Field: "name", Message: "must be provided"}
+	}
+
+	if author.SortName == "" {
+		author.SortName = SortName(author.Name)
+	}
+
+	currentYear := time.Now().Year()
+
+	if author.BirthYear < 0 || author.BirthYear > currentYear {
+		return &ValidationError{Field: "birthYear", Message: fmt.Sprintf("must be between 0 and %d", currentYear)}
+	}
+
+	if author.DeathYear < 0 || author.DeathYear > currentYear {
+		return &ValidationError{Field: "deathYear", Message: fmt.Sprintf("must be between 0 and %d", currentYear)}
+	}
+
+	if author.BirthYear != 0 && author.DeathYear != 0 && author.DeathYear < author.BirthYear {
+		return &ValidationError{Field: "deathYear", Message: "must not be before birthYear"}
+	}
+
+	return nil
+}
+
+/*
+SortName derives the name an author is filed under, "Ursula K. Le Guin" becomes "Guin, Ursula K. Le".
+Single-word names are returned unchanged; authors can always set their sort name explicitly.
+
+Parameters:
+
+	param1: name string
+
+Returns:
+
+	return1: string
+*/
+func SortName(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) < 2 {
+		return strings.TrimSpace(name)
+	}
+
+	return parts[len(parts)-1] + ", " + strings.Join(parts[:len(parts)-1], " ")
+}
+
+/*
+addAliases appends the names not already used by the author, case-insensitively.
+*/
+func addAliases(author *data.Author, names []string) []string {
+	aliases := author.Aliases
+	known := map[string]bool{strings.ToLower(author.Name): true}
+
+	for _, alias := range aliases {
+		known[strings.ToLower(alias)] = true
+	}
+
+	for _, name := range names {
+		if key := strings.ToLower(name); name != "" && !known[key] {
+			known[key] = true
+			aliases = append(aliases, name)
+		}
+	}
+
+	return aliases
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, initialisers.ErrRecordNotFound)
+}
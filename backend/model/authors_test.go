@@ -0,0 +1,120 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/internal/data"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortName(t *testing.T) {
+	tests := map[string]string{
+		"Ursula K. Le Guin": "Guin, Ursula K. Le",
+		"Terry Pratchett":   "Pratchett, Terry",
+		"Homer":             "Homer",
+	}
+
+	for name, expected := range tests {
+		if got := SortName(name); got != expected {
+			t.Errorf("SortName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestCreateAuthorValidation(t *testing.T) {
+	authors := memoryAuthors{}
+
+	inputs := []AuthorInput{
+		{Name: " "},
+		{Name: "Anne Brontë", BirthYear: 1820, DeathYear: 1749},
+	}
+
+	for _, input := range inputs {
+		_, _, err := model.CreateAuthor(authors, input)
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("CreateAuthor(%+v) got error %v, expected a ValidationError", input, err)
+		}
+	}
+}
+
+func TestMergeAuthors(t *testing.T) {
+	deleted := time.Now()
+	authors := memoryAuthors{
+		"a1": {ID: "a1", Name: "Terry Pratchett"},
+		"a2": {ID: "a2", Name: "T. Pratchett", Aliases: []string{"Pratchett"}},
+	}
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Authors: []string{"T. Pratchett"}, AuthorIDs: []string{"a2"}},
+		"b2": {ID: "b2", Authors: []string{"Terry Pratchett", "T. Pratchett"}, AuthorIDs: []string{"a1", "a2"}},
+		"b3": {ID: "b3", Authors: []string{"T. Pratchett"}, AuthorIDs: []string{"a2"}, DeletedAt: &deleted},
+	}}
+
+	merged, err := model.MergeAuthors(authors, books, memoryTransactor{books}, "a1", []string{"a2"})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if !reflect.DeepEqual(merged.Aliases, []string{"T. Pratchett", "Pratchett"}) {
+		t.Errorf("got aliases %v", merged.Aliases)
+	}
+
+	if _, ok := authors["a2"]; ok {
+		t.Errorf("expected the duplicate author to be deleted")
+	}
+
+	for id, book := range books.books {
+		if !reflect.DeepEqual(book.AuthorIDs, []string{"a1"}) || !reflect.DeepEqual(book.Authors, []string{"Terry Pratchett"}) {
+			t.Errorf("book %s got authors %v %v, expected only a1", id, book.AuthorIDs, book.Authors)
+		}
+	}
+
+	if _, err := model.MergeAuthors(authors, books, memoryTransactor{books}, "a1", []string{"a1"}); err == nil {
+		t.Errorf("expected an error merging an author into itself")
+	}
+}
+
+func TestUpdateAndDeleteAuthor(t *testing.T) {
+	deleted := time.Now()
+	authors := memoryAuthors{"a1": {ID: "a1", Name: "T. Pratchett"}}
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Authors: []string{"T. Pratchett"}, AuthorIDs: []string{"a1"}},
+		"b2": {ID: "b2", Authors: []string{"T. Pratchett"}, AuthorIDs: []string{"a1"}, DeletedAt: &deleted},
+	}}
+
+	renamed := &data.Author{ID: "a1", Name: "Terry Pratchett"}
+	if err := model.UpdateAuthor(authors, books, failedTransactor{}, renamed); err == nil {
+		t.Errorf("expected the error of the transaction")
+	}
+
+	if books.books["b1"].Authors[0] != "T. Pratchett" {
+		t.Errorf("got %v, expected the books left as they were when the transaction fails", books.books["b1"].Authors)
+	}
+
+	// Updating again finishes the job, though the name no longer changes
+	if err := model.UpdateAuthor(authors, books, memoryTransactor{books}, renamed); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	for id, book := range books.books {
+		if !reflect.DeepEqual(book.Authors, []string{"Terry Pratchett"}) {
+			t.Errorf("book %s got authors %v, expected the new name", id, book.Authors)
+		}
+	}
+
+	if err := model.DeleteAuthor(authors, books, failedTransactor{}, "a1"); err == nil || authors["a1"] == nil {
+		t.Errorf("got error %v, expected the author kept when the transaction fails", err)
+	}
+
+	if err := model.DeleteAuthor(authors, books, memoryTransactor{books}, "a1"); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	for id, book := range books.books {
+		if len(book.AuthorIDs) != 0 || book.Authors[0] != "Terry Pratchett" {
+			t.Errorf("book %s got authors %v %v, expected the name kept without the link", id, book.AuthorIDs, book.Authors)
+		}
+	}
+}
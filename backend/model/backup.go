@@ -0,0 +1,253 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"readinglistapp/backup"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// How a restore treats what is already stored.
+const (
+	RestoreMerge   = "merge"
+	RestoreReplace = "replace"
+)
+
+/*
+RestoreReport tells what a restore did with the documents of each collection of the backup.
+*/
+type RestoreReport struct {
+	Mode        string                   `json:"mode"`
+	Manifest    *backup.Manifest         `json:"manifest"`
+	Collections map[string]*RestoreCount `json:"collections"`
+}
+
+/*
+RestoreCount counts what a restore did in one collection. Skipped documents were already there in a merge,
+or had nowhere to go when the backend only stores books.
+*/
+type RestoreCount struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+	Skipped int `json:"skipped"`
+}
+
+/*
+Writes every book, including the books in the trash, and every document of the related collections when documents is not nil, as a backup.
+
+Parameters:
+
+	param1: documents initialisers.IDocumentStore, nil to back up the books only
+	param2: w io.Writer, the backup file
+
+Returns:
+
+	return1: pointer of the manifest written at the end of the backup
+	return2: error
+*/
+func (m *Model) Backup(books initialisers.IBookCollection, documents initialisers.IDocumentStore, w io.Writer) (*backup.Manifest, error) {
+	writer := backup.NewWriter(w)
+
+	if err := books.ForEach(writer.WriteBook); err != nil {
+		return nil, err
+	}
+
+	// Books in the trash are backed up with their deletedAt time, so they go back to the trash
+	trash, err := books.GetDeleted()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, book := range trash {
+		if err := writer.WriteBook(book); err != nil {
+			return nil, err
+		}
+	}
+
+	if documents != nil {
+		for _, collection := range backup.Collections {
+			err := documents.ForEachDocument(collection, func(doc bson.Raw) error {
+				return writer.WriteDocument(collection, doc)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return writer.Close()
+}
+
+/*
+Restores a backup, which is read and checked in full before anything is written. Books keep their IDs, so
+shelves, reviews and the other related documents still point at them.
+
+In merge mode what is already stored is kept: only the books and documents that aren't there are added.
+In replace mode the library becomes the backup: books are overwritten, books missing from the backup are
+purged, even from the trash, and each related collection is swapped for its documents in the backup.
+The books are restored in one transaction when the backend supports them, and each related collection at once
+by the document store, so a restore that fails leaves the books, and every collection not yet reached, as they were.
+
+Parameters:
+
+	param1: documents initialisers.IDocumentStore, nil to restore the books only
+	param2: transactor initialisers.ITransactor, to restore the books in one transaction
+	param3: r io.Reader, the backup file
+	param4: mode string, RestoreMerge or RestoreReplace
+
+Returns:
+
+	return1: pointer of the restore report
+	return2: error, a *ValidationError when the mode is unknown or the file is not a valid backup
+*/
+func (m *Model) Restore(books initialisers.IBookCollection, documents initialisers.IDocumentStore, transactor initialisers.ITransactor, r io.Reader, mode string) (*RestoreReport, error) {
+	if mode != RestoreMerge && mode != RestoreReplace {
+		return nil, &ValidationError{Field: "mode", Message: fmt.Sprintf("must be %s or %s", RestoreMerge, RestoreReplace)}
+	}
+
+	content, err := backup.Read(r)
+	if errors.Is(err, backup.ErrInvalid) {
+		return nil, &ValidationError{Field: "file", Message: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RestoreReport{Mode: mode, Manifest: content.Manifest, Collections: make(map[string]*RestoreCount)}
+
+	var count *RestoreCount
+
+	err = rewriteBooks(transactor, books, func(books initialisers.IBookCollection) error {
+		count, err = restoreBooks(books, content.Books, mode == RestoreReplace)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Collections[backup.Books] = count
+
+	for _, collection := range backup.Collections {
+		docs := content.Documents[collection]
+		count := &RestoreCount{}
+		report.Collections[collection] = count
+
+		if documents == nil {
+			count.Skipped = len(docs)
+			continue
+		}
+
+		count.Created, count.Deleted, count.Skipped, err = documents.RestoreDocuments(collection, docs, mode == RestoreReplace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+/*
+restoreBooks writes the books of a backup through the book collection, so any backend can be restored into,
+in one transaction when Restore runs it through the backend's transactor.
+Books in the trash count as stored; a book whose place in the trash changes is purged and created again,
+since updates leave the trash alone.
+*/
+func restoreBooks(db initialisers.IBookCollection, books []*data.Book, replace bool) (*RestoreCount, error) {
+	existing, err := db.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := db.GetDeleted()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]bool, len(existing)+len(deleted))
+	for _, book := range existing {
+		stored[book.ID] = true
+	}
+
+	trashed := make(map[string]bool, len(deleted))
+	for _, book := range deleted {
+		stored[book.ID] = true
+		trashed[book.ID] = true
+	}
+
+	count := &RestoreCount{}
+
+	if replace {
+		restored := make(map[string]bool, len(books))
+		for _, book := range books {
+			restored[book.ID] = true
+		}
+
+		// Deleting first frees the ISBNs of the books that go for the restored ones
+		for _, book := range append(existing, deleted...) {
+			if restored[book.ID] {
+				continue
+			}
+			if err := purgeBook(db, book.ID, trashed[book.ID]); err != nil {
+				return nil, err
+			}
+			count.Deleted++
+		}
+	}
+
+	for _, book := range books {
+		if stored[book.ID] && !replace {
+			count.Skipped++
+			continue
+		}
+
+		if stored[book.ID] && !trashed[book.ID] && book.DeletedAt == nil {
+			if err := db.Update(book); err != nil {
+				return nil, err
+			}
+			count.Updated++
+			continue
+		}
+
+		if stored[book.ID] {
+			if err := purgeBook(db, book.ID, trashed[book.ID]); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err := db.Create(book)
+
+		// In a merge another book may already have the ISBN; it is kept like the other stored books
+		if errors.Is(err, initialisers.ErrDuplicateRecord) && !replace {
+			count.Skipped++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if stored[book.ID] {
+			count.Updated++
+		} else {
+			count.Created++
+		}
+	}
+
+	return count, nil
+}
+
+/*
+purgeBook permanently removes a book, moving it to the trash first when it isn't there already.
+*/
+func purgeBook(db initialisers.IBookCollection, id string, trashed bool) error {
+	if !trashed {
+		if err := db.Delete(id); err != nil {
+			return err
+		}
+	}
+
+	return db.Purge(id)
+}
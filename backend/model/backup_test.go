@@ -0,0 +1,165 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"readinglistapp/backup"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func backupLibrary(t *testing.T) (*bytes.Buffer, memoryDocuments) {
+	t.Helper()
+
+	shelf, _ := bson.Marshal(bson.D{{Key: "_id", Value: "s1"}, {Key: "name", Value: "Favourites"}, {Key: "bookids", Value: bson.A{"b1"}}})
+	documents := memoryDocuments{"shelves": {shelf}}
+
+	var file bytes.Buffer
+	manifest, err := model.Backup(newImportLibrary(), documents, &file)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if manifest.Counts[backup.Books] != 2 || manifest.Counts["shelves"] != 1 {
+		t.Fatalf("got counts %v, expected 2 books and 1 shelf", manifest.Counts)
+	}
+
+	return &file, documents
+}
+
+func TestRestoreMerge(t *testing.T) {
+	file, _ := backupLibrary(t)
+
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma, changed since"},
+		"b9": {ID: "b9", Title: "Added since"},
+	}}
+	documents := memoryDocuments{}
+
+	report, err := model.Restore(books, documents, memoryTransactor{books}, file, RestoreMerge)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if count := report.Collections[backup.Books]; count.Created != 1 || count.Skipped != 1 || count.Deleted != 0 {
+		t.Errorf("got %+v, expected Persuasion added and Emma kept", count)
+	}
+
+	if len(books.books) != 3 || books.books["b1"].Title != "Emma, changed since" || books.books["b2"].Title != "Persuasion" {
+		t.Errorf("got %v, expected the missing book added with its ID and the others untouched", books.books)
+	}
+
+	if len(documents["shelves"]) != 1 || report.Collections["shelves"].Created != 1 {
+		t.Errorf("got %+v, expected the shelf restored", report.Collections["shelves"])
+	}
+}
+
+func TestRestoreTrash(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	library := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma"},
+		"b2": {ID: "b2", Title: "Persuasion", DeletedAt: &deletedAt},
+	}}
+
+	var file bytes.Buffer
+	if _, err := model.Backup(library, nil, &file); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	// Since the backup Emma went to the trash, Persuasion came out of it and Sanditon was deleted
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma", DeletedAt: &deletedAt},
+		"b2": {ID: "b2", Title: "Persuasion"},
+		"b9": {ID: "b9", Title: "Sanditon", DeletedAt: &deletedAt},
+	}}
+
+	report, err := model.Restore(books, nil, memoryTransactor{books}, &file, RestoreReplace)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if count := report.Collections[backup.Books]; count.Created != 0 || count.Updated != 2 || count.Deleted != 1 {
+		t.Errorf("got %+v, expected both books replaced and Sanditon purged", count)
+	}
+
+	if len(books.books) != 2 || books.books["b1"].DeletedAt != nil || books.books["b2"].DeletedAt == nil {
+		t.Errorf("got %v, expected Emma out of the trash and Persuasion back in it", books.books)
+	}
+}
+
+func TestRestoreReplace(t *testing.T) {
+	file, _ := backupLibrary(t)
+
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma, changed since"},
+		"b9": {ID: "b9", Title: "Added since"},
+	}}
+
+	report, err := model.Restore(books, nil, unsupportedTransactor{}, file, RestoreReplace)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if count := report.Collections[backup.Books]; count.Created != 1 || count.Updated != 1 || count.Deleted != 1 {
+		t.Errorf("got %+v, expected the library to become the backup", count)
+	}
+
+	if len(books.books) != 2 || books.books["b1"].Title != "Emma" || books.books["b9"] != nil {
+		t.Errorf("got %v, expected only the books of the backup", books.books)
+	}
+
+	if report.Collections["shelves"].Skipped != 1 {
+		t.Errorf("got %+v, expected the shelf skipped without a document store", report.Collections["shelves"])
+	}
+}
+
+func TestRestoreReplaceFails(t *testing.T) {
+	// A backup can't be restored when two of its books have the same ISBN
+	library := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma", ISBN13: "9780141439587"},
+		"b2": {ID: "b2", Title: "Emma, again", ISBN13: "9780141439587"},
+	}}
+
+	var file bytes.Buffer
+	if _, err := model.Backup(library, nil, &file); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	books := memoryBooks{books: map[string]*data.Book{
+		"b9": {ID: "b9", Title: "Sanditon"},
+	}}
+
+	if _, err := model.Restore(books, nil, memoryTransactor{books}, &file, RestoreReplace); !errors.Is(err, initialisers.ErrDuplicateRecord) {
+		t.Fatalf("got error %v, expected a duplicate ISBN", err)
+	}
+
+	if len(books.books) != 1 || books.books["b9"] == nil {
+		t.Errorf("got %v, expected the library left as it was", books.books)
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	file, _ := backupLibrary(t)
+
+	var validationErr *ValidationError
+
+	if _, err := model.Restore(newImportLibrary(), nil, unsupportedTransactor{}, file, "overwrite"); !errors.As(err, &validationErr) || validationErr.Field != "mode" {
+		t.Errorf("got error %v, expected an invalid mode", err)
+	}
+
+	corrupt := file.Bytes()[:file.Len()/2]
+	books := memoryBooks{books: map[string]*data.Book{}}
+
+	if _, err := model.Restore(books, nil, memoryTransactor{books}, bytes.NewReader(corrupt), RestoreReplace); !errors.As(err, &validationErr) || validationErr.Field != "file" {
+		t.Errorf("got error %v, expected an invalid file", err)
+	}
+
+	if len(books.books) != 0 {
+		t.Error("expected nothing restored from an invalid file")
+	}
+}
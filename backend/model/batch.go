@@ -0,0 +1,244 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+)
+
+// MaxBatchSize is the most operations a single batch may contain.
+const MaxBatchSize = 100
+
+// Operations a batch can apply to a book.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// ErrBatchAborted marks the operations of an atomic batch that were rolled back or never run because another one failed.
+var ErrBatchAborted = errors.New("batch aborted")
+
+/*
+BatchInput lists the operations of a batch. When Atomic is set they are applied in one transaction:
+either all of them succeed or none is kept.
+*/
+type BatchInput struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+/*
+BatchOperation creates, updates or deletes one book. Book holds the same body as the single-item
+endpoint: an Input for create and an UpdateInput for update. ID is required for update and delete.
+*/
+type BatchOperation struct {
+	Op   string          `json:"op"`
+	ID   string          `json:"id"`
+	Book json.RawMessage `json:"book"`
+}
+
+/*
+BatchResult is the outcome of one operation, in the order they were sent.
+Err is nil on success; Status and Error are filled in by the controller from it.
+*/
+type BatchResult struct {
+	Index  int        `json:"index"`
+	Op     string     `json:"op"`
+	ID     string     `json:"id,omitempty"`
+	Status int        `json:"status"`
+	Error  string     `json:"error,omitempty"`
+	Book   *data.Book `json:"book,omitempty"`
+	Err    error      `json:"-"`
+}
+
+/*
+Applies a batch of creates, updates and deletes, validating each operation like the single-item endpoints.
+
+Without Atomic every operation is applied on its own and its outcome recorded, whatever happened to the others.
+With Atomic they run in one transaction that stops at the first failure: that operation keeps its error and
+the others are marked with ErrBatchAborted.
+
+Parameters:
+
+	param1: input BatchInput
+
+Returns:
+
+	return1: slice of a pointer of results, one per operation
+	return2: error, a *ValidationError when the batch is empty or larger than MaxBatchSize or is atomic on a server
+	without transactions, or the error of the transaction itself when it could not be run or committed
+*/
+func (m *Model) Batch(books initialisers.IBookCollection, authors initialisers.IAuthorCollection, transactor initialisers.ITransactor, input BatchInput) ([]*BatchResult, error) {
+	if len(input.Operations) == 0 {
+		return nil, &ValidationError{Field: "operations", Message: "must list at least one operation"}
+	}
+
+	if len(input.Operations) > MaxBatchSize {
+		return nil, &ValidationError{Field: "operations", Message: fmt.Sprintf("must not list more than %d operations", MaxBatchSize)}
+	}
+
+	if !input.Atomic {
+		return m.applyBatch(books, authors, input.Operations, false), nil
+	}
+
+	var results []*BatchResult
+	var failed *BatchResult
+
+	err := transactor.BookTransaction(func(books initialisers.IBookCollection) error {
+		results = m.applyBatch(books, authors, input.Operations, true)
+		failed = nil
+
+		for _, result := range results {
+			if result.Err != nil {
+				failed = result
+				return result.Err
+			}
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, initialisers.ErrTransactionsUnsupported) {
+		return nil, &ValidationError{Field: "atomic", Message: "needs transactions, which this MongoDB deployment does not support: send the batch without atomic"}
+	}
+
+	if failed == nil {
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	for _, result := range results {
+		switch {
+		case result == failed:
+		case result.Err == nil:
+			result.Err = fmt.Errorf("%w: rolled back because operation %d failed", ErrBatchAborted, failed.Index)
+			result.Book = nil
+		default:
+			result.Err = fmt.Errorf("%w: not run because operation %d failed", ErrBatchAborted, failed.Index)
+		}
+	}
+
+	return results, nil
+}
+
+/*
+applyBatch runs the operations in order and records their outcome. With stopOnError, the operations after
+the first failure are not run and get ErrBatchAborted.
+*/
+func (m *Model) applyBatch(books initialisers.IBookCollection, authors initialisers.IAuthorCollection, operations []BatchOperation, stopOnError bool) []*BatchResult {
+	results := make([]*BatchResult, len(operations))
+	stopped := false
+
+	for i, operation := range operations {
+		result := &BatchResult{Index: i, Op: operation.Op, ID: operation.ID}
+		results[i] = result
+
+		if stopped {
+			result.Err = ErrBatchAborted
+			continue
+		}
+
+		result.Book, result.Err = m.applyBatchOperation(books, authors, operation)
+
+		if result.Book != nil {
+			result.ID = result.Book.ID
+		}
+
+		if result.Err != nil && stopOnError {
+			stopped = true
+		}
+	}
+
+	return results
+}
+
+// applyBatchOperation applies one operation through the same model methods as the single-item endpoints.
+func (m *Model) applyBatchOperation(books initialisers.IBookCollection, authors initialisers.IAuthorCollection, operation BatchOperation) (*data.Book, error) {
+	switch operation.Op {
+	case BatchCreate:
+		var input Input
+		if err := decodeBatchBook(operation.Book, &input); err != nil {
+			return nil, err
+		}
+
+		if len(input.AuthorIDs) > 0 {
+			names, err := m.ResolveAuthors(authors, input.AuthorIDs)
+			if err != nil {
+				return nil, err
+			}
+			input.Authors = names
+		}
+
+		_, book, err := m.Insert(books, input)
+		return book, err
+
+	case BatchUpdate:
+		if operation.ID == "" {
+			return nil, &ValidationError{Field: "id", Message: "must be provided"}
+		}
+
+		var input UpdateInput
+		if err := decodeBatchBook(operation.Book, &input); err != nil {
+			return nil, err
+		}
+
+		book, err := m.Get(books, operation.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.ApplyUpdate(authors, book, input); err != nil {
+			return nil, err
+		}
+
+		if err := m.Update(books, operation.ID, book); err != nil {
+			return nil, err
+		}
+
+		return book, nil
+
+	case BatchDelete:
+		if operation.ID == "" {
+			return nil, &ValidationError{Field: "id", Message: "must be provided"}
+		}
+
+		return nil, m.Delete(books, operation.ID)
+	}
+
+	return nil, &ValidationError{Field: "op", Message: fmt.Sprintf("must be %q, %q or %q", BatchCreate, BatchUpdate, BatchDelete)}
+}
+
+// decodeBatchBook decodes the book of an operation, rejecting unknown fields like view.ReadJSON.
+func decodeBatchBook(raw json.RawMessage, dst interface{}) error {
+	if len(raw) == 0 {
+		return &ValidationError{Field: "book", Message: "must be provided"}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return &ValidationError{Field: "book", Message: err.Error()}
+	}
+
+	return nil
+}
+
+/*
+rewriteBooks runs fn, which rewrites several books, in one transaction. Without transaction support, as on a
+standalone server, fn runs on its own: callers order their writes so that running them again repairs a partial failure.
+*/
+func rewriteBooks(transactor initialisers.ITransactor, books initialisers.IBookCollection, fn func(books initialisers.IBookCollection) error) error {
+	err := transactor.BookTransaction(fn)
+	if errors.Is(err, initialisers.ErrTransactionsUnsupported) {
+		return fn(books)
+	}
+
+	return err
+}
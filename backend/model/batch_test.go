@@ -0,0 +1,126 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Dune"},
+		"b2": {ID: "b2", Title: "Emma"},
+	}}
+	authors := memoryAuthors{"a1": {ID: "a1", Name: "Frank Herbert"}}
+
+	results, err := model.Batch(books, authors, memoryTransactor{books}, BatchInput{Operations: []BatchOperation{
+		{Op: BatchCreate, Book: json.RawMessage(`{"title": "Persuasion", "isbn13": "978-0-306-40615-7"}`)},
+		{Op: BatchUpdate, ID: "b1", Book: json.RawMessage(`{"title": "Dune Messiah", "authorIds": ["a1"]}`)},
+		{Op: BatchDelete, ID: "b2"},
+		{Op: BatchUpdate, ID: "missing", Book: json.RawMessage(`{"title": "Nowhere"}`)},
+		{Op: BatchCreate, Book: json.RawMessage(`{"title": "Bad", "isbn13": "123"}`)},
+		{Op: BatchCreate, Book: json.RawMessage(`{"title": "Odd", "colour": "red"}`)},
+		{Op: "archive", ID: "b1"},
+	}})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if results[i].Err != nil {
+			t.Errorf("got error %v for operation %d, expected nil", results[i].Err, i)
+		}
+	}
+
+	if created := books.books[results[0].ID]; created == nil || created.ISBN13 != "9780306406157" || created.Status != data.StatusWantToRead {
+		t.Errorf("got %+v, expected the book created like the single-item endpoint does", created)
+	}
+
+	if got := books.books["b1"]; got.Title != "Dune Messiah" || len(got.Authors) != 1 || got.Authors[0] != "Frank Herbert" {
+		t.Errorf("got %+v, expected the title and linked author updated", got)
+	}
+
+	if got := books.books["b2"]; got == nil || got.DeletedAt == nil {
+		t.Error("expected b2 to be moved to the trash")
+	}
+
+	if !errors.Is(results[3].Err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected ErrRecordNotFound", results[3].Err)
+	}
+
+	var validationErr *ValidationError
+	for _, result := range results[4:] {
+		if !errors.As(result.Err, &validationErr) {
+			t.Errorf("got error %v for operation %d, expected a ValidationError", result.Err, result.Index)
+		}
+	}
+}
+
+func TestBatchAtomic(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Dune", ISBN13: "9780306406157"},
+	}}
+
+	results, err := model.Batch(books, memoryAuthors{}, memoryTransactor{books}, BatchInput{Atomic: true, Operations: []BatchOperation{
+		{Op: BatchUpdate, ID: "b1", Book: json.RawMessage(`{"title": "Dune Messiah"}`)},
+		{Op: BatchCreate, Book: json.RawMessage(`{"title": "Copy", "isbn13": "9780306406157"}`)},
+		{Op: BatchDelete, ID: "b1"},
+	}})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if !errors.Is(results[1].Err, initialisers.ErrDuplicateRecord) {
+		t.Errorf("got error %v, expected the failing operation to keep its error", results[1].Err)
+	}
+
+	for _, i := range []int{0, 2} {
+		if !errors.Is(results[i].Err, ErrBatchAborted) {
+			t.Errorf("got error %v for operation %d, expected ErrBatchAborted", results[i].Err, i)
+		}
+	}
+
+	if len(books.books) != 1 || books.books["b1"].Title != "Dune" {
+		t.Errorf("got %v, expected every change rolled back", books.books)
+	}
+
+	results, err = model.Batch(books, memoryAuthors{}, memoryTransactor{books}, BatchInput{Atomic: true, Operations: []BatchOperation{
+		{Op: BatchUpdate, ID: "b1", Book: json.RawMessage(`{"title": "Dune Messiah"}`)},
+		{Op: BatchCreate, Book: json.RawMessage(`{"title": "Emma"}`)},
+	}})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if len(books.books) != 2 || books.books["b1"].Title != "Dune Messiah" || results[1].Book.Title != "Emma" {
+		t.Errorf("got %v, expected every change kept", books.books)
+	}
+}
+
+func TestBatchAtomicWithoutTransactions(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{}}
+
+	results, err := model.Batch(books, memoryAuthors{}, unsupportedTransactor{}, BatchInput{Atomic: true, Operations: []BatchOperation{
+		{Op: BatchCreate, Book: json.RawMessage(`{"title": "Emma"}`)},
+	}})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "atomic" || results != nil {
+		t.Errorf("got %v and error %v, expected one ValidationError for the whole batch", results, err)
+	}
+}
+
+func TestBatchSize(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{}}
+
+	var validationErr *ValidationError
+	for _, size := range []int{0, MaxBatchSize + 1} {
+		input := BatchInput{Operations: make([]BatchOperation, size)}
+
+		if _, err := model.Batch(books, memoryAuthors{}, memoryTransactor{books}, input); !errors.As(err, &validationErr) {
+			t.Errorf("got error %v for %d operations, expected a ValidationError", err, size)
+		}
+	}
+}
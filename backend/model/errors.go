@@ -0,0 +1,15 @@
+package model
+
+import "fmt"
+
+/*
+ValidationError reports input rejected by the model, the controller answers it with 422.
+*/
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
@@ -0,0 +1,292 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strings"
+	"time"
+)
+
+/*
+Calls the DB to create a reading goal.
+
+Parameters:
+
+	param1: input GoalInput
+
+Returns:
+
+	return1: database id of inserted value
+	return2: pointer of goal data
+	return3: error, a *ValidationError for an unknown metric, an invalid period or a target below 1
+*/
+func (m *Model) CreateGoal(db initialisers.IGoalCollection, input GoalInput) (interface{}, *data.Goal, error) {
+	goal := &data.Goal{
+		Owner:   strings.TrimSpace(input.Owner),
+		Metric:  input.Metric,
+		Year:    input.Year,
+		Month:   input.Month,
+		Target:  input.Target,
+		Version: 1,
+	}
+
+	if goal.Metric == "" {
+		goal.Metric = data.GoalMetricBooks
+	}
+
+	if err := validateGoal(goal); err != nil {
+		return nil, nil, err
+	}
+
+	id, err := db.Create(goal)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, goal, nil
+}
+
+/*
+Calls the DB to list the goals of an owner, the latest year first.
+
+Parameters:
+
+	param1: owner string
+
+Returns:
+
+	return1: slice of a pointer of goals
+	return2: error
+*/
+func (m *Model) GetGoals(db initialisers.IGoalCollection, owner string) ([]*data.Goal, error) {
+	return db.GetByOwner(strings.TrimSpace(owner))
+}
+
+/*
+Calls the DB to find a goal by id.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: pointer of goal data
+	return2: error
+*/
+func (m *Model) GetGoal(db initialisers.IGoalCollection, id string) (*data.Goal, error) {
+	return db.Get(id)
+}
+
+/*
+Calls the DB to update a goal.
+
+Parameters:
+
+	param1: pointer of goal data
+
+Returns:
+
+	return1: error, a *ValidationError for an unknown metric, an invalid period or a target below 1
+*/
+func (m *Model) UpdateGoal(db initialisers.IGoalCollection, goal *data.Goal) error {
+	if err := validateGoal(goal); err != nil {
+		return err
+	}
+
+	return db.Update(goal)
+}
+
+/*
+Calls the DB to delete a goal.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) DeleteGoal(db initialisers.IGoalCollection, id string) error {
+	return db.Delete(id)
+}
+
+/*
+Reports the progress of a goal from the books of its owner finished in its period.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: pointer of goal progress
+	return2: error
+*/
+func (m *Model) GetGoalProgress(goals initialisers.IGoalCollection, books initialisers.IBookCollection, id string) (*data.GoalProgress, error) {
+	goal, err := goals.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	finished, err := finishedBooks(books, goal.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return goalProgress(goal, finished, time.Now()), nil
+}
+
+/*
+Reports the progress of the owner's goals for the current year and month, from the books of the owner.
+
+Parameters:
+
+	param1: owner string
+
+Returns:
+
+	return1: slice of a pointer of goal progress, yearly goals first
+	return2: error
+*/
+func (m *Model) GetCurrentGoalsProgress(goals initialisers.IGoalCollection, books initialisers.IBookCollection, owner string) ([]*data.GoalProgress, error) {
+	owner = strings.TrimSpace(owner)
+
+	all, err := goals.GetByOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var current []*data.Goal
+	for _, goal := range all {
+		if start, end := goalPeriod(goal); !now.Before(start) && now.Before(end) {
+			current = append(current, goal)
+		}
+	}
+
+	if len(current) == 0 {
+		return nil, nil
+	}
+
+	finished, err := finishedBooks(books, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]*data.GoalProgress, 0, len(current))
+	for _, goal := range current {
+		progress = append(progress, goalProgress(goal, finished, now))
+	}
+
+	return progress, nil
+}
+
+// finishedBooks returns the finished books of an owner, those nobody owns for the empty owner.
+func finishedBooks(books initialisers.IBookCollection, owner string) ([]*data.Book, error) {
+	return books.GetFiltered(data.BookFilter{Owners: []string{owner}, Statuses: []string{data.StatusFinished}})
+}
+
+/*
+goalProgress counts the books, or their pages, finished in the goal's period, from the finished books of the goal's
+owner, and compares it with an even pace from the start of the period to now. The projections extend the pace so far to the end of the period.
+*/
+func goalProgress(goal *data.Goal, finished []*data.Book, now time.Time) *data.GoalProgress {
+	start, end := goalPeriod(goal)
+
+	progress := &data.GoalProgress{Goal: goal, Start: start, End: end}
+
+	for _, book := range finished {
+		if book.FinishedAt == nil || book.FinishedAt.Before(start) || !book.FinishedAt.Before(end) {
+			continue
+		}
+
+		if goal.Metric == data.GoalMetricPages {
+			progress.Current += book.Pages
+		} else {
+			progress.Current++
+		}
+	}
+
+	elapsed := now.Sub(start)
+	switch {
+	case elapsed < 0:
+		elapsed = 0
+	case elapsed > end.Sub(start):
+		elapsed = end.Sub(start)
+	}
+
+	fraction := float64(elapsed) / float64(end.Sub(start))
+	days := elapsed.Hours() / 24
+
+	progress.Percent = round1(float64(progress.Current) / float64(goal.Target) * 100)
+	progress.Expected = int(math.Round(float64(goal.Target) * fraction))
+	progress.Difference = progress.Current - progress.Expected
+
+	if days > 0 {
+		progress.PerDay = round1(float64(progress.Current) / days)
+	}
+
+	if fraction > 0 {
+		progress.ProjectedTotal = int(math.Round(float64(progress.Current) / fraction))
+	}
+
+	remaining := goal.Target - progress.Current
+
+	if remaining > 0 && now.Before(end) {
+		progress.NeededPerDay = round1(float64(remaining) / (end.Sub(now).Hours() / 24))
+
+		if progress.Current > 0 {
+			completion := start.Add(time.Duration(float64(elapsed) * float64(goal.Target) / float64(progress.Current)))
+			progress.ProjectedCompletion = &completion
+		}
+	}
+
+	switch {
+	case remaining <= 0:
+		progress.Status = data.GoalCompleted
+	case !now.Before(end):
+		progress.Status = data.GoalMissed
+	case now.Before(start):
+		progress.Status = data.GoalNotStarted
+	case progress.Difference > 0:
+		progress.Status = data.GoalAhead
+	case progress.Difference < 0:
+		progress.Status = data.GoalBehind
+	default:
+		progress.Status = data.GoalOnTrack
+	}
+
+	return progress
+}
+
+/*
+goalPeriod returns the start and end (exclusive) of the goal's year or month, in UTC.
+*/
+func goalPeriod(goal *data.Goal) (time.Time, time.Time) {
+	if goal.Month > 0 {
+		start := time.Date(goal.Year, time.Month(goal.Month), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+
+	start := time.Date(goal.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(1, 0, 0)
+}
+
+func validateGoal(goal *data.Goal) error {
+	switch {
+	case goal.Metric != data.GoalMetricBooks && goal.Metric != data.GoalMetricPages:
+		return &ValidationError{Field: "metric", Message: fmt.Sprintf("%q must be one of %s", goal.Metric, strings.Join(data.GoalMetrics, ", "))}
+	case goal.Year < 1900 || goal.Year > 9999:
+		return &ValidationError{Field: "year", Message: "must be between 1900 and 9999"}
+	case goal.Month < 0 || goal.Month > 12:
+		return &ValidationError{Field: "month", Message: "must be between 1 and 12, or 0 for a yearly goal"}
+	case goal.Target < 1:
+		return &ValidationError{Field: "target", Message: "must be at least 1"}
+	}
+
+	return nil
+}
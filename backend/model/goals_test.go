@@ -0,0 +1,106 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/internal/data"
+	"testing"
+	"time"
+)
+
+func TestGoalProgress(t *testing.T) {
+	at := func(month time.Month, day int) *time.Time {
+		date := time.Date(2026, month, day, 12, 0, 0, 0, time.UTC)
+		return &date
+	}
+
+	finished := []*data.Book{
+		{Title: "Mort", Pages: 300, FinishedAt: at(time.January, 20)},
+		{Title: "Sourcery", Pages: 250, FinishedAt: at(time.February, 10)},
+		{Title: "Wyrd Sisters", Pages: 280, FinishedAt: at(time.March, 5)},
+		{Title: "Pyramids", Pages: 320, FinishedAt: &time.Time{}},
+	}
+
+	// A quarter of the way through 2026.
+	now := time.Date(2026, time.April, 2, 6, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		goal     data.Goal
+		current  int
+		expected int
+		status   string
+	}{
+		{"books behind", data.Goal{Metric: data.GoalMetricBooks, Year: 2026, Target: 24}, 3, 6, data.GoalBehind},
+		{"books ahead", data.Goal{Metric: data.GoalMetricBooks, Year: 2026, Target: 8}, 3, 2, data.GoalAhead},
+		{"pages completed", data.Goal{Metric: data.GoalMetricPages, Year: 2026, Month: 2, Target: 200}, 250, 200, data.GoalCompleted},
+		{"month missed", data.Goal{Metric: data.GoalMetricBooks, Year: 2026, Month: 3, Target: 2}, 1, 2, data.GoalMissed},
+		{"not started", data.Goal{Metric: data.GoalMetricBooks, Year: 2027, Target: 12}, 0, 0, data.GoalNotStarted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := goalProgress(&tt.goal, finished, now)
+
+			if progress.Current != tt.current || progress.Expected != tt.expected || progress.Status != tt.status {
+				t.Errorf("got current %d, expected %d, status %q; expected %d, %d and %q",
+					progress.Current, progress.Expected, progress.Status, tt.current, tt.expected, tt.status)
+			}
+		})
+	}
+
+	progress := goalProgress(&data.Goal{Metric: data.GoalMetricBooks, Year: 2026, Target: 24}, finished, now)
+
+	if progress.ProjectedTotal != 12 || progress.ProjectedCompletion == nil || progress.ProjectedCompletion.Year() != 2028 {
+		t.Errorf("got projected total %d finishing %v, expected 12 finishing in 2028", progress.ProjectedTotal, progress.ProjectedCompletion)
+	}
+}
+
+func TestGoalProgressCountsOwnerBooks(t *testing.T) {
+	finishedAt := time.Now()
+
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Owner: "ana", Title: "Mort", Status: data.StatusFinished, FinishedAt: &finishedAt},
+		"b2": {ID: "b2", Owner: "ana", Title: "Sourcery", Status: data.StatusFinished, FinishedAt: &finishedAt},
+		"b3": {ID: "b3", Owner: "ben", Title: "Emma", Status: data.StatusFinished, FinishedAt: &finishedAt},
+		"b4": {ID: "b4", Title: "Dune", Status: data.StatusFinished, FinishedAt: &finishedAt},
+	}}
+
+	goals := memoryGoals{
+		"g1": {ID: "g1", Owner: "ana", Metric: data.GoalMetricBooks, Year: finishedAt.Year(), Target: 10},
+		"g2": {ID: "g2", Metric: data.GoalMetricBooks, Year: finishedAt.Year(), Target: 10},
+	}
+
+	progress, err := model.GetGoalProgress(goals, books, "g1")
+	if err != nil || progress.Current != 2 {
+		t.Errorf("got %+v and error %v, expected the 2 books of ana counted", progress, err)
+	}
+
+	current, err := model.GetCurrentGoalsProgress(goals, books, " ")
+	if err != nil || len(current) != 1 || current[0].Current != 1 {
+		t.Errorf("got %v and error %v, expected the goal without an owner to count the book nobody owns", current, err)
+	}
+}
+
+func TestCreateGoalValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input GoalInput
+		field string
+	}{
+		{"metric", GoalInput{Metric: "chapters", Year: 2026, Target: 10}, "metric"},
+		{"year", GoalInput{Year: 26, Target: 10}, "year"},
+		{"month", GoalInput{Year: 2026, Month: 13, Target: 10}, "month"},
+		{"target", GoalInput{Year: 2026}, "target"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := model.CreateGoal(nil, tt.input)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, expected a ValidationError for %s", err, tt.field)
+			}
+		})
+	}
+}
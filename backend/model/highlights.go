@@ -0,0 +1,259 @@
+package model
+
+import (
+	"fmt"
+	"hash/fnv"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strings"
+	"time"
+)
+
+/*
+Calls the DB to list the highlights of a book in reading order.
+
+Parameters:
+
+	param1: bookID string
+
+Returns:
+
+	return1: slice of a pointer of highlights
+	return2: error, ErrRecordNotFound when there is no such book
+*/
+func (m *Model) GetBookHighlights(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, bookID string) ([]*data.Highlight, error) {
+	if _, err := books.Get(bookID); err != nil {
+		return nil, err
+	}
+
+	return highlights.GetByBook(bookID)
+}
+
+/*
+Calls the DB to list the highlights across the library, most recent first, with the title of the book each is from.
+Highlights of books in the trash are left out.
+
+Parameters:
+
+	param1: tag string, only highlights with this tag; empty for all of them
+
+Returns:
+
+	return1: slice of a pointer of highlights
+	return2: error
+*/
+func (m *Model) GetHighlights(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, tag string) ([]*data.Highlight, error) {
+	found, err := highlights.GetAll(normaliseTag(tag))
+	if err != nil {
+		return nil, err
+	}
+
+	return fillBookTitles(books, found)
+}
+
+/*
+Picks the highlight of the day among those of books not in the trash. Every call on the same (UTC) day returns
+the same highlight as long as no highlights or books are added or removed, and the pick changes from one day to the next.
+
+Parameters:
+
+	param1: now time.Time
+
+Returns:
+
+	return1: pointer of highlight data, nil when there are no highlights
+	return2: error
+*/
+func (m *Model) HighlightOfTheDay(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, now time.Time) (*data.Highlight, error) {
+	all, err := highlights.GetAll("")
+	if err != nil {
+		return nil, err
+	}
+
+	if all, err = fillBookTitles(books, all); err != nil || len(all) == 0 {
+		return nil, err
+	}
+
+	day := fnv.New32a()
+	day.Write([]byte(now.UTC().Format(time.DateOnly)))
+
+	return all[day.Sum32()%uint32(len(all))], nil
+}
+
+/*
+Calls the DB to find a highlight from a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: id string
+
+Returns:
+
+	return1: pointer of highlight data
+	return2: error, ErrRecordNotFound when the highlight doesn't exist or is from another book
+*/
+func (m *Model) GetHighlight(highlights initialisers.IHighlightCollection, bookID, id string) (*data.Highlight, error) {
+	highlight, err := highlights.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if highlight.BookID != bookID {
+		return nil, fmt.Errorf("%w: the highlight is from another book", initialisers.ErrRecordNotFound)
+	}
+
+	return highlight, nil
+}
+
+/*
+Calls the DB to add a highlight to a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: input HighlightInput
+
+Returns:
+
+	return1: pointer of highlight data
+	return2: error, a *ValidationError when the quote is empty or the page is outside the book
+*/
+func (m *Model) CreateHighlight(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, bookID string, input HighlightInput) (*data.Highlight, error) {
+	book, err := books.Get(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	highlight := &data.Highlight{
+		BookID:   bookID,
+		Quote:    input.Quote,
+		Page:     input.Page,
+		Location: input.Location,
+		Comment:  input.Comment,
+		Tags:     input.Tags,
+		Version:  1,
+	}
+
+	if err := validateHighlight(highlight, book); err != nil {
+		return nil, err
+	}
+
+	if _, err := highlights.Create(highlight); err != nil {
+		return nil, err
+	}
+	return highlight, nil
+}
+
+/*
+Calls the DB to update a highlight from a book.
+
+Parameters:
+
+	param1: pointer of highlight data
+
+Returns:
+
+	return1: error, a *ValidationError when the quote is empty or the page is outside the book
+*/
+func (m *Model) UpdateHighlight(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, highlight *data.Highlight) error {
+	book, err := books.Get(highlight.BookID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateHighlight(highlight, book); err != nil {
+		return err
+	}
+
+	return highlights.Update(highlight)
+}
+
+/*
+Calls the DB to delete a highlight from a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: id string
+
+Returns:
+
+	return1: error, ErrRecordNotFound when the highlight doesn't exist or is from another book
+*/
+func (m *Model) DeleteHighlight(highlights initialisers.IHighlightCollection, bookID, id string) error {
+	if _, err := m.GetHighlight(highlights, bookID, id); err != nil {
+		return err
+	}
+
+	return highlights.Delete(id)
+}
+
+// validateHighlight tidies the text fields and tags of a highlight and checks the page is within the book.
+func validateHighlight(highlight *data.Highlight, book *data.Book) error {
+	highlight.Quote = strings.TrimSpace(highlight.Quote)
+	highlight.Location = strings.TrimSpace(highlight.Location)
+	highlight.Comment = strings.TrimSpace(highlight.Comment)
+
+	if highlight.Quote == "" {
+		return &ValidationError{Field: "quote", Message: "must be provided"}
+	}
+
+	if highlight.Page < 0 {
+		return &ValidationError{Field: "page", Message: "must not be negative"}
+	}
+
+	if book.Pages > 0 && highlight.Page > book.Pages {
+		return &ValidationError{Field: "page", Message: fmt.Sprintf("must not be more than the book's %d pages", book.Pages)}
+	}
+
+	tags := make([]string, 0, len(highlight.Tags))
+	seen := make(map[string]bool, len(highlight.Tags))
+	for _, tag := range highlight.Tags {
+		tag = normaliseTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	highlight.Tags = tags
+
+	return nil
+}
+
+// normaliseTag trims and lowercases a tag so "Love " and "love" are the same tag.
+func normaliseTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+/*
+fillBookTitles sets the BookTitle of each highlight from a single lookup of the books they are from,
+dropping the highlights whose book is in the trash or gone.
+*/
+func fillBookTitles(books initialisers.IBookCollection, highlights []*data.Highlight) ([]*data.Highlight, error) {
+	ids := make([]string, 0, len(highlights))
+	for _, highlight := range highlights {
+		ids = append(ids, highlight.BookID)
+	}
+
+	found, err := books.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[string]string, len(found))
+	for _, book := range found {
+		titles[book.ID] = book.Title
+	}
+
+	live := make([]*data.Highlight, 0, len(highlights))
+	for _, highlight := range highlights {
+		if title, ok := titles[highlight.BookID]; ok {
+			highlight.BookTitle = title
+			live = append(live, highlight)
+		}
+	}
+
+	return live, nil
+}
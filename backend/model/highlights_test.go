@@ -0,0 +1,94 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHighlights(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Dune", Pages: 400},
+		"b2": {ID: "b2", Title: "Emma"},
+	}}
+	highlights := memoryHighlights{}
+
+	var validationErr *ValidationError
+	if _, err := model.CreateHighlight(highlights, books, "b1", HighlightInput{Quote: " "}); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError for an empty quote", err)
+	}
+
+	if _, err := model.CreateHighlight(highlights, books, "b1", HighlightInput{Quote: "Fear", Page: 401}); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError for a page past the end of the book", err)
+	}
+
+	highlight, err := model.CreateHighlight(highlights, books, "b1", HighlightInput{Quote: " I must not fear. ", Page: 8, Tags: []string{"Fear ", "fear", "", "litany"}})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if highlight.Quote != "I must not fear." || !reflect.DeepEqual(highlight.Tags, []string{"fear", "litany"}) {
+		t.Errorf("got quote %q and tags %v, expected them tidied", highlight.Quote, highlight.Tags)
+	}
+
+	if _, err := model.GetHighlight(highlights, "b2", highlight.ID); !errors.Is(err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected ErrRecordNotFound for the highlight of another book", err)
+	}
+
+	if _, err := model.CreateHighlight(highlights, books, "b2", HighlightInput{Quote: "Badly done, Emma!", Location: "ch. 43"}); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	tagged, err := model.GetHighlights(highlights, books, "FEAR")
+	if err != nil || len(tagged) != 1 || tagged[0].BookTitle != "Dune" {
+		t.Errorf("got %v and error %v, expected the one fear highlight with its book title", tagged, err)
+	}
+
+	_ = model.Delete(books, "b1")
+
+	if all, err := model.GetHighlights(highlights, books, ""); err != nil || len(all) != 1 || all[0].BookTitle != "Emma" {
+		t.Errorf("got %v and error %v, expected the highlights of the book in the trash left out", all, err)
+	}
+}
+
+func TestHighlightOfTheDay(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{"b1": {ID: "b1", Title: "Dune"}}}
+	highlights := memoryHighlights{}
+
+	if highlight, err := model.HighlightOfTheDay(highlights, books, time.Now()); highlight != nil || err != nil {
+		t.Errorf("got %v and error %v, expected no highlight when there are none", highlight, err)
+	}
+
+	for _, quote := range []string{"one", "two", "three", "four", "five"} {
+		if _, err := model.CreateHighlight(highlights, books, "b1", HighlightInput{Quote: quote}); err != nil {
+			t.Fatalf("got error %v, expected nil", err)
+		}
+	}
+
+	morning := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	first, _ := model.HighlightOfTheDay(highlights, books, morning)
+	again, _ := model.HighlightOfTheDay(highlights, books, morning.Add(12*time.Hour))
+
+	if first == nil || again == nil || first.ID != again.ID || first.BookTitle != "Dune" {
+		t.Errorf("got %v and %v, expected the same highlight all day", first, again)
+	}
+
+	picked := map[string]bool{}
+	for day := 0; day < 30; day++ {
+		highlight, _ := model.HighlightOfTheDay(highlights, books, morning.AddDate(0, 0, day))
+		picked[highlight.ID] = true
+	}
+
+	if len(picked) < 2 {
+		t.Errorf("got %d different highlights over 30 days, expected the pick to change", len(picked))
+	}
+
+	_ = model.Delete(books, "b1")
+
+	if highlight, err := model.HighlightOfTheDay(highlights, books, morning); highlight != nil || err != nil {
+		t.Errorf("got %v and error %v, expected no highlight when its book is in the trash", highlight, err)
+	}
+}
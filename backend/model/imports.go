@@ -0,0 +1,510 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"readinglistapp/bookcsv"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// What an import did with each row.
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+)
+
+/*
+ImportReport tells what an import did, or would do for a dry run, with every row of the file.
+*/
+type ImportReport struct {
+	DryRun         bool         `json:"dryRun"`
+	Created        int          `json:"created"`
+	Updated        int          `json:"updated"`
+	Skipped        int          `json:"skipped"`
+	Failed         int          `json:"failed"`
+	Shelved        int          `json:"shelved"`
+	ShelvesCreated []string     `json:"shelvesCreated,omitempty"`
+	Rows           []*ImportRow `json:"rows"`
+	Header         []string     `json:"-"`
+}
+
+/*
+ImportRow is the outcome of one row. Reason explains why it was skipped or failed.
+Shelves names the shelves the book was put on. Values keeps the row as read, for the error report.
+*/
+type ImportRow struct {
+	Line    int      `json:"line"`
+	Action  string   `json:"action"`
+	ID      string   `json:"id,omitempty"`
+	Title   string   `json:"title,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+	Shelves []string `json:"shelves,omitempty"`
+	Values  []string `json:"-"`
+}
+
+// Failures returns the rows that could not be imported.
+func (r *ImportReport) Failures() []*ImportRow {
+	failures := []*ImportRow{}
+	for _, row := range r.Rows {
+		if row.Action == ImportFailed {
+			failures = append(failures, row)
+		}
+	}
+
+	return failures
+}
+
+/*
+Calls the DB to go through every book, oldest first, without loading them all at once.
+
+Parameters:
+
+	param1: fn func(book *data.Book) error, called with each book; an error stops the export
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) ExportBooks(db initialisers.IBookCollection, fn func(book *data.Book) error) error {
+	return db.ForEach(fn)
+}
+
+/*
+Imports the books read from a file. A book already in the library, found by ISBN or else by title and
+year published, is updated with the values in the row and skipped when they change nothing. A row
+describing the same book as an earlier row is skipped. Books are put on the shelves their row names,
+creating the shelves that don't exist, unless they are already on them; importing the same file again
+changes nothing. The books are imported for owner, and only the owner's books are looked at for ones already
+in the library, as ISBNs are unique per owner. With dryRun nothing is written, but the report tells what would have happened.
+
+Parameters:
+
+	param1: source bookcsv.RecordReader
+	param2: owner string, empty for the books nobody owns
+	param3: dryRun bool
+
+Returns:
+
+	return1: pointer of the import report
+	return2: error, a *ValidationError when the file is not valid CSV
+*/
+func (m *Model) ImportBooks(db initialisers.IBookCollection, shelves initialisers.IShelfCollection, source bookcsv.RecordReader, owner string, dryRun bool) (*ImportReport, error) {
+	owner = strings.TrimSpace(owner)
+
+	existing, err := db.GetFiltered(data.BookFilter{Owners: []string{owner}})
+	if err != nil {
+		return nil, err
+	}
+
+	existingShelves, err := shelves.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	library := newBookIndex(existing)
+	shelving := newShelfNames(existingShelves)
+	report := &ImportReport{DryRun: dryRun, Rows: []*ImportRow{}, Header: source.Header()}
+
+	for {
+		record, err := source.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, &ValidationError{Field: "file", Message: err.Error()}
+		}
+
+		row := &ImportRow{Line: record.Line, Values: record.Values}
+		report.Rows = append(report.Rows, row)
+
+		if err := importBook(db, library, record, row, owner, dryRun); err != nil {
+			return nil, err
+		}
+
+		switch row.Action {
+		case ImportCreated:
+			report.Created++
+		case ImportUpdated:
+			report.Updated++
+		case ImportSkipped:
+			report.Skipped++
+		case ImportFailed:
+			report.Failed++
+		}
+
+		if row.Action != ImportFailed {
+			shelving.place(row, record.Shelves)
+			report.Shelved += len(row.Shelves)
+		}
+	}
+
+	for _, shelf := range shelving.created {
+		report.ShelvesCreated = append(report.ShelvesCreated, shelf.Name)
+	}
+
+	if !dryRun {
+		if err := shelving.save(shelves); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+/*
+importBook creates or updates the book of one row and records what it did in row.
+Its status is applied as Insert and UpdateProgress do, so created and merged books get the same timestamps and page checks.
+It returns an error only when the import can't go on, such as the database being unavailable.
+*/
+func importBook(db initialisers.IBookCollection, library *bookIndex, record *bookcsv.Record, row *ImportRow, owner string, dryRun bool) error {
+	if record.Err != nil {
+		row.fail(record.Err)
+		return nil
+	}
+
+	book := record.Book
+	book.Owner = owner
+	row.Title = book.Title
+
+	if book.Title == "" {
+		row.fail(&ValidationError{Field: "title", Message: "must be provided"})
+		return nil
+	}
+
+	if err := normaliseISBNs(book); err != nil {
+		row.fail(err)
+		return nil
+	}
+
+	if book.Status != "" && !validStatus(book.Status) {
+		row.fail(statusError(book.Status))
+		return nil
+	}
+
+	match := library.find(book)
+
+	if line, ok := library.lines[match]; ok {
+		row.ID = match.ID
+		row.Action, row.Reason = ImportSkipped, fmt.Sprintf("same book as line %d", line)
+		return nil
+	}
+
+	if match != nil {
+		updated := *match
+		row.ID = match.ID
+
+		if !mergeBook(&updated, book) {
+			library.add(match, row.Line)
+			row.Action, row.Reason = ImportSkipped, "already in the library"
+			return nil
+		}
+
+		if err := normaliseISBNs(&updated); err != nil {
+			row.fail(err)
+			return nil
+		}
+
+		status := updated.Status
+		updated.Status = match.Status
+
+		if err := applyImportedStatus(&updated, status); err != nil {
+			row.fail(err)
+			return nil
+		}
+
+		updated.Version++
+
+		if !dryRun {
+			if err := db.Update(&updated); err != nil {
+				return row.failOnConflict(err)
+			}
+		}
+
+		library.add(&updated, row.Line)
+		row.Action = ImportUpdated
+		return nil
+	}
+
+	status := book.Status
+	if status == "" {
+		status = data.StatusWantToRead
+	}
+	book.Status = ""
+
+	if err := applyImportedStatus(book, status); err != nil {
+		row.fail(err)
+		return nil
+	}
+
+	if !dryRun {
+		if _, err := db.Create(book); err != nil {
+			return row.failOnConflict(err)
+		}
+		row.ID = book.ID
+	}
+
+	library.add(book, row.Line)
+	row.Action = ImportCreated
+	return nil
+}
+
+/*
+applyImportedStatus moves a book to the status of its row with applyStatus, keeping the dates and page the file gives.
+Missing dates are taken from the date the book was finished or abandoned, falling back to now, and a book abandoned
+without having been started is started first. The current page must be within the book's pages.
+*/
+func applyImportedStatus(book *data.Book, status string) error {
+	startedAt, finishedAt, abandonedAt, page := book.StartedAt, book.FinishedAt, book.AbandonedAt, book.CurrentPage
+
+	now := time.Now()
+	if abandonedAt != nil {
+		now = *abandonedAt
+	} else if finishedAt != nil {
+		now = *finishedAt
+	}
+
+	if status == data.StatusAbandoned && book.Status != data.StatusReading && book.Status != data.StatusAbandoned {
+		if err := applyStatus(book, data.StatusReading, now); err != nil {
+			return err
+		}
+	}
+
+	if err := applyStatus(book, status, now); err != nil {
+		return err
+	}
+
+	if startedAt != nil && book.StartedAt != nil {
+		book.StartedAt = startedAt
+	}
+	if finishedAt != nil && book.FinishedAt != nil {
+		book.FinishedAt = finishedAt
+	}
+	if abandonedAt != nil && book.AbandonedAt != nil {
+		book.AbandonedAt = abandonedAt
+	}
+	if page != 0 && (status == data.StatusReading || status == data.StatusAbandoned) {
+		book.CurrentPage = page
+	}
+
+	if book.CurrentPage < 0 || (book.Pages > 0 && book.CurrentPage > book.Pages) {
+		return &ValidationError{Field: "currentPage", Message: fmt.Sprintf("must be between 0 and %d", book.Pages)}
+	}
+
+	return nil
+}
+
+func (row *ImportRow) fail(err error) {
+	row.Action, row.Reason = ImportFailed, err.Error()
+}
+
+// failOnConflict fails the row when its ISBN belongs to another book and returns any other error.
+func (row *ImportRow) failOnConflict(err error) error {
+	if errors.Is(err, initialisers.ErrDuplicateRecord) {
+		row.Action, row.Reason = ImportFailed, "another book has this ISBN"
+		return nil
+	}
+
+	return err
+}
+
+/*
+bookIndex finds the books of the library, and those imported so far, by ISBN-13 and by title and year published.
+lines records the line of the file each book was imported from.
+*/
+type bookIndex struct {
+	byISBN  map[string]*data.Book
+	byTitle map[string]*data.Book
+	lines   map[*data.Book]int
+}
+
+func newBookIndex(books []*data.Book) *bookIndex {
+	index := &bookIndex{
+		byISBN:  make(map[string]*data.Book, len(books)),
+		byTitle: make(map[string]*data.Book, len(books)),
+		lines:   make(map[*data.Book]int),
+	}
+
+	for _, book := range books {
+		index.put(book)
+	}
+
+	return index
+}
+
+func (i *bookIndex) find(book *data.Book) *data.Book {
+	if book.ISBN13 != "" {
+		if match, ok := i.byISBN[book.ISBN13]; ok {
+			return match
+		}
+	}
+
+	return i.byTitle[titleKey(book)]
+}
+
+func (i *bookIndex) add(book *data.Book, line int) {
+	i.put(book)
+	i.lines[book] = line
+}
+
+func (i *bookIndex) put(book *data.Book) {
+	if book.ISBN13 != "" {
+		i.byISBN[book.ISBN13] = book
+	}
+
+	i.byTitle[titleKey(book)] = book
+}
+
+func titleKey(book *data.Book) string {
+	return strings.ToLower(strings.TrimSpace(book.Title)) + "\x00" + strconv.Itoa(book.Published)
+}
+
+/*
+mergeBook copies the values present in src onto dst and reports whether anything changed.
+Like a partial update, new author names unlink the authors and a new series unlinks the series.
+*/
+func mergeBook(dst, src *data.Book) bool {
+	changed := false
+
+	text := func(field *string, value string) {
+		if value != "" && *field != value {
+			*field, changed = value, true
+		}
+	}
+	number := func(field *int, value int) {
+		if value != 0 && *field != value {
+			*field, changed = value, true
+		}
+	}
+	decimal := func(field *float64, value float64) {
+		if value != 0 && *field != value {
+			*field, changed = value, true
+		}
+	}
+	list := func(field *[]string, value []string) {
+		if len(value) > 0 && strings.Join(*field, "\x00") != strings.Join(value, "\x00") {
+			*field, changed = value, true
+		}
+	}
+	date := func(field **time.Time, value *time.Time) {
+		if value != nil && (*field == nil || !(*field).Equal(*value)) {
+			*field, changed = value, true
+		}
+	}
+
+	authors, series := strings.Join(dst.Authors, "\x00"), dst.Series
+
+	text(&dst.Title, src.Title)
+	list(&dst.Authors, src.Authors)
+	// The ISBNs go together: an ISBN-13 starting with 979 has no ISBN-10, which must not be left over from before
+	if src.ISBN13 != "" && dst.ISBN13 != src.ISBN13 {
+		dst.ISBN10, dst.ISBN13, changed = src.ISBN10, src.ISBN13, true
+	}
+	text(&dst.Publisher, src.Publisher)
+	text(&dst.Language, src.Language)
+	text(&dst.Description, src.Description)
+	text(&dst.Edition, src.Edition)
+	text(&dst.Series, src.Series)
+	decimal(&dst.SeriesPosition, src.SeriesPosition)
+	number(&dst.Published, src.Published)
+	number(&dst.Pages, src.Pages)
+	list(&dst.Genres, src.Genres)
+	decimal(&dst.Rating, src.Rating)
+	text(&dst.Status, src.Status)
+	number(&dst.CurrentPage, src.CurrentPage)
+	date(&dst.StartedAt, src.StartedAt)
+	date(&dst.FinishedAt, src.FinishedAt)
+	date(&dst.AbandonedAt, src.AbandonedAt)
+
+	if strings.Join(dst.Authors, "\x00") != authors {
+		dst.AuthorIDs = nil
+	}
+
+	if dst.Series != series {
+		dst.SeriesID = ""
+	}
+
+	return changed
+}
+
+/*
+shelfNames finds shelves by name, ignoring case, and keeps track of those an import created or changed.
+*/
+type shelfNames struct {
+	byName  map[string]*data.Shelf
+	created []*data.Shelf
+	changed []*data.Shelf
+}
+
+func newShelfNames(shelves []*data.Shelf) *shelfNames {
+	index := &shelfNames{byName: make(map[string]*data.Shelf, len(shelves))}
+	for _, shelf := range shelves {
+		index.byName[strings.ToLower(shelf.Name)] = shelf
+	}
+
+	return index
+}
+
+// place puts the book of a row at the end of the shelves it names, unless it is already on them.
+func (i *shelfNames) place(row *ImportRow, names []string) {
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+
+		shelf, ok := i.byName[key]
+		if !ok {
+			shelf = &data.Shelf{Name: strings.TrimSpace(name), BookIDs: []string{}, Version: 1}
+			i.byName[key] = shelf
+			i.created = append(i.created, shelf)
+		}
+
+		// A dry run has no ID for the books it would create, and they can't be on a shelf yet
+		if row.ID != "" {
+			if shelfIndex(shelf, row.ID) >= 0 {
+				continue
+			}
+			shelf.BookIDs = append(shelf.BookIDs, row.ID)
+		}
+
+		if ok && !containsShelf(i.changed, shelf) {
+			i.changed = append(i.changed, shelf)
+		}
+		row.Shelves = append(row.Shelves, shelf.Name)
+	}
+}
+
+// save creates the new shelves and updates the changed ones.
+func (i *shelfNames) save(shelves initialisers.IShelfCollection) error {
+	for _, shelf := range i.created {
+		if _, err := shelves.Create(shelf); err != nil {
+			return err
+		}
+	}
+
+	for _, shelf := range i.changed {
+		shelf.Version++
+		if err := shelves.Update(shelf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func containsShelf(shelves []*data.Shelf, shelf *data.Shelf) bool {
+	for _, s := range shelves {
+		if s == shelf {
+			return true
+		}
+	}
+
+	return false
+}
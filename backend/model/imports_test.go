@@ -0,0 +1,218 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/bookcsv"
+	"readinglistapp/internal/data"
+	"strings"
+	"testing"
+)
+
+const importFile = `title,authors,isbn13,published,rating,status
+Dune,Frank Herbert,978-0-441-17271-9,1965,5,finished
+Emma,Jane Austen,,1815,4,
+Persuasion,Jane Austen,,1817,,
+Dune,Frank Herbert,9780441172719,1965,,
+,Nobody,,,,
+Middlemarch,George Eliot,123,1871,,
+Ulysses,James Joyce,,1922,lots,
+`
+
+func readImport(t *testing.T) bookcsv.RecordReader {
+	t.Helper()
+
+	reader, err := bookcsv.NewReader(strings.NewReader(importFile), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return reader
+}
+
+func newImportLibrary() memoryBooks {
+	return memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma", Authors: []string{"Jane Austen"}, AuthorIDs: []string{"a1"}, Published: 1815, Rating: 3, Status: data.StatusReading},
+		"b2": {ID: "b2", Title: "Persuasion", Authors: []string{"Jane Austen"}, Published: 1817, Status: data.StatusWantToRead},
+	}}
+}
+
+func TestImportBooks(t *testing.T) {
+	books := newImportLibrary()
+
+	report, err := model.ImportBooks(books, memoryShelves{}, readImport(t), "", false)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	expected := []string{ImportCreated, ImportUpdated, ImportSkipped, ImportSkipped, ImportFailed, ImportFailed, ImportFailed}
+	for i, row := range report.Rows {
+		if row.Action != expected[i] {
+			t.Errorf("got %s for line %d (%s), expected %s", row.Action, row.Line, row.Reason, expected[i])
+		}
+	}
+
+	if report.Created != 1 || report.Updated != 1 || report.Skipped != 2 || report.Failed != 3 {
+		t.Errorf("got %+v, expected the counts to match the rows", report)
+	}
+
+	if len(books.books) != 3 {
+		t.Errorf("got %d books, expected Dune added once", len(books.books))
+	}
+
+	dune := books.books[report.Rows[0].ID]
+	if dune == nil || dune.ISBN13 != "9780441172719" || dune.Status != data.StatusFinished {
+		t.Errorf("got %+v, expected Dune created with its ISBN and status", dune)
+	}
+
+	if emma := books.books["b1"]; emma.Rating != 4 || emma.Status != data.StatusReading || len(emma.AuthorIDs) != 1 {
+		t.Errorf("got %+v, expected only the rating changed", emma)
+	}
+
+	if got := report.Rows[3].Reason; got != "same book as line 2" {
+		t.Errorf("got reason %q, expected the second Dune to be found by ISBN", got)
+	}
+
+	if failures := report.Failures(); len(failures) != 3 || failures[0].Values[1] != "Nobody" {
+		t.Errorf("got %+v, expected the failed rows with their values", failures)
+	}
+}
+
+func TestImportBooksAppliesStatus(t *testing.T) {
+	const file = `title,published,pages,status,current_page,finished_at
+Dune,1965,604,finished,,2024-03-09T00:00:00Z
+Emma,1815,474,reading,120,
+Persuasion,1817,249,reading,300,
+`
+	books := newImportLibrary()
+
+	reader, err := bookcsv.NewReader(strings.NewReader(file), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := model.ImportBooks(books, memoryShelves{}, reader, "", false)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	dune := books.books[report.Rows[0].ID]
+	if dune == nil || dune.StartedAt == nil || dune.FinishedAt == nil || dune.FinishedAt.Year() != 2024 || dune.CurrentPage != 604 {
+		t.Errorf("got %+v, expected Dune finished on the date given, with its start date and last page set", dune)
+	}
+
+	if emma := books.books["b1"]; report.Rows[1].Action != ImportUpdated || emma.CurrentPage != 120 || emma.Pages != 474 {
+		t.Errorf("got %+v, expected Emma's progress merged", emma)
+	}
+
+	if row := report.Rows[2]; row.Action != ImportFailed || books.books["b2"].Status != data.StatusWantToRead {
+		t.Errorf("got %s (%s), expected a current page past the last one to fail", row.Action, row.Reason)
+	}
+}
+
+func TestImportBooksForOwner(t *testing.T) {
+	books := newImportLibrary()
+	books.books["b1"].ISBN13 = "9780306406157"
+
+	reader, err := bookcsv.NewReader(strings.NewReader("title,published,isbn13\nEmma,1815,978-0-306-40615-7\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := model.ImportBooks(books, memoryShelves{}, reader, " ana ", false)
+	if err != nil || report.Created != 1 {
+		t.Fatalf("got %+v and error %v, expected Emma created for ana rather than merged into the book nobody owns", report, err)
+	}
+
+	if emma := books.books[report.Rows[0].ID]; emma.Owner != "ana" || emma.ISBN13 != "9780306406157" {
+		t.Errorf("got %+v, expected ana's Emma with the same ISBN", emma)
+	}
+}
+
+func TestImportBooksReplacesISBN(t *testing.T) {
+	books := newImportLibrary()
+	books.books["b1"].ISBN10, books.books["b1"].ISBN13 = "0306406152", "9780306406157"
+
+	reader, err := bookcsv.NewReader(strings.NewReader("title,published,isbn13\nEmma,1815,979-10-90636-07-1\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := model.ImportBooks(books, memoryShelves{}, reader, "", false)
+	if err != nil || report.Updated != 1 {
+		t.Fatalf("got %+v and error %v, expected Emma updated", report, err)
+	}
+
+	if emma := books.books["b1"]; emma.ISBN13 != "9791090636071" || emma.ISBN10 != "" {
+		t.Errorf("got ISBNs %q and %q, expected the new ISBN-13 without the old ISBN-10", emma.ISBN13, emma.ISBN10)
+	}
+}
+
+func TestImportBooksDryRun(t *testing.T) {
+	books := newImportLibrary()
+
+	report, err := model.ImportBooks(books, memoryShelves{}, readImport(t), "", true)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if !report.DryRun || report.Created != 1 || report.Updated != 1 {
+		t.Errorf("got %+v, expected the same outcome as a real import", report)
+	}
+
+	if len(books.books) != 2 || books.books["b1"].Rating != 3 {
+		t.Error("expected a dry run to change nothing")
+	}
+}
+
+func TestImportBooksInvalidCSV(t *testing.T) {
+	reader, err := bookcsv.NewReader(strings.NewReader("title\n\"Emma\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var validationErr *ValidationError
+	if _, err := model.ImportBooks(newImportLibrary(), memoryShelves{}, reader, "", false); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError", err)
+	}
+}
+
+func TestImportBooksGoodreadsTwice(t *testing.T) {
+	const export = `Book Id,Title,Author,Additional Authors,ISBN,ISBN13,My Rating,Number of Pages,Year Published,Date Read,Bookshelves,Exclusive Shelf
+1,"Dune (Dune, #1)",Frank Herbert,,"=""0441172717""","=""9780441172719""",5,604,1965,2024/03/09,"favorites, read",read
+2,Emma,Jane Austen,,"=""""","=""""",4,474,1815,,"Favorites, classics, to-read",to-read
+`
+	books := newImportLibrary()
+	shelves := memoryShelves{"s1": {ID: "s1", Name: "Classics", BookIDs: []string{}, Version: 1}}
+
+	importExport := func() *ImportReport {
+		reader, err := bookcsv.NewServiceReader(strings.NewReader(export), bookcsv.Goodreads)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := model.ImportBooks(books, shelves, reader, "", false)
+		if err != nil {
+			t.Fatalf("got error %v, expected nil", err)
+		}
+
+		return report
+	}
+
+	first := importExport()
+	if first.Created != 1 || first.Updated != 1 || first.Shelved != 3 || len(first.ShelvesCreated) != 1 || first.ShelvesCreated[0] != "favorites" {
+		t.Errorf("got %+v, expected Dune created, Emma updated and one shelf created", first)
+	}
+
+	if len(shelves) != 2 || len(shelves["s1"].BookIDs) != 1 || len(shelves["s2"].BookIDs) != 2 {
+		t.Errorf("got %+v, expected both books on favorites and Emma on Classics", shelves)
+	}
+
+	second := importExport()
+	if second.Skipped != 2 || second.Shelved != 0 || len(second.ShelvesCreated) != 0 {
+		t.Errorf("got %+v, expected importing the same export again to change nothing", second)
+	}
+
+	if len(books.books) != 3 || len(shelves) != 2 || len(shelves["s2"].BookIDs) != 2 {
+		t.Error("expected no books, shelves or placements added by the second import")
+	}
+}
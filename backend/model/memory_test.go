@@ -0,0 +1,607 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// memoryAuthors, memoryBooks, memoryGoals, memorySeries, memorySessions, memoryShelves, memoryReviews, memoryNotes, memoryHighlights, memoryTags and memoryUsers keep records in maps so changes spanning collections can be checked end to end.
+// memoryTransactor runs transactions over memoryBooks, restoring them when the transaction fails.
+// memoryDocuments keeps raw documents by collection for backups.
+type memoryAuthors map[string]*data.Author
+
+func (m memoryAuthors) Create(author *data.Author) (interface{}, error) {
+	m[author.ID] = author
+	return author.ID, nil
+}
+func (m memoryAuthors) Delete(id string) error { delete(m, id); return nil }
+func (m memoryAuthors) Get(id string) (*data.Author, error) {
+	if author, ok := m[id]; ok {
+		copied := *author
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryAuthors) GetAll() ([]*data.Author, error) { return nil, nil }
+func (m memoryAuthors) Update(author *data.Author) error {
+	m[author.ID] = author
+	return nil
+}
+
+type memoryBooks struct {
+	initialisers.IBookCollection
+	books map[string]*data.Book
+}
+
+func (m memoryBooks) GetByAuthor(authorID string) ([]*data.Book, error) {
+	var result []*data.Book
+	for _, book := range m.books {
+		if book.DeletedAt != nil {
+			continue
+		}
+		for _, id := range book.AuthorIDs {
+			if id == authorID {
+				copied := *book
+				result = append(result, &copied)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+func (m memoryBooks) GetBySeries(seriesID string) ([]*data.Book, error) {
+	var result []*data.Book
+	for _, book := range m.books {
+		if book.SeriesID == seriesID && book.DeletedAt == nil {
+			copied := *book
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+func (m memoryBooks) GetFiltered(filter data.BookFilter) ([]*data.Book, error) {
+	var result []*data.Book
+	for _, book := range m.books {
+		if book.DeletedAt != nil && !filter.IncludeDeleted {
+			continue
+		}
+		if len(filter.Owners) > 0 && !contains(filter.Owners, book.Owner) {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !contains(filter.Statuses, book.Status) {
+			continue
+		}
+		if len(filter.Tags) > 0 && !containsAny(filter.Tags, book.Tags) {
+			continue
+		}
+		if len(filter.AuthorIDs) > 0 && !containsAny(filter.AuthorIDs, book.AuthorIDs) {
+			continue
+		}
+		copied := *book
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+func (m memoryBooks) GetAll() ([]*data.Book, error) {
+	return m.GetFiltered(data.BookFilter{})
+}
+func (m memoryBooks) GetByIDs(ids []string) ([]*data.Book, error) {
+	var result []*data.Book
+	for _, id := range ids {
+		if book, ok := m.books[id]; ok && book.DeletedAt == nil {
+			copied := *book
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+func (m memoryBooks) Get(id string) (*data.Book, error) {
+	if book, ok := m.books[id]; ok && book.DeletedAt == nil {
+		copied := *book
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryBooks) GetByISBN(owner, isbn13 string) (*data.Book, error) {
+	for _, book := range m.books {
+		if book.Owner == owner && book.ISBN13 == isbn13 && book.DeletedAt == nil {
+			copied := *book
+			return &copied, nil
+		}
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryBooks) GetDeleted() ([]*data.Book, error) {
+	var result []*data.Book
+	for _, book := range m.books {
+		if book.DeletedAt != nil {
+			copied := *book
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DeletedAt.After(*result[j].DeletedAt) })
+	return result, nil
+}
+func (m memoryBooks) Update(book *data.Book) error {
+	m.books[book.ID] = book
+	return nil
+}
+func (m memoryBooks) Create(book *data.Book) (interface{}, error) {
+	if m.isbnTaken(book) {
+		return nil, initialisers.ErrDuplicateRecord
+	}
+	for i := len(m.books) + 1; book.ID == ""; i++ {
+		if _, ok := m.books[fmt.Sprintf("b%d", i)]; !ok {
+			book.ID = fmt.Sprintf("b%d", i)
+		}
+	}
+	m.books[book.ID] = book
+	return book.ID, nil
+}
+func (m memoryBooks) Delete(id string) error {
+	if book, ok := m.books[id]; ok && book.DeletedAt == nil {
+		copied, now := *book, time.Now()
+		copied.DeletedAt = &now
+		m.books[id] = &copied
+	}
+	return nil
+}
+func (m memoryBooks) Restore(id string) error {
+	book, ok := m.books[id]
+	if !ok || book.DeletedAt == nil {
+		return initialisers.ErrRecordNotFound
+	}
+	if m.isbnTaken(book) {
+		return initialisers.ErrDuplicateRecord
+	}
+	copied := *book
+	copied.DeletedAt = nil
+	m.books[id] = &copied
+	return nil
+}
+
+// isbnTaken reports whether another book of the same owner that is not in the trash has the ISBN of book, as the unique index does.
+func (m memoryBooks) isbnTaken(book *data.Book) bool {
+	for id, existing := range m.books {
+		if id != book.ID && book.ISBN13 != "" && existing.ISBN13 == book.ISBN13 && existing.Owner == book.Owner && existing.DeletedAt == nil {
+			return true
+		}
+	}
+	return false
+}
+func (m memoryBooks) Purge(id string) error {
+	if book, ok := m.books[id]; !ok || book.DeletedAt == nil {
+		return initialisers.ErrRecordNotFound
+	}
+	delete(m.books, id)
+	return nil
+}
+func (m memoryBooks) ForEach(fn func(book *data.Book) error) error {
+	books, _ := m.GetAll()
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	for _, book := range books {
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memoryTransactor struct {
+	books memoryBooks
+}
+
+func (m memoryTransactor) BookTransaction(fn func(books initialisers.IBookCollection) error) error {
+	saved := make(map[string]*data.Book, len(m.books.books))
+	for id, book := range m.books.books {
+		saved[id] = book
+	}
+
+	if err := fn(m.books); err != nil {
+		for id := range m.books.books {
+			delete(m.books.books, id)
+		}
+		for id, book := range saved {
+			m.books.books[id] = book
+		}
+		return err
+	}
+	return nil
+}
+
+// unsupportedTransactor stands in for a standalone server, which can't run transactions.
+type unsupportedTransactor struct{}
+
+func (unsupportedTransactor) BookTransaction(fn func(books initialisers.IBookCollection) error) error {
+	return initialisers.ErrTransactionsUnsupported
+}
+
+// failedTransactor stands in for a transaction that is aborted, leaving the books as they were.
+type failedTransactor struct{}
+
+func (failedTransactor) BookTransaction(fn func(books initialisers.IBookCollection) error) error {
+	return errors.New("transaction aborted")
+}
+
+type memoryGoals map[string]*data.Goal
+
+func (m memoryGoals) Create(goal *data.Goal) (interface{}, error) {
+	m[goal.ID] = goal
+	return goal.ID, nil
+}
+func (m memoryGoals) Delete(id string) error { delete(m, id); return nil }
+func (m memoryGoals) Get(id string) (*data.Goal, error) {
+	if goal, ok := m[id]; ok {
+		copied := *goal
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryGoals) GetByOwner(owner string) ([]*data.Goal, error) {
+	var result []*data.Goal
+	for _, goal := range m {
+		if goal.Owner == owner {
+			result = append(result, goal)
+		}
+	}
+	return result, nil
+}
+func (m memoryGoals) Update(goal *data.Goal) error {
+	m[goal.ID] = goal
+	return nil
+}
+
+type memoryUsers map[string]*data.User
+
+func (m memoryUsers) Create(user *data.User) (interface{}, error) {
+	for _, existing := range m {
+		if existing.Name == user.Name {
+			return nil, initialisers.ErrDuplicateRecord
+		}
+	}
+	user.ID = fmt.Sprintf("u%d", len(m)+1)
+	m[user.ID] = user
+	return user.ID, nil
+}
+func (m memoryUsers) GetAll() ([]*data.User, error) {
+	var result []*data.User
+	for _, user := range m {
+		result = append(result, user)
+	}
+	return result, nil
+}
+
+type memorySeries map[string]*data.Series
+
+func (m memorySeries) Create(series *data.Series) (interface{}, error) {
+	m[series.ID] = series
+	return series.ID, nil
+}
+func (m memorySeries) Delete(id string) error { delete(m, id); return nil }
+func (m memorySeries) Get(id string) (*data.Series, error) {
+	if series, ok := m[id]; ok {
+		copied := *series
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memorySeries) GetAll() ([]*data.Series, error) { return nil, nil }
+func (m memorySeries) Update(series *data.Series) error {
+	m[series.ID] = series
+	return nil
+}
+
+type memorySessions map[string]*data.ReadingSession
+
+func (m memorySessions) Create(session *data.ReadingSession) (interface{}, error) {
+	session.ID = fmt.Sprintf("s%d", len(m)+1)
+	m[session.ID] = session
+	return session.ID, nil
+}
+func (m memorySessions) GetByBook(bookID string) ([]*data.ReadingSession, error) {
+	var result []*data.ReadingSession
+	for _, session := range m {
+		if session.BookID == bookID {
+			copied := *session
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+func (m memorySessions) DeleteByBook(bookID string) error {
+	for id, session := range m {
+		if session.BookID == bookID {
+			delete(m, id)
+		}
+	}
+	return nil
+}
+func (m memorySessions) GetBetween(from, to time.Time) ([]*data.ReadingSession, error) {
+	var result []*data.ReadingSession
+	for _, session := range m {
+		if !session.StartedAt.Before(from) && session.StartedAt.Before(to) {
+			copied := *session
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+func (m memorySessions) GetOpen(bookID string) (*data.ReadingSession, error) {
+	for _, session := range m {
+		if session.BookID == bookID && session.EndedAt == nil {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memorySessions) Update(session *data.ReadingSession) error {
+	m[session.ID] = session
+	return nil
+}
+
+type memoryShelves map[string]*data.Shelf
+
+func (m memoryShelves) Create(shelf *data.Shelf) (interface{}, error) {
+	if shelf.ID == "" {
+		shelf.ID = fmt.Sprintf("s%d", len(m)+1)
+	}
+	copied := *shelf
+	m[shelf.ID] = &copied
+	return shelf.ID, nil
+}
+func (m memoryShelves) Delete(id string) error { delete(m, id); return nil }
+func (m memoryShelves) Get(id string) (*data.Shelf, error) {
+	if shelf, ok := m[id]; ok {
+		copied := *shelf
+		copied.BookIDs = append([]string(nil), shelf.BookIDs...)
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryShelves) GetAll() ([]*data.Shelf, error) {
+	var result []*data.Shelf
+	for id := range m {
+		shelf, _ := m.Get(id)
+		result = append(result, shelf)
+	}
+	return result, nil
+}
+func (m memoryShelves) GetByBook(bookID string) ([]*data.Shelf, error) {
+	var result []*data.Shelf
+	for id, shelf := range m {
+		if shelfIndex(shelf, bookID) >= 0 {
+			copied, _ := m.Get(id)
+			result = append(result, copied)
+		}
+	}
+	return result, nil
+}
+func (m memoryShelves) Update(shelf *data.Shelf) error {
+	stored, ok := m[shelf.ID]
+	if !ok {
+		return initialisers.ErrRecordNotFound
+	}
+	if stored.Version != shelf.Version-1 {
+		return initialisers.ErrEditConflict
+	}
+	copied := *shelf
+	m[shelf.ID] = &copied
+	return nil
+}
+
+type memoryReviews map[string]*data.Review
+
+func (m memoryReviews) Create(review *data.Review) (interface{}, error) {
+	review.ID = fmt.Sprintf("r%d", len(m)+1)
+	m[review.ID] = review
+	return review.ID, nil
+}
+func (m memoryReviews) Delete(id string) error { delete(m, id); return nil }
+func (m memoryReviews) DeleteByBook(bookID string) error {
+	for id, review := range m {
+		if review.BookID == bookID {
+			delete(m, id)
+		}
+	}
+	return nil
+}
+func (m memoryReviews) Get(id string) (*data.Review, error) {
+	if review, ok := m[id]; ok {
+		copied := *review
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryReviews) GetByBook(bookID string) ([]*data.Review, error) { return nil, nil }
+func (m memoryReviews) Update(review *data.Review) error {
+	m[review.ID] = review
+	return nil
+}
+
+type memoryHighlights map[string]*data.Highlight
+
+func (m memoryHighlights) Create(highlight *data.Highlight) (interface{}, error) {
+	highlight.ID = fmt.Sprintf("h%d", len(m)+1)
+	m[highlight.ID] = highlight
+	return highlight.ID, nil
+}
+func (m memoryHighlights) Delete(id string) error { delete(m, id); return nil }
+func (m memoryHighlights) DeleteByBook(bookID string) error {
+	for id, highlight := range m {
+		if highlight.BookID == bookID {
+			delete(m, id)
+		}
+	}
+	return nil
+}
+func (m memoryHighlights) Get(id string) (*data.Highlight, error) {
+	if highlight, ok := m[id]; ok {
+		copied := *highlight
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryHighlights) GetAll(tag string) ([]*data.Highlight, error) {
+	var result []*data.Highlight
+	for _, highlight := range m {
+		for _, t := range highlight.Tags {
+			if t == tag {
+				copied := *highlight
+				result = append(result, &copied)
+				break
+			}
+		}
+		if tag == "" {
+			copied := *highlight
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+func (m memoryHighlights) GetByBook(bookID string) ([]*data.Highlight, error) { return nil, nil }
+func (m memoryHighlights) Update(highlight *data.Highlight) error {
+	m[highlight.ID] = highlight
+	return nil
+}
+
+type memoryNotes map[string]*data.Note
+
+func (m memoryNotes) Create(note *data.Note) (interface{}, error) {
+	note.ID = fmt.Sprintf("n%d", len(m)+1)
+	m[note.ID] = note
+	return note.ID, nil
+}
+func (m memoryNotes) Delete(id string) error { delete(m, id); return nil }
+func (m memoryNotes) DeleteByBook(bookID string) error {
+	for id, note := range m {
+		if note.BookID == bookID {
+			delete(m, id)
+		}
+	}
+	return nil
+}
+func (m memoryNotes) Get(id string) (*data.Note, error) {
+	if note, ok := m[id]; ok {
+		copied := *note
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryNotes) GetByBook(bookID string) ([]*data.Note, error) { return nil, nil }
+func (m memoryNotes) Search(text string) ([]*data.Note, error) {
+	var result []*data.Note
+	for _, note := range m {
+		if contains(strings.Fields(note.Body), text) {
+			copied := *note
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+func (m memoryNotes) Update(note *data.Note) error {
+	m[note.ID] = note
+	return nil
+}
+
+type memoryTags map[string]*data.Tag
+
+func (m memoryTags) Create(tag *data.Tag) (interface{}, error) {
+	for _, existing := range m {
+		if existing.Name == tag.Name {
+			return nil, initialisers.ErrDuplicateRecord
+		}
+	}
+	tag.ID = fmt.Sprintf("t%d", len(m)+1)
+	m[tag.ID] = tag
+	return tag.ID, nil
+}
+func (m memoryTags) Delete(id string) error { delete(m, id); return nil }
+func (m memoryTags) Get(id string) (*data.Tag, error) {
+	if tag, ok := m[id]; ok {
+		copied := *tag
+		return &copied, nil
+	}
+	return nil, initialisers.ErrRecordNotFound
+}
+func (m memoryTags) GetAll() ([]*data.Tag, error) {
+	var result []*data.Tag
+	for _, tag := range m {
+		copied := *tag
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+func (m memoryTags) GetByPrefix(prefix string, limit int64) ([]*data.Tag, error) {
+	all, _ := m.GetAll()
+	var result []*data.Tag
+	for _, tag := range all {
+		if strings.HasPrefix(tag.Name, prefix) && int64(len(result)) < limit {
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+func (m memoryTags) Update(tag *data.Tag) error {
+	copied := *tag
+	m[tag.ID] = &copied
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, candidate := range candidates {
+		if contains(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+type memoryDocuments map[string][]bson.Raw
+
+func (m memoryDocuments) ForEachDocument(collection string, fn func(doc bson.Raw) error) error {
+	for _, doc := range m[collection] {
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (m memoryDocuments) RestoreDocuments(collection string, docs []bson.Raw, replace bool) (int, int, int, error) {
+	deleted, skipped := 0, 0
+	if replace {
+		deleted = len(m[collection])
+		m[collection] = nil
+	}
+	ids := make(map[string]bool)
+	for _, doc := range m[collection] {
+		ids[doc.Lookup("_id").String()] = true
+	}
+	for _, doc := range docs {
+		if ids[doc.Lookup("_id").String()] {
+			skipped++
+			continue
+		}
+		m[collection] = append(m[collection], doc)
+	}
+	return len(docs) - skipped, deleted, skipped, nil
+}
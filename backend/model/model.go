@@ -1,8 +1,15 @@
 package model
 
 import (
+	"fmt"
+	"io"
+	"readinglistapp/backup"
+	"readinglistapp/bookcsv"
 	"readinglistapp/initialisers"
 	"readinglistapp/internal/data"
+	"readinglistapp/isbn"
+	"strings"
+	"time"
 )
 
 type IModelNew interface {
@@ -13,8 +20,99 @@ type IModelFuncs interface {
 	Delete(db initialisers.IBookCollection, id string) error
 	Get(db initialisers.IBookCollection, id string) (*data.Book, error)
 	GetAll(db initialisers.IBookCollection) ([]*data.Book, error)
+	GetByISBN(db initialisers.IBookCollection, owner, isbn string) (*data.Book, error)
+	GetFiltered(db initialisers.IBookCollection, filter data.BookFilter) ([]*data.Book, error)
+	GetStats(db initialisers.IBookCollection) (*data.LibraryStats, error)
+	GetTrash(db initialisers.IBookCollection) ([]*data.Book, error)
 	Insert(db initialisers.IBookCollection, input Input) (interface{}, *data.Book, error)
+	PurgeBook(db initialisers.IBookCollection, dependents BookDependents, id string) error
+	PurgeTrash(db initialisers.IBookCollection, dependents BookDependents, retention time.Duration, now time.Time) (int64, error)
+	RestoreBook(db initialisers.IBookCollection, id string) (*data.Book, error)
 	Update(db initialisers.IBookCollection, id string, data *data.Book) error
+	UpdateProgress(db initialisers.IBookCollection, id string, input ProgressInput) (*data.Book, error)
+
+	ApplyUpdate(authors initialisers.IAuthorCollection, book *data.Book, input UpdateInput) error
+	Backup(books initialisers.IBookCollection, documents initialisers.IDocumentStore, w io.Writer) (*backup.Manifest, error)
+	Batch(books initialisers.IBookCollection, authors initialisers.IAuthorCollection, transactor initialisers.ITransactor, input BatchInput) ([]*BatchResult, error)
+	ExportBooks(db initialisers.IBookCollection, fn func(book *data.Book) error) error
+	ImportBooks(db initialisers.IBookCollection, shelves initialisers.IShelfCollection, source bookcsv.RecordReader, owner string, dryRun bool) (*ImportReport, error)
+	Restore(books initialisers.IBookCollection, documents initialisers.IDocumentStore, transactor initialisers.ITransactor, r io.Reader, mode string) (*RestoreReport, error)
+
+	CreateAuthor(db initialisers.IAuthorCollection, input AuthorInput) (interface{}, *data.Author, error)
+	DeleteAuthor(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, id string) error
+	GetAuthor(db initialisers.IAuthorCollection, id string) (*data.Author, error)
+	GetAuthorBooks(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, id string) ([]*data.Book, error)
+	GetAuthors(db initialisers.IAuthorCollection) ([]*data.Author, error)
+	MergeAuthors(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, targetID string, sourceIDs []string) (*data.Author, error)
+	ResolveAuthors(db initialisers.IAuthorCollection, ids []string) ([]string, error)
+	UpdateAuthor(authors initialisers.IAuthorCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, author *data.Author) error
+
+	CreateSeries(db initialisers.ISeriesCollection, input SeriesInput) (interface{}, *data.Series, error)
+	DeleteSeries(series initialisers.ISeriesCollection, books initialisers.IBookCollection, id string) error
+	GetAllSeries(db initialisers.ISeriesCollection) ([]*data.Series, error)
+	GetSeries(series initialisers.ISeriesCollection, books initialisers.IBookCollection, id string) (*data.Series, error)
+	NextUnread(series initialisers.ISeriesCollection, books initialisers.IBookCollection, id string) (*data.Book, error)
+	RemoveSeriesEntry(books initialisers.IBookCollection, seriesID, bookID string) error
+	SetSeriesEntry(series initialisers.ISeriesCollection, books initialisers.IBookCollection, seriesID, bookID string, position float64) (*data.Book, error)
+	UpdateSeries(series initialisers.ISeriesCollection, books initialisers.IBookCollection, s *data.Series) error
+
+	GetBookSessions(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, bookID string) ([]*data.ReadingSession, *data.ReadingStats, error)
+	LogSession(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, bookID string, input ReadingSessionInput) (*data.ReadingSession, error)
+	ReadingStats(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, from, to time.Time) (*data.ReadingStats, error)
+	StartSession(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, bookID string) (*data.ReadingSession, error)
+	StopSession(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, bookID string, input StopReadingSessionInput) (*data.ReadingSession, error)
+
+	CreateGoal(db initialisers.IGoalCollection, input GoalInput) (interface{}, *data.Goal, error)
+	DeleteGoal(db initialisers.IGoalCollection, id string) error
+	GetCurrentGoalsProgress(goals initialisers.IGoalCollection, books initialisers.IBookCollection, owner string) ([]*data.GoalProgress, error)
+	GetGoal(db initialisers.IGoalCollection, id string) (*data.Goal, error)
+	GetGoalProgress(goals initialisers.IGoalCollection, books initialisers.IBookCollection, id string) (*data.GoalProgress, error)
+	GetGoals(db initialisers.IGoalCollection, owner string) ([]*data.Goal, error)
+	UpdateGoal(db initialisers.IGoalCollection, goal *data.Goal) error
+
+	AddToShelf(shelves initialisers.IShelfCollection, books initialisers.IBookCollection, shelfID, bookID string, position int) (*data.Shelf, error)
+	CreateShelf(db initialisers.IShelfCollection, input ShelfInput) (interface{}, *data.Shelf, error)
+	DeleteShelf(db initialisers.IShelfCollection, id string) error
+	GetBookShelves(db initialisers.IShelfCollection, bookID string) ([]*data.Shelf, error)
+	GetShelf(shelves initialisers.IShelfCollection, books initialisers.IBookCollection, id string) (*data.Shelf, error)
+	GetShelves(db initialisers.IShelfCollection) ([]*data.Shelf, error)
+	RemoveFromShelf(shelves initialisers.IShelfCollection, shelfID, bookID string) (*data.Shelf, error)
+	ReorderShelf(shelves initialisers.IShelfCollection, shelfID string, bookIDs []string) (*data.Shelf, error)
+	UpdateShelf(db initialisers.IShelfCollection, shelf *data.Shelf) error
+
+	CreateNote(notes initialisers.INoteCollection, books initialisers.IBookCollection, bookID string, input NoteInput) (*data.Note, error)
+	CreateReview(reviews initialisers.IReviewCollection, books initialisers.IBookCollection, bookID string, input ReviewInput) (*data.Review, error)
+	DeleteNote(notes initialisers.INoteCollection, bookID, id string) error
+	DeleteReview(reviews initialisers.IReviewCollection, bookID, id string) error
+	GetNote(notes initialisers.INoteCollection, bookID, id string) (*data.Note, error)
+	GetNotes(notes initialisers.INoteCollection, books initialisers.IBookCollection, bookID string) ([]*data.Note, error)
+	GetReview(reviews initialisers.IReviewCollection, bookID, id string) (*data.Review, error)
+	GetReviews(reviews initialisers.IReviewCollection, books initialisers.IBookCollection, bookID string) ([]*data.Review, error)
+	SearchNotes(notes initialisers.INoteCollection, books initialisers.IBookCollection, query string) ([]*data.Note, error)
+	UpdateNote(notes initialisers.INoteCollection, note *data.Note) error
+	UpdateReview(reviews initialisers.IReviewCollection, review *data.Review) error
+
+	CreateHighlight(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, bookID string, input HighlightInput) (*data.Highlight, error)
+	DeleteHighlight(highlights initialisers.IHighlightCollection, bookID, id string) error
+	GetBookHighlights(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, bookID string) ([]*data.Highlight, error)
+	GetHighlight(highlights initialisers.IHighlightCollection, bookID, id string) (*data.Highlight, error)
+	GetHighlights(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, tag string) ([]*data.Highlight, error)
+	HighlightOfTheDay(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, now time.Time) (*data.Highlight, error)
+	UpdateHighlight(highlights initialisers.IHighlightCollection, books initialisers.IBookCollection, highlight *data.Highlight) error
+
+	CreateTag(tags initialisers.ITagCollection, input TagInput) (interface{}, *data.Tag, error)
+	DeleteTag(tags initialisers.ITagCollection, books initialisers.IBookCollection, id string) error
+	ExpandTag(tags initialisers.ITagCollection, name string) ([]string, error)
+	GetTag(tags initialisers.ITagCollection, books initialisers.IBookCollection, id string) (*data.Tag, error)
+	GetTags(tags initialisers.ITagCollection, books initialisers.IBookCollection) ([]*data.Tag, error)
+	GetTagTree(tags initialisers.ITagCollection, books initialisers.IBookCollection) ([]*data.Tag, error)
+	MergeTags(tags initialisers.ITagCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, targetID string, sourceIDs []string) (*data.Tag, error)
+	SetBookTags(tags initialisers.ITagCollection, books initialisers.IBookCollection, bookID string, names []string) (*data.Book, error)
+	SuggestTags(tags initialisers.ITagCollection, prefix string) ([]*data.Tag, error)
+	UpdateTag(tags initialisers.ITagCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, tag *data.Tag) error
+
+	CreateUser(db initialisers.IUserCollection, input UserInput) (interface{}, *data.User, error)
+	GetUsers(db initialisers.IUserCollection) ([]*data.User, error)
 }
 
 func NewModel() *Model {
@@ -34,19 +132,43 @@ Returns:
 	return2: error
 */
 func (m *Model) Insert(db initialisers.IBookCollection, input Input) (interface{}, *data.Book, error) {
+	status := input.Status
+	if status == "" {
+		status = data.StatusWantToRead
+	}
+
 	data := &data.Book{
-		ID:        "",
-		Title:     input.Title,
-		Published: input.Published,
-		Pages:     input.Pages,
-		Genres:    input.Genres,
-		Rating:    input.Rating,
+		ID:          "",
+		Owner:       strings.TrimSpace(input.Owner),
+		Title:       input.Title,
+		Authors:     input.Authors,
+		AuthorIDs:   input.AuthorIDs,
+		ISBN10:      input.ISBN10,
+		ISBN13:      input.ISBN13,
+		Publisher:   input.Publisher,
+		Language:    input.Language,
+		Description: input.Description,
+		Edition:     input.Edition,
+		Series:      input.Series,
+		Published:   input.Published,
+		Pages:       input.Pages,
+		Genres:      input.Genres,
+		Rating:      input.Rating,
+	}
+
+	if err := normaliseISBNs(data); err != nil {
+		return nil, nil, err
+	}
+
+	if err := applyStatus(data, status, time.Now()); err != nil {
+		return nil, nil, err
 	}
 
 	id, err := db.Create(data)
 	if err != nil {
 		return nil, nil, err
 	}
+	fillPercent(data)
 	return id, data, nil
 }
 
@@ -63,6 +185,50 @@ func (m *Model) GetAll(db initialisers.IBookCollection) ([]*data.Book, error) {
 	if err != nil {
 		return nil, err
 	}
+	fillPercent(data...)
+	return data, nil
+}
+
+/*
+Calls the DB to summarise the library: genres, ratings, publication decades, pages, books added per month
+and the top-rated books. Collections that don't implement IBookStats are summarised from every book.
+
+Returns:
+
+	return1: pointer of library statistics
+	return2: error
+*/
+func (m *Model) GetStats(db initialisers.IBookCollection) (*data.LibraryStats, error) {
+	if stats, ok := db.(initialisers.IBookStats); ok {
+		return stats.Stats()
+	}
+
+	books, err := db.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return initialisers.ComputeLibraryStats(books), nil
+}
+
+/*
+Calls the DB to retrieve the books matching a filter, such as reading statuses.
+
+Parameters:
+
+	param1: filter data.BookFilter
+
+Returns:
+
+	return1: slice of a pointer of books
+	return2: error
+*/
+func (m *Model) GetFiltered(db initialisers.IBookCollection, filter data.BookFilter) ([]*data.Book, error) {
+	data, err := db.GetFiltered(filter)
+	if err != nil {
+		return nil, err
+	}
+	fillPercent(data...)
 	return data, nil
 }
 
@@ -83,6 +249,34 @@ func (m *Model) Get(db initialisers.IBookCollection, id string) (*data.Book, err
 	if err != nil {
 		return nil, err
 	}
+	fillPercent(data)
+	return data, nil
+}
+
+/*
+Calls the DB to find a book of an owner by ISBN. The ISBN may be an ISBN-10 or ISBN-13, with or without hyphens.
+
+Parameters:
+
+	param1: owner string, empty for the books nobody owns
+	param2: value string, the ISBN to look up
+
+Returns:
+
+	return1: pointer of book data
+	return2: error
+*/
+func (m *Model) GetByISBN(db initialisers.IBookCollection, owner, value string) (*data.Book, error) {
+	isbn13, err := isbn.Normalize(value)
+	if err != nil {
+		return nil, &ValidationError{Field: "isbn", Message: "must be a valid ISBN-10 or ISBN-13"}
+	}
+
+	data, err := db.GetByISBN(strings.TrimSpace(owner), isbn13)
+	if err != nil {
+		return nil, err
+	}
+	fillPercent(data)
 	return data, nil
 }
 
@@ -100,6 +294,10 @@ Returns:
 	return2: error
 */
 func (m *Model) Update(db initialisers.IBookCollection, id string, data *data.Book) error {
+	if err := normaliseISBNs(data); err != nil {
+		return err
+	}
+
 	err := db.Update(data)
 	if err != nil {
 		return err
@@ -108,7 +306,102 @@ func (m *Model) Update(db initialisers.IBookCollection, id string, data *data.Bo
 }
 
 /*
-Calls the DB to perform a delete operation with a given id.
+Applies a partial update to a book, leaving out the fields missing from the input. An empty list of authors or
+genres clears them, and an ISBN-10 or ISBN-13 alone replaces the book's ISBN, an empty one removing it.
+Linked authors take precedence over author names and an empty list of author IDs unlinks every author, keeping their names.
+Renaming the series as plain text takes the book out of its linked series.
+
+Parameters:
+
+	param1: pointer of book data, changed in place
+	param2: input UpdateInput
+
+Returns:
+
+	return1: error, a *ValidationError when an author ID is unknown or the pages are fewer than the current page
+*/
+func (m *Model) ApplyUpdate(authors initialisers.IAuthorCollection, book *data.Book, input UpdateInput) error {
+	if input.Title != nil {
+		book.Title = *input.Title
+	}
+
+	if input.Authors != nil {
+		book.Authors = input.Authors
+		book.AuthorIDs = nil
+	}
+
+	if input.AuthorIDs != nil {
+		book.AuthorIDs = input.AuthorIDs
+
+		if len(input.AuthorIDs) > 0 {
+			names, err := authorNames(authors, input.AuthorIDs)
+			if err != nil {
+				return err
+			}
+			book.Authors = names
+		}
+	}
+
+	// Either ISBN replaces both: the one left out is derived again when the book is saved
+	if input.ISBN10 != nil || input.ISBN13 != nil {
+		book.ISBN10, book.ISBN13 = "", ""
+
+		if input.ISBN10 != nil {
+			book.ISBN10 = *input.ISBN10
+		}
+
+		if input.ISBN13 != nil {
+			book.ISBN13 = *input.ISBN13
+		}
+	}
+
+	if input.Publisher != nil {
+		book.Publisher = *input.Publisher
+	}
+
+	if input.Language != nil {
+		book.Language = *input.Language
+	}
+
+	if input.Description != nil {
+		book.Description = *input.Description
+	}
+
+	if input.Edition != nil {
+		book.Edition = *input.Edition
+	}
+
+	if input.Series != nil && *input.Series != book.Series {
+		book.Series = *input.Series
+		book.SeriesID, book.SeriesPosition = "", 0
+	}
+
+	if input.Published != nil {
+		book.Published = *input.Published
+	}
+
+	if input.Pages != nil {
+		// UpdateProgress keeps the current page within the pages; shrinking the pages must too
+		if *input.Pages > 0 && book.CurrentPage > *input.Pages {
+			return &ValidationError{Field: "pages", Message: fmt.Sprintf("must be at least the current page, %d", book.CurrentPage)}
+		}
+
+		book.Pages = *input.Pages
+	}
+
+	if input.Genres != nil {
+		book.Genres = input.Genres
+	}
+
+	if input.Rating != nil {
+		book.Rating = *input.Rating
+	}
+
+	return nil
+}
+
+/*
+Calls the DB to move a book to the trash. It can be restored with RestoreBook until it is purged.
 
 Parameters:
 
@@ -125,3 +418,49 @@ func (m *Model) Delete(db initialisers.IBookCollection, id string) error {
 	}
 	return nil
 }
+
+/*
+normaliseISBNs validates the book's ISBN-10 and ISBN-13, stores the ISBN-13 without hyphens
+and derives whichever of the two is missing. Both must describe the same book.
+
+Parameters:
+
+	param1: pointer of book data
+
+Returns:
+
+	return1: error, a *ValidationError when an ISBN is invalid
+*/
+func normaliseISBNs(book *data.Book) error {
+	var isbn13 string
+
+	if book.ISBN13 != "" {
+		cleaned := isbn.Clean(book.ISBN13)
+		if !isbn.Valid13(cleaned) {
+			return &ValidationError{Field: "isbn13", Message: "must be a valid ISBN-13"}
+		}
+		isbn13 = cleaned
+	}
+
+	if book.ISBN10 != "" {
+		from10, err := isbn.To13(book.ISBN10)
+		if err != nil {
+			return &ValidationError{Field: "isbn10", Message: "must be a valid ISBN-10"}
+		}
+
+		if isbn13 != "" && isbn13 != from10 {
+			return &ValidationError{Field: "isbn10", Message: "does not match isbn13"}
+		}
+		isbn13 = from10
+	}
+
+	book.ISBN13 = isbn13
+	book.ISBN10 = ""
+
+	if isbn13 != "" {
+		// Only 978 ISBN-13s have an ISBN-10 form.
+		book.ISBN10, _ = isbn.To10(isbn13)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"errors"
 	"readinglistapp/initialisers"
 	"readinglistapp/internal/data"
 	"readinglistapp/internal/mocks"
@@ -17,7 +18,7 @@ var model = NewModel()
 
 func TestInsert(t *testing.T) {
 	mockCollection := &mocks.MockCollection{}
-	bookCollection := &initialisers.BookCollection{Collection: mockCollection}
+	bookCollection := &initialisers.BookCollection{Store: initialisers.Store{Collection: mockCollection}}
 
 	mockInsertResult := &mongo.InsertOneResult{InsertedID: primitive.NewObjectID()}
 
@@ -42,7 +43,7 @@ func TestInsert(t *testing.T) {
 
 func TestGet(t *testing.T) {
 	mockCollection := &mocks.MockCollection{}
-	bookCollection := &initialisers.BookCollection{Collection: mockCollection}
+	bookCollection := &initialisers.BookCollection{Store: initialisers.Store{Collection: mockCollection}}
 
 	bookID := "507f1f77bcf86cd799439011"
 
@@ -71,7 +72,7 @@ func TestGet(t *testing.T) {
 func TestUpdateModel(t *testing.T) {
 	// Create a mock instance of IBookCollection
 	mockCollection := &mocks.MockCollection{}
-	bookCollection := &initialisers.BookCollection{Collection: mockCollection}
+	bookCollection := &initialisers.BookCollection{Store: initialisers.Store{Collection: mockCollection}}
 
 	mockUpdateResult := &mongo.UpdateResult{}
 
@@ -99,15 +100,78 @@ func TestUpdateModel(t *testing.T) {
 	}
 }
 
+func TestApplyUpdateClearsAuthorsAndGenres(t *testing.T) {
+	book := &data.Book{Title: "Dune", Authors: []string{"Frank Herbert"}, Genres: []string{"Sci-Fi"}}
+
+	err := model.ApplyUpdate(nil, book, UpdateInput{Authors: []string{}, Genres: []string{}})
+
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+	if len(book.Authors) != 0 || len(book.Genres) != 0 {
+		t.Errorf("got authors %v and genres %v, expected both cleared", book.Authors, book.Genres)
+	}
+
+	// Fields left out of the input are kept
+	book.Authors, book.Genres = []string{"Frank Herbert"}, []string{"Sci-Fi"}
+	if err := model.ApplyUpdate(nil, book, UpdateInput{}); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+	if len(book.Authors) != 1 || len(book.Genres) != 1 {
+		t.Errorf("got authors %v and genres %v, expected them kept", book.Authors, book.Genres)
+	}
+}
+
+func TestApplyUpdateKeepsCurrentPageWithinPages(t *testing.T) {
+	book := &data.Book{Title: "Dune", Pages: 400, CurrentPage: 250}
+
+	pages := 200
+	var validationErr *ValidationError
+	if err := model.ApplyUpdate(nil, book, UpdateInput{Pages: &pages}); !errors.As(err, &validationErr) || validationErr.Field != "pages" {
+		t.Errorf("got error %v, expected a ValidationError for pages", err)
+	}
+
+	pages = 250
+	if err := model.ApplyUpdate(nil, book, UpdateInput{Pages: &pages}); err != nil || book.Pages != 250 {
+		t.Errorf("got error %v and pages %d, expected the pages to reach the current page", err, book.Pages)
+	}
+}
+
+func TestApplyUpdateReplacesISBN(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{"b1": {ID: "b1", Title: "Emma", ISBN10: "0306406152", ISBN13: "9780306406157"}}}
+
+	isbn13 := "978-0-14-143958-7"
+	book, _ := model.Get(books, "b1")
+	if err := model.ApplyUpdate(nil, book, UpdateInput{ISBN13: &isbn13}); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if err := model.Update(books, "b1", book); err != nil || book.ISBN13 != "9780141439587" || book.ISBN10 != "0141439580" {
+		t.Errorf("got ISBNs %q and %q and error %v, expected the new ISBN-13 with its own ISBN-10", book.ISBN13, book.ISBN10, err)
+	}
+
+	isbn13 = ""
+	book, _ = model.Get(books, "b1")
+	if err := model.ApplyUpdate(nil, book, UpdateInput{ISBN13: &isbn13}); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if err := model.Update(books, "b1", book); err != nil || book.ISBN13 != "" || book.ISBN10 != "" {
+		t.Errorf("got ISBNs %q and %q and error %v, expected the ISBN removed", book.ISBN13, book.ISBN10, err)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	// Create a mock instance of IBookCollection
 	mockCollection := &mocks.MockCollection{}
-	bookCollection := &initialisers.BookCollection{Collection: mockCollection}
+	bookCollection := &initialisers.BookCollection{Store: initialisers.Store{Collection: mockCollection}}
 
-	mockDeleteResult := &mongo.DeleteResult{}
+	var update interface{}
 
-	mockCollection.DeleteManyFunc = func(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
-		return mockDeleteResult, nil
+	// Deleting moves the book to the trash: its document is updated, not removed
+	mockCollection.UpdateOneFunc = func(ctx context.Context, filter interface{}, u interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+		update = u
+		return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
 	}
 
 	bookID := "507f1f77bcf86cd799439011"
@@ -117,4 +181,142 @@ func TestDelete(t *testing.T) {
 	if err != nil {
 		t.Errorf("got error %v, expected nil", err)
 	}
+
+	set, ok := update.(bson.D)
+	if !ok || len(set) != 1 || set[0].Key != "$set" || set[0].Value.(bson.D)[0].Key != "deletedat" {
+		t.Errorf("got update %v, expected deletedat to be set", update)
+	}
+}
+
+func TestInsertNormalisesISBN(t *testing.T) {
+	mockCollection := &mocks.MockCollection{}
+	bookCollection := &initialisers.BookCollection{Store: initialisers.Store{Collection: mockCollection}}
+
+	mockCollection.InsertOneFunc = func(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+		return &mongo.InsertOneResult{InsertedID: primitive.NewObjectID()}, nil
+	}
+
+	_, book, err := model.Insert(bookCollection, Input{Title: "Unit Test", ISBN10: "0-306-40615-2"})
+
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if book.ISBN13 != "9780306406157" || book.ISBN10 != "0306406152" {
+		t.Errorf("got ISBNs %q and %q, expected 9780306406157 and 0306406152", book.ISBN13, book.ISBN10)
+	}
+}
+
+func TestInsertOwner(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{}}
+
+	for _, owner := range []string{" ana ", "ben", ""} {
+		if _, _, err := model.Insert(books, Input{Title: "Emma", Owner: owner}); err != nil {
+			t.Fatalf("got error %v, expected nil", err)
+		}
+	}
+
+	owned, err := model.GetFiltered(books, data.BookFilter{Owners: []string{"ana"}})
+	if err != nil || len(owned) != 1 || owned[0].Owner != "ana" {
+		t.Errorf("got %v and error %v, expected the one book of ana, with the owner trimmed", owned, err)
+	}
+
+	unowned, err := model.GetFiltered(books, data.BookFilter{Owners: []string{""}})
+	if err != nil || len(unowned) != 1 {
+		t.Errorf("got %v and error %v, expected the one book without an owner", unowned, err)
+	}
+}
+
+func TestISBNPerOwner(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{}}
+
+	for _, owner := range []string{"ana", "ben"} {
+		if _, _, err := model.Insert(books, Input{Title: "Emma", Owner: owner, ISBN13: "978-0-14-143958-7"}); err != nil {
+			t.Fatalf("got error %v, expected each owner to have a book with the ISBN", err)
+		}
+	}
+
+	if _, _, err := model.Insert(books, Input{Title: "Emma again", Owner: "ana", ISBN10: "0141439580"}); !errors.Is(err, initialisers.ErrDuplicateRecord) {
+		t.Errorf("got error %v, expected a second book of ana with the ISBN refused", err)
+	}
+
+	book, err := model.GetByISBN(books, "ben", "0-14-143958-0")
+	if err != nil || book.Owner != "ben" {
+		t.Errorf("got %+v and error %v, expected ben's Emma", book, err)
+	}
+
+	if _, err := model.GetByISBN(books, "", "9780141439587"); !errors.Is(err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected no book nobody owns with the ISBN", err)
+	}
+}
+
+func TestInsertRejectsInvalidISBN(t *testing.T) {
+	mockCollection := &mocks.MockCollection{}
+	bookCollection := &initialisers.BookCollection{Store: initialisers.Store{Collection: mockCollection}}
+
+	inputs := []Input{
+		{Title: "Bad checksum", ISBN13: "978-0-306-40615-8"},
+		{Title: "Mismatch", ISBN10: "0-8044-2957-X", ISBN13: "978-0-306-40615-7"},
+	}
+
+	for _, input := range inputs {
+		_, _, err := model.Insert(bookCollection, input)
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: got error %v, expected a ValidationError", input.Title, err)
+		}
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	mockCollection := &mocks.MockCollection{}
+	bookCollection := &initialisers.BookCollection{Store: initialisers.Store{Collection: mockCollection}}
+
+	facets := bson.D{
+		{Key: "books", Value: bson.A{bson.D{{Key: "count", Value: int32(3)}}}},
+		{Key: "unrated", Value: bson.A{}},
+		{Key: "genres", Value: bson.A{
+			bson.D{{Key: "_id", Value: "Classic"}, {Key: "count", Value: int32(1)}},
+			bson.D{{Key: "_id", Value: "Sci-Fi"}, {Key: "count", Value: int32(2)}},
+		}},
+		{Key: "ratings", Value: bson.A{bson.D{{Key: "_id", Value: 4.0}, {Key: "count", Value: int32(3)}}}},
+		{Key: "decades", Value: bson.A{bson.D{{Key: "_id", Value: int64(1960)}, {Key: "count", Value: int32(3)}}}},
+		{Key: "pages", Value: bson.A{bson.D{{Key: "_id", Value: nil}, {Key: "total", Value: int64(900)}, {Key: "average", Value: 300.0}, {Key: "books", Value: int32(3)}}}},
+		{Key: "added", Value: bson.A{bson.D{{Key: "_id", Value: "2024-03"}, {Key: "count", Value: int32(3)}}}},
+		{Key: "toprated", Value: bson.A{bson.D{{Key: "_id", Value: "507f1f77bcf86cd799439011"}, {Key: "title", Value: "Dune"}, {Key: "rating", Value: 4.5}}}},
+	}
+
+	mockCollection.AggregateFunc = func(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+		return mongo.NewCursorFromDocuments([]interface{}{facets}, nil, bson.DefaultRegistry)
+	}
+
+	stats, err := model.GetStats(bookCollection)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if stats.Books != 3 || stats.Genres[0].Genre != "Sci-Fi" || stats.Ratings[3].Count != 3 || stats.Decades[0].Decade != 1960 {
+		t.Errorf("got %+v, expected the aggregation results in documented order", stats)
+	}
+
+	if stats.Pages.Total != 900 || stats.AddedPerMonth[0].Month != "2024-03" || stats.TopRated[0].ID != "507f1f77bcf86cd799439011" {
+		t.Errorf("got %+v, expected pages, months and top-rated books decoded", stats)
+	}
+}
+
+func TestGetStatsWithoutAggregation(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Mort", Genres: []string{"Fantasy"}, Pages: 272},
+		"b2": {ID: "b2", Title: "Emma", Genres: []string{"Classics"}, Pages: 474},
+	}}
+
+	stats, err := model.GetStats(books)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if stats.Books != 2 || stats.Pages.Total != 746 {
+		t.Errorf("got %+v, expected the figures computed from both books", stats)
+	}
 }
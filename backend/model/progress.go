@@ -0,0 +1,179 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strings"
+	"time"
+)
+
+/*
+UpdateProgress records how far a book has been read and, optionally, changes its reading status.
+Moving past page 0 of a book not yet started marks it as reading, reaching the last page marks it as finished.
+
+Parameters:
+
+	param1: id string
+	param2: input ProgressInput
+
+Returns:
+
+	return1: pointer of the updated book data
+	return2: error, a *ValidationError for an unknown status, a disallowed transition or a page out of range
+*/
+func (m *Model) UpdateProgress(db initialisers.IBookCollection, id string, input ProgressInput) (*data.Book, error) {
+	book, err := db.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if input.Status != nil {
+		if err := applyStatus(book, *input.Status, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.CurrentPage != nil {
+		page := *input.CurrentPage
+
+		if page < 0 || (book.Pages > 0 && page > book.Pages) {
+			return nil, &ValidationError{Field: "currentPage", Message: fmt.Sprintf("must be between 0 and %d", book.Pages)}
+		}
+
+		if input.Status == nil {
+			switch {
+			case book.Pages > 0 && page == book.Pages && book.Status != data.StatusFinished:
+				err = applyStatus(book, data.StatusFinished, now)
+			case page > 0 && (book.Status == data.StatusWantToRead || book.Status == ""):
+				err = applyStatus(book, data.StatusReading, now)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		book.CurrentPage = page
+	}
+
+	book.Version++
+
+	if err := db.Update(book); err != nil {
+		return nil, err
+	}
+
+	fillPercent(book)
+
+	return book, nil
+}
+
+/*
+ParseStatusFilter parses a comma-separated list of reading statuses, as used by the status query parameter.
+
+Parameters:
+
+	param1: value string
+
+Returns:
+
+	return1: []string, the statuses, empty when value is
+	return2: error, a *ValidationError for an unknown status
+*/
+func ParseStatusFilter(value string) ([]string, error) {
+	var statuses []string
+
+	for _, status := range strings.Split(value, ",") {
+		if status = strings.TrimSpace(status); status == "" {
+			continue
+		}
+
+		if !validStatus(status) {
+			return nil, statusError(status)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+/*
+applyStatus moves a book to a new reading status and records when it happened.
+Starting a finished book again begins a re-read, starting an abandoned one resumes it; a book can't be abandoned before it was started
+or after it was finished.
+*/
+func applyStatus(book *data.Book, status string, now time.Time) error {
+	if !validStatus(status) {
+		return statusError(status)
+	}
+
+	current := book.Status
+	if current == "" {
+		current = data.StatusWantToRead
+	}
+
+	if status == current && book.Status != "" {
+		return nil
+	}
+
+	switch status {
+	case data.StatusWantToRead:
+		book.CurrentPage = 0
+		book.StartedAt, book.FinishedAt, book.AbandonedAt = nil, nil, nil
+	case data.StatusReading:
+		// Resuming an abandoned book keeps the page reached and when reading began
+		if current != data.StatusAbandoned || book.StartedAt == nil {
+			book.CurrentPage = 0
+			book.StartedAt = &now
+		}
+		book.FinishedAt, book.AbandonedAt = nil, nil
+	case data.StatusFinished:
+		if book.StartedAt == nil {
+			book.StartedAt = &now
+		}
+		if book.Pages > 0 {
+			book.CurrentPage = book.Pages
+		}
+		book.FinishedAt, book.AbandonedAt = &now, nil
+	case data.StatusAbandoned:
+		if current != data.StatusReading {
+			return &ValidationError{Field: "status", Message: fmt.Sprintf("can't abandon a book that is %s", strings.ReplaceAll(current, "_", " "))}
+		}
+		book.AbandonedAt = &now
+	}
+
+	book.Status = status
+
+	return nil
+}
+
+/*
+fillPercent sets how much of each book has been read, rounded to one decimal place.
+*/
+func fillPercent(books ...*data.Book) {
+	for _, book := range books {
+		book.Percent = 0
+
+		if book.Pages > 0 {
+			book.Percent = math.Round(float64(book.CurrentPage)/float64(book.Pages)*1000) / 10
+		}
+	}
+}
+
+func validStatus(status string) bool {
+	for _, s := range data.Statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func statusError(status string) error {
+	return &ValidationError{Field: "status", Message: fmt.Sprintf("%q must be one of %s", status, strings.Join(data.Statuses, ", "))}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/internal/data"
+	"testing"
+)
+
+func TestUpdateProgress(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Mort", Pages: 200, Status: data.StatusWantToRead},
+	}}
+
+	page := 50
+	book, err := model.UpdateProgress(books, "b1", ProgressInput{CurrentPage: &page})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if book.Status != data.StatusReading || book.StartedAt == nil || book.Percent != 25 {
+		t.Errorf("got status %q, startedAt %v, percent %v; expected reading, a start date and 25", book.Status, book.StartedAt, book.Percent)
+	}
+
+	page = 200
+	book, _ = model.UpdateProgress(books, "b1", ProgressInput{CurrentPage: &page})
+
+	if book.Status != data.StatusFinished || book.FinishedAt == nil {
+		t.Errorf("got status %q, finishedAt %v; expected the last page to finish the book", book.Status, book.FinishedAt)
+	}
+
+	page = 201
+	_, err = model.UpdateProgress(books, "b1", ProgressInput{CurrentPage: &page})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError for a page past the end", err)
+	}
+}
+
+func TestStatusTransitions(t *testing.T) {
+	abandoned := data.StatusAbandoned
+	reading := data.StatusReading
+	unknown := "lost"
+
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Pages: 100, Status: data.StatusWantToRead},
+	}}
+
+	for _, status := range []*string{&abandoned, &unknown} {
+		var validationErr *ValidationError
+		if _, err := model.UpdateProgress(books, "b1", ProgressInput{Status: status}); !errors.As(err, &validationErr) {
+			t.Errorf("status %q: got error %v, expected a ValidationError", *status, err)
+		}
+	}
+
+	page := 40
+	model.UpdateProgress(books, "b1", ProgressInput{Status: &reading, CurrentPage: &page})
+	model.UpdateProgress(books, "b1", ProgressInput{Status: &abandoned})
+	book, err := model.UpdateProgress(books, "b1", ProgressInput{Status: &reading})
+
+	if err != nil || book.CurrentPage != 40 || book.AbandonedAt != nil {
+		t.Errorf("got %+v, %v; expected resuming an abandoned book to keep its page", book, err)
+	}
+}
+
+func TestParseStatusFilter(t *testing.T) {
+	statuses, err := ParseStatusFilter("reading, finished")
+	if err != nil || len(statuses) != 2 {
+		t.Errorf("got %v, %v; expected reading and finished", statuses, err)
+	}
+
+	if _, err := ParseStatusFilter("reading,lost"); err == nil {
+		t.Errorf("expected an error for an unknown status")
+	}
+}
@@ -0,0 +1,320 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"time"
+)
+
+// statsPeriod is the number of days covered by ReadingStats when no period is given.
+const statsPeriod = 30
+
+/*
+Starts timing a reading session of a book from its current page. A book that is waiting to be read
+or was abandoned is marked as reading. Only one session per book can run at a time.
+
+Parameters:
+
+	param1: bookID string
+
+Returns:
+
+	return1: pointer of the started session
+	return2: error, wrapping ErrDuplicateRecord when a session is already running
+*/
+func (m *Model) StartSession(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, bookID string) (*data.ReadingSession, error) {
+	book, err := books.Get(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := sessions.GetOpen(bookID); err == nil {
+		return nil, fmt.Errorf("%w: a reading session of this book is already running", initialisers.ErrDuplicateRecord)
+	} else if !isNotFound(err) {
+		return nil, err
+	}
+
+	if book.Status == data.StatusWantToRead || book.Status == data.StatusAbandoned || book.Status == "" {
+		reading := data.StatusReading
+		if book, err = m.UpdateProgress(books, bookID, ProgressInput{Status: &reading}); err != nil {
+			return nil, err
+		}
+	}
+
+	session := &data.ReadingSession{
+		BookID:    bookID,
+		StartedAt: time.Now(),
+		StartPage: book.CurrentPage,
+	}
+
+	if _, err := sessions.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+/*
+Stops the running session of a book, recording the minutes since it started and the pages read,
+and moves the book on to the page reached.
+
+Parameters:
+
+	param1: bookID string
+	param2: input StopReadingSessionInput, either the page reached or the number of pages read
+
+Returns:
+
+	return1: pointer of the stopped session
+	return2: error, ErrRecordNotFound when no session is running, a *ValidationError for an invalid page
+*/
+func (m *Model) StopSession(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, bookID string, input StopReadingSessionInput) (*data.ReadingSession, error) {
+	session, err := sessions.GetOpen(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	endPage := session.StartPage
+
+	switch {
+	case input.EndPage != nil && input.Pages != nil:
+		return nil, &ValidationError{Field: "endPage", Message: "can't be given together with pages"}
+	case input.EndPage != nil:
+		if *input.EndPage < session.StartPage {
+			return nil, &ValidationError{Field: "endPage", Message: fmt.Sprintf("must be at least the start page %d", session.StartPage)}
+		}
+		endPage = *input.EndPage
+	case input.Pages != nil:
+		if *input.Pages < 0 {
+			return nil, &ValidationError{Field: "pages", Message: "must not be negative"}
+		}
+		endPage = session.StartPage + *input.Pages
+	}
+
+	if endPage != session.StartPage {
+		if _, err := m.UpdateProgress(books, bookID, ProgressInput{CurrentPage: &endPage}); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+
+	session.EndedAt = &now
+	session.EndPage = endPage
+	session.Pages = endPage - session.StartPage
+	session.Minutes = int(math.Max(1, math.Round(now.Sub(session.StartedAt).Minutes())))
+
+	if err := sessions.Update(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+/*
+Records a session entered by hand. It doesn't change the book's progress.
+
+Parameters:
+
+	param1: bookID string
+	param2: input ReadingSessionInput, the pages read and/or minutes spent, started now unless given
+
+Returns:
+
+	return1: pointer of the recorded session
+	return2: error, a *ValidationError for negative values, an empty session or a start in the future
+*/
+func (m *Model) LogSession(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, bookID string, input ReadingSessionInput) (*data.ReadingSession, error) {
+	if _, err := books.Get(bookID); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case input.Pages < 0:
+		return nil, &ValidationError{Field: "pages", Message: "must not be negative"}
+	case input.Minutes < 0:
+		return nil, &ValidationError{Field: "minutes", Message: "must not be negative"}
+	case input.Pages == 0 && input.Minutes == 0:
+		return nil, &ValidationError{Field: "pages", Message: "pages or minutes must be provided"}
+	}
+
+	duration := time.Duration(input.Minutes) * time.Minute
+	startedAt := time.Now().Add(-duration)
+
+	if input.StartedAt != nil {
+		if input.StartedAt.After(time.Now()) {
+			return nil, &ValidationError{Field: "startedAt", Message: "must not be in the future"}
+		}
+		startedAt = *input.StartedAt
+	}
+
+	endedAt := startedAt.Add(duration)
+
+	session := &data.ReadingSession{
+		BookID:    bookID,
+		StartedAt: startedAt,
+		EndedAt:   &endedAt,
+		Pages:     input.Pages,
+		Minutes:   input.Minutes,
+		Manual:    true,
+	}
+
+	if _, err := sessions.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+/*
+Calls the DB to list the sessions of a book, most recent first, with statistics over all of them.
+
+Parameters:
+
+	param1: bookID string
+
+Returns:
+
+	return1: slice of a pointer of sessions
+	return2: pointer of the book's reading statistics
+	return3: error
+*/
+func (m *Model) GetBookSessions(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, bookID string) ([]*data.ReadingSession, *data.ReadingStats, error) {
+	if _, err := books.Get(bookID); err != nil {
+		return nil, nil, err
+	}
+
+	list, err := sessions.GetByBook(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from := startOfDay(time.Now())
+	to := from.AddDate(0, 0, 1)
+
+	// Sessions are most recent first, so the period runs from the day of the last one.
+	if len(list) > 0 {
+		from = startOfDay(list[len(list)-1].StartedAt)
+	}
+
+	return list, summariseSessions(list, from, to), nil
+}
+
+/*
+Computes reading statistics over the sessions started in [from, to): pages per day, reading speed and the
+average reading time of the books finished in the period. A zero from or to covers the last 30 days.
+
+Parameters:
+
+	param1: from time.Time
+	param2: to time.Time
+
+Returns:
+
+	return1: pointer of reading statistics
+	return2: error, a *ValidationError when the period is empty
+*/
+func (m *Model) ReadingStats(sessions initialisers.IReadingSessionCollection, books initialisers.IBookCollection, from, to time.Time) (*data.ReadingStats, error) {
+	if to.IsZero() {
+		to = startOfDay(time.Now()).AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -statsPeriod)
+	}
+
+	from, to = startOfDay(from), startOfDay(to)
+
+	if !from.Before(to) {
+		return nil, &ValidationError{Field: "to", Message: "must be after from"}
+	}
+
+	list, err := sessions.GetBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := summariseSessions(list, from, to)
+
+	finished, err := books.GetFiltered(data.BookFilter{Statuses: []string{data.StatusFinished}})
+	if err != nil {
+		return nil, err
+	}
+
+	timedBooks, totalMinutes := 0, 0
+
+	for _, book := range finished {
+		if book.FinishedAt == nil || book.FinishedAt.Before(from) || !book.FinishedAt.Before(to) {
+			continue
+		}
+
+		stats.BooksFinished++
+
+		bookSessions, err := sessions.GetByBook(book.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		minutes := summariseSessions(bookSessions, from, from).TotalMinutes
+		if minutes > 0 {
+			timedBooks++
+			totalMinutes += minutes
+		}
+	}
+
+	if timedBooks > 0 {
+		stats.AverageMinutesPerBook = round1(float64(totalMinutes) / float64(timedBooks))
+	}
+
+	return stats, nil
+}
+
+/*
+summariseSessions totals the finished sessions and spreads their pages over the days in [from, to),
+counting days without reading as zero. Pages per hour only counts sessions with a duration.
+*/
+func summariseSessions(sessions []*data.ReadingSession, from, to time.Time) *data.ReadingStats {
+	stats := &data.ReadingStats{From: from, To: to}
+
+	perDay := map[string]int{}
+	timedPages := 0
+
+	for _, session := range sessions {
+		if session.EndedAt == nil {
+			continue
+		}
+
+		stats.Sessions++
+		stats.TotalPages += session.Pages
+		stats.TotalMinutes += session.Minutes
+		perDay[session.StartedAt.UTC().Format(time.DateOnly)] += session.Pages
+
+		if session.Minutes > 0 {
+			timedPages += session.Pages
+		}
+	}
+
+	days := 0
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		stats.PagesPerDay = append(stats.PagesPerDay, data.DailyPages{Date: date, Pages: perDay[date]})
+		days++
+	}
+
+	if days > 0 {
+		stats.AveragePagesPerDay = round1(float64(stats.TotalPages) / float64(days))
+	}
+
+	if stats.TotalMinutes > 0 {
+		stats.PagesPerHour = round1(float64(timedPages) / float64(stats.TotalMinutes) * 60)
+	}
+
+	return stats
+}
+
+// startOfDay returns midnight UTC of the day t falls on.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func round1(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"testing"
+	"time"
+)
+
+func TestStartAndStopSession(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Mort", Pages: 200, Status: data.StatusWantToRead},
+	}}
+	sessions := memorySessions{}
+
+	session, err := model.StartSession(sessions, books, "b1")
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if books.books["b1"].Status != data.StatusReading {
+		t.Errorf("got status %q, expected starting a session to mark the book as reading", books.books["b1"].Status)
+	}
+
+	if _, err := model.StartSession(sessions, books, "b1"); !errors.Is(err, initialisers.ErrDuplicateRecord) {
+		t.Errorf("got error %v, expected ErrDuplicateRecord for a second running session", err)
+	}
+
+	sessions[session.ID].StartedAt = time.Now().Add(-45 * time.Minute)
+
+	endPage := 30
+	session, err = model.StopSession(sessions, books, "b1", StopReadingSessionInput{EndPage: &endPage})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if session.Pages != 30 || session.Minutes != 45 || session.EndedAt == nil {
+		t.Errorf("got %d pages in %d minutes, ended %v; expected 30 pages in 45 minutes", session.Pages, session.Minutes, session.EndedAt)
+	}
+
+	if books.books["b1"].CurrentPage != 30 {
+		t.Errorf("got current page %d, expected the book to move on to page 30", books.books["b1"].CurrentPage)
+	}
+
+	if _, err := model.StopSession(sessions, books, "b1", StopReadingSessionInput{}); !errors.Is(err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected ErrRecordNotFound without a running session", err)
+	}
+}
+
+func TestLogSessionValidation(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{"b1": {ID: "b1", Title: "Mort"}}}
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		input ReadingSessionInput
+	}{
+		{"empty", ReadingSessionInput{}},
+		{"negative pages", ReadingSessionInput{Pages: -1, Minutes: 10}},
+		{"future", ReadingSessionInput{Pages: 10, StartedAt: &future}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := model.LogSession(memorySessions{}, books, "b1", tt.input)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("got error %v, expected a ValidationError", err)
+			}
+		})
+	}
+}
+
+func TestReadingStats(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	finishedAt := day.Add(50 * time.Hour)
+
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Mort", Status: data.StatusFinished, FinishedAt: &finishedAt},
+		"b2": {ID: "b2", Title: "Sourcery", Status: data.StatusReading},
+	}}
+	sessions := memorySessions{}
+
+	for _, entry := range []struct {
+		bookID  string
+		at      time.Time
+		pages   int
+		minutes int
+	}{
+		{"b1", day.Add(9 * time.Hour), 40, 60},
+		{"b1", day.Add(50 * time.Hour), 20, 30},
+		{"b2", day.Add(50 * time.Hour), 30, 0},
+	} {
+		ended := entry.at.Add(time.Duration(entry.minutes) * time.Minute)
+		sessions.Create(&data.ReadingSession{BookID: entry.bookID, StartedAt: entry.at, EndedAt: &ended, Pages: entry.pages, Minutes: entry.minutes})
+	}
+	sessions.Create(&data.ReadingSession{BookID: "b2", StartedAt: day.Add(51 * time.Hour)})
+
+	stats, err := model.ReadingStats(sessions, books, day, day.AddDate(0, 0, 4))
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if stats.Sessions != 3 || stats.TotalPages != 90 || stats.TotalMinutes != 90 {
+		t.Errorf("got %d sessions, %d pages, %d minutes; expected 3, 90 and 90", stats.Sessions, stats.TotalPages, stats.TotalMinutes)
+	}
+
+	expected := []int{40, 0, 50, 0}
+	if len(stats.PagesPerDay) != len(expected) {
+		t.Fatalf("got %d days, expected %d", len(stats.PagesPerDay), len(expected))
+	}
+	for i, pages := range expected {
+		if stats.PagesPerDay[i].Pages != pages {
+			t.Errorf("got %d pages on %s, expected %d", stats.PagesPerDay[i].Pages, stats.PagesPerDay[i].Date, pages)
+		}
+	}
+
+	if stats.AveragePagesPerDay != 22.5 || stats.PagesPerHour != 40 {
+		t.Errorf("got %v pages per day and %v per hour, expected 22.5 and 40", stats.AveragePagesPerDay, stats.PagesPerHour)
+	}
+
+	if stats.BooksFinished != 1 || stats.AverageMinutesPerBook != 90 {
+		t.Errorf("got %d books finished taking %v minutes on average, expected 1 taking 90", stats.BooksFinished, stats.AverageMinutesPerBook)
+	}
+}
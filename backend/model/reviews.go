@@ -0,0 +1,306 @@
+package model
+
+import (
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strings"
+	"time"
+)
+
+/*
+Calls the DB to list the reviews of a book, most recent first.
+
+Parameters:
+
+	param1: bookID string
+
+Returns:
+
+	return1: slice of a pointer of reviews
+	return2: error, ErrRecordNotFound when there is no such book
+*/
+func (m *Model) GetReviews(reviews initialisers.IReviewCollection, books initialisers.IBookCollection, bookID string) ([]*data.Review, error) {
+	if _, err := books.Get(bookID); err != nil {
+		return nil, err
+	}
+
+	return reviews.GetByBook(bookID)
+}
+
+/*
+Calls the DB to find a review of a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: id string
+
+Returns:
+
+	return1: pointer of review data
+	return2: error, ErrRecordNotFound when the review doesn't exist or is of another book
+*/
+func (m *Model) GetReview(reviews initialisers.IReviewCollection, bookID, id string) (*data.Review, error) {
+	review, err := reviews.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.BookID != bookID {
+		return nil, fmt.Errorf("%w: the review is of another book", initialisers.ErrRecordNotFound)
+	}
+
+	return review, nil
+}
+
+/*
+Calls the DB to add a review to a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: input ReviewInput
+
+Returns:
+
+	return1: pointer of review data
+	return2: error, a *ValidationError when the body is empty
+*/
+func (m *Model) CreateReview(reviews initialisers.IReviewCollection, books initialisers.IBookCollection, bookID string, input ReviewInput) (*data.Review, error) {
+	if _, err := books.Get(bookID); err != nil {
+		return nil, err
+	}
+
+	review := &data.Review{
+		BookID:  bookID,
+		Body:    strings.TrimSpace(input.Body),
+		Spoiler: input.Spoiler,
+		Version: 1,
+	}
+
+	if review.Body == "" {
+		return nil, &ValidationError{Field: "body", Message: "must be provided"}
+	}
+
+	if _, err := reviews.Create(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+/*
+Calls the DB to update a review, recording when it was edited.
+
+Parameters:
+
+	param1: pointer of review data
+
+Returns:
+
+	return1: error, a *ValidationError when the body is empty
+*/
+func (m *Model) UpdateReview(reviews initialisers.IReviewCollection, review *data.Review) error {
+	review.Body = strings.TrimSpace(review.Body)
+	if review.Body == "" {
+		return &ValidationError{Field: "body", Message: "must be provided"}
+	}
+
+	now := time.Now()
+	review.EditedAt = &now
+
+	return reviews.Update(review)
+}
+
+/*
+Calls the DB to delete a review of a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: id string
+
+Returns:
+
+	return1: error, ErrRecordNotFound when the review doesn't exist or is of another book
+*/
+func (m *Model) DeleteReview(reviews initialisers.IReviewCollection, bookID, id string) error {
+	if _, err := m.GetReview(reviews, bookID, id); err != nil {
+		return err
+	}
+
+	return reviews.Delete(id)
+}
+
+/*
+Calls the DB to list the private notes on a book, most recent first.
+
+Parameters:
+
+	param1: bookID string
+
+Returns:
+
+	return1: slice of a pointer of notes
+	return2: error, ErrRecordNotFound when there is no such book
+*/
+func (m *Model) GetNotes(notes initialisers.INoteCollection, books initialisers.IBookCollection, bookID string) ([]*data.Note, error) {
+	if _, err := books.Get(bookID); err != nil {
+		return nil, err
+	}
+
+	return notes.GetByBook(bookID)
+}
+
+/*
+Calls the DB to find a note on a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: id string
+
+Returns:
+
+	return1: pointer of note data
+	return2: error, ErrRecordNotFound when the note doesn't exist or is on another book
+*/
+func (m *Model) GetNote(notes initialisers.INoteCollection, bookID, id string) (*data.Note, error) {
+	note, err := notes.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if note.BookID != bookID {
+		return nil, fmt.Errorf("%w: the note is on another book", initialisers.ErrRecordNotFound)
+	}
+
+	return note, nil
+}
+
+/*
+Calls the DB to add a private note to a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: input NoteInput
+
+Returns:
+
+	return1: pointer of note data
+	return2: error, a *ValidationError when the body is empty
+*/
+func (m *Model) CreateNote(notes initialisers.INoteCollection, books initialisers.IBookCollection, bookID string, input NoteInput) (*data.Note, error) {
+	if _, err := books.Get(bookID); err != nil {
+		return nil, err
+	}
+
+	note := &data.Note{
+		BookID:  bookID,
+		Body:    strings.TrimSpace(input.Body),
+		Version: 1,
+	}
+
+	if note.Body == "" {
+		return nil, &ValidationError{Field: "body", Message: "must be provided"}
+	}
+
+	if _, err := notes.Create(note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+/*
+Calls the DB to update a note, recording when it was edited.
+
+Parameters:
+
+	param1: pointer of note data
+
+Returns:
+
+	return1: error, a *ValidationError when the body is empty
+*/
+func (m *Model) UpdateNote(notes initialisers.INoteCollection, note *data.Note) error {
+	note.Body = strings.TrimSpace(note.Body)
+	if note.Body == "" {
+		return &ValidationError{Field: "body", Message: "must be provided"}
+	}
+
+	now := time.Now()
+	note.EditedAt = &now
+
+	return notes.Update(note)
+}
+
+/*
+Calls the DB to delete a note on a book.
+
+Parameters:
+
+	param1: bookID string
+	param2: id string
+
+Returns:
+
+	return1: error, ErrRecordNotFound when the note doesn't exist or is on another book
+*/
+func (m *Model) DeleteNote(notes initialisers.INoteCollection, bookID, id string) error {
+	if _, err := m.GetNote(notes, bookID, id); err != nil {
+		return err
+	}
+
+	return notes.Delete(id)
+}
+
+/*
+Searches the text of every note, best matches first, with the title of the book each note is on.
+Notes on books in the trash are left out.
+
+Parameters:
+
+	param1: query string, words or "quoted phrases"
+
+Returns:
+
+	return1: slice of a pointer of notes
+	return2: error, a *ValidationError when the query is empty
+*/
+func (m *Model) SearchNotes(notes initialisers.INoteCollection, books initialisers.IBookCollection, query string) ([]*data.Note, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, &ValidationError{Field: "q", Message: "must be provided"}
+	}
+
+	found, err := notes.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(found))
+	for _, note := range found {
+		ids = append(ids, note.BookID)
+	}
+
+	noted, err := books.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[string]string, len(noted))
+	for _, book := range noted {
+		titles[book.ID] = book.Title
+	}
+
+	live := make([]*data.Note, 0, len(found))
+	for _, note := range found {
+		if title, ok := titles[note.BookID]; ok {
+			note.BookTitle = title
+			live = append(live, note)
+		}
+	}
+
+	return live, nil
+}
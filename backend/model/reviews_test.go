@@ -0,0 +1,64 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"testing"
+)
+
+func TestReviews(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Mort"},
+		"b2": {ID: "b2", Title: "Sourcery"},
+	}}
+	reviews := memoryReviews{}
+
+	var validationErr *ValidationError
+	if _, err := model.CreateReview(reviews, books, "b1", ReviewInput{Body: "  "}); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError for an empty body", err)
+	}
+
+	review, err := model.CreateReview(reviews, books, "b1", ReviewInput{Body: "Death takes an *apprentice*.", Spoiler: true})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if _, err := model.GetReview(reviews, "b2", review.ID); !errors.Is(err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected ErrRecordNotFound for the review of another book", err)
+	}
+
+	if err := model.DeleteReview(reviews, "b2", review.ID); !errors.Is(err, initialisers.ErrRecordNotFound) || len(reviews) != 1 {
+		t.Errorf("got error %v, expected the review of another book to be kept", err)
+	}
+
+	review.Body = "Edited"
+	if err := model.UpdateReview(reviews, review); err != nil || review.EditedAt == nil {
+		t.Errorf("got error %v and editedAt %v, expected the edit to be recorded", err, review.EditedAt)
+	}
+}
+
+func TestSearchNotes(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Mort"},
+		"b2": {ID: "b2", Title: "Sourcery"},
+	}}
+	notes := memoryNotes{}
+
+	for bookID, body := range map[string]string{"b1": "Death rides Binky", "b2": "Death of a wizard"} {
+		if _, err := model.CreateNote(notes, books, bookID, NoteInput{Body: body}); err != nil {
+			t.Fatalf("got error %v, expected nil", err)
+		}
+	}
+
+	if found, err := model.SearchNotes(notes, books, "Death"); err != nil || len(found) != 2 {
+		t.Errorf("got %v and error %v, expected both notes", found, err)
+	}
+
+	_ = model.Delete(books, "b2")
+
+	found, err := model.SearchNotes(notes, books, "Death")
+	if err != nil || len(found) != 1 || found[0].BookTitle != "Mort" {
+		t.Errorf("got %v and error %v, expected the note on the book in the trash left out", found, err)
+	}
+}
@@ -0,0 +1,285 @@
+package model
+
+import (
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"sort"
+	"strings"
+)
+
+/*
+Calls the DB to create a series.
+
+Parameters:
+
+	param1: input SeriesInput
+
+Returns:
+
+	return1: database id of inserted value
+	return2: pointer of series data
+	return3: error, a *ValidationError when the name is missing
+*/
+func (m *Model) CreateSeries(db initialisers.ISeriesCollection, input SeriesInput) (interface{}, *data.Series, error) {
+	series := &data.Series{
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+		Version:     1,
+	}
+
+	if series.Name == "" {
+		return nil, nil, &ValidationError{Field: "name", Message: "must be provided"}
+	}
+
+	id, err := db.Create(series)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, series, nil
+}
+
+/*
+Calls the DB to list every series, ordered by name. Entries are not included.
+
+Returns:
+
+	return1: slice of a pointer of series
+	return2: error
+*/
+func (m *Model) GetAllSeries(db initialisers.ISeriesCollection) ([]*data.Series, error) {
+	return db.GetAll()
+}
+
+/*
+Calls the DB to find a series by id, with its books in reading order.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: pointer of series data
+	return2: error
+*/
+func (m *Model) GetSeries(series initialisers.ISeriesCollection, books initialisers.IBookCollection, id string) (*data.Series, error) {
+	result, err := series.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := books.GetBySeries(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sortSeriesEntries(entries)
+	result.Entries = entries
+
+	return result, nil
+}
+
+/*
+Calls the DB to update a series. When the name changes, the series name stored on its books is updated too.
+
+Parameters:
+
+	param1: pointer of series data
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) UpdateSeries(series initialisers.ISeriesCollection, books initialisers.IBookCollection, s *data.Series) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return &ValidationError{Field: "name", Message: "must be provided"}
+	}
+
+	previous, err := series.Get(s.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := series.Update(s); err != nil {
+		return err
+	}
+
+	if previous.Name == s.Name {
+		return nil
+	}
+
+	entries, err := books.GetBySeries(s.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, book := range entries {
+		book.Series = s.Name
+
+		if err := books.Update(book); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+Calls the DB to delete a series. Its books keep the series name but lose the link and position.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) DeleteSeries(series initialisers.ISeriesCollection, books initialisers.IBookCollection, id string) error {
+	if _, err := series.Get(id); err != nil {
+		return err
+	}
+
+	entries, err := books.GetBySeries(id)
+	if err != nil {
+		return err
+	}
+
+	for _, book := range entries {
+		book.SeriesID, book.SeriesPosition = "", 0
+
+		if err := books.Update(book); err != nil {
+			return err
+		}
+	}
+
+	return series.Delete(id)
+}
+
+/*
+SetSeriesEntry adds a book to a series at the given position, or moves it there. Positions may be
+fractional (2.5 for a novella read between the second and third books) but must be unique within the series.
+
+Parameters:
+
+	param1: seriesID string
+	param2: bookID string
+	param3: position float64, greater than 0
+
+Returns:
+
+	return1: pointer of the updated book data
+	return2: error
+*/
+func (m *Model) SetSeriesEntry(series initialisers.ISeriesCollection, books initialisers.IBookCollection, seriesID, bookID string, position float64) (*data.Book, error) {
+	if position <= 0 {
+		return nil, &ValidationError{Field: "position", Message: "must be greater than 0"}
+	}
+
+	s, err := series.Get(seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := books.Get(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := books.GetBySeries(seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.ID != book.ID && entry.SeriesPosition == position {
+			return nil, &ValidationError{Field: "position", Message: fmt.Sprintf("%g is already taken by %q", position, entry.Title)}
+		}
+	}
+
+	book.Series, book.SeriesID, book.SeriesPosition = s.Name, s.ID, position
+
+	if err := books.Update(book); err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+/*
+RemoveSeriesEntry takes a book out of a series. It fails with ErrRecordNotFound if the book isn't in it.
+
+Parameters:
+
+	param1: seriesID string
+	param2: bookID string
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) RemoveSeriesEntry(books initialisers.IBookCollection, seriesID, bookID string) error {
+	book, err := books.Get(bookID)
+	if err != nil {
+		return err
+	}
+
+	if book.SeriesID != seriesID {
+		return fmt.Errorf("%w: book %s is not in series %s", initialisers.ErrRecordNotFound, bookID, seriesID)
+	}
+
+	book.Series, book.SeriesID, book.SeriesPosition = "", "", 0
+
+	return books.Update(book)
+}
+
+/*
+NextUnread returns the first book of a series, in reading order, that hasn't been finished.
+It returns nil when every book in the series has been read.
+
+Parameters:
+
+	param1: id string, ID of the series
+
+Returns:
+
+	return1: pointer of book data, or nil
+	return2: error
+*/
+func (m *Model) NextUnread(series initialisers.ISeriesCollection, books initialisers.IBookCollection, id string) (*data.Book, error) {
+	s, err := m.GetSeries(series, books, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, book := range s.Entries {
+		if book.Status != data.StatusFinished {
+			return book, nil
+		}
+	}
+
+	return nil, nil
+}
+
+/*
+sortSeriesEntries orders books by series position. Books without a position (linked by migration from
+a free-text series name) come last, by title.
+*/
+func sortSeriesEntries(entries []*data.Book) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+
+		switch {
+		case a.SeriesPosition == 0 && b.SeriesPosition == 0:
+			return a.Title < b.Title
+		case a.SeriesPosition == 0:
+			return false
+		case b.SeriesPosition == 0:
+			return true
+		default:
+			return a.SeriesPosition < b.SeriesPosition
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/internal/data"
+	"testing"
+)
+
+func TestNextUnread(t *testing.T) {
+	series := memorySeries{"s1": {ID: "s1", Name: "Discworld"}}
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "The Colour of Magic", SeriesID: "s1", SeriesPosition: 1, Status: data.StatusFinished},
+		"b3": {ID: "b3", Title: "Equal Rites", SeriesID: "s1", SeriesPosition: 3},
+		"b2": {ID: "b2", Title: "Troll Bridge", SeriesID: "s1", SeriesPosition: 2.5},
+		"b0": {ID: "b0", Title: "Unnumbered", SeriesID: "s1"},
+	}}
+
+	next, err := model.NextUnread(series, books, "s1")
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if next == nil || next.ID != "b2" {
+		t.Errorf("got %+v, expected the novella at position 2.5", next)
+	}
+
+	got, _ := model.GetSeries(series, books, "s1")
+	if last := got.Entries[len(got.Entries)-1]; last.ID != "b0" {
+		t.Errorf("expected books without a position last, got %s", last.ID)
+	}
+}
+
+func TestSetSeriesEntryRejectsTakenPosition(t *testing.T) {
+	series := memorySeries{"s1": {ID: "s1", Name: "Discworld"}}
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "The Colour of Magic", SeriesID: "s1", SeriesPosition: 1},
+		"b2": {ID: "b2", Title: "The Light Fantastic"},
+	}}
+
+	_, err := model.SetSeriesEntry(series, books, "s1", "b2", 1)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError", err)
+	}
+
+	book, err := model.SetSeriesEntry(series, books, "s1", "b2", 2)
+	if err != nil || book.Series != "Discworld" || books.books["b2"].SeriesPosition != 2 {
+		t.Errorf("got %+v, %v, expected the book at position 2 of Discworld", book, err)
+	}
+}
@@ -0,0 +1,275 @@
+package model
+
+import (
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strings"
+)
+
+/*
+Calls the DB to create an empty shelf.
+
+Parameters:
+
+	param1: input ShelfInput
+
+Returns:
+
+	return1: database id of inserted value
+	return2: pointer of shelf data
+	return3: error, a *ValidationError when the name is missing
+*/
+func (m *Model) CreateShelf(db initialisers.IShelfCollection, input ShelfInput) (interface{}, *data.Shelf, error) {
+	shelf := &data.Shelf{
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+		BookIDs:     []string{},
+		Version:     1,
+	}
+
+	if shelf.Name == "" {
+		return nil, nil, &ValidationError{Field: "name", Message: "must be provided"}
+	}
+
+	id, err := db.Create(shelf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, shelf, nil
+}
+
+/*
+Calls the DB to list every shelf, ordered by name. Books are not included.
+
+Returns:
+
+	return1: slice of a pointer of shelves
+	return2: error
+*/
+func (m *Model) GetShelves(db initialisers.IShelfCollection) ([]*data.Shelf, error) {
+	return db.GetAll()
+}
+
+/*
+Calls the DB to find a shelf by id, with its books in shelf order.
+Books that no longer exist are left out.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: pointer of shelf data
+	return2: error
+*/
+func (m *Model) GetShelf(shelves initialisers.IShelfCollection, books initialisers.IBookCollection, id string) (*data.Shelf, error) {
+	shelf, err := shelves.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := books.GetByIDs(shelf.BookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*data.Book, len(found))
+	for _, book := range found {
+		byID[book.ID] = book
+	}
+
+	shelf.Books = make([]*data.Book, 0, len(found))
+	for _, bookID := range shelf.BookIDs {
+		if book, ok := byID[bookID]; ok {
+			shelf.Books = append(shelf.Books, book)
+		}
+	}
+
+	fillPercent(shelf.Books...)
+
+	return shelf, nil
+}
+
+/*
+Calls the DB to list the shelves a book is on, ordered by name.
+
+Parameters:
+
+	param1: bookID string
+
+Returns:
+
+	return1: slice of a pointer of shelves
+	return2: error
+*/
+func (m *Model) GetBookShelves(db initialisers.IShelfCollection, bookID string) ([]*data.Shelf, error) {
+	return db.GetByBook(bookID)
+}
+
+/*
+Calls the DB to update a shelf's name and description.
+
+Parameters:
+
+	param1: pointer of shelf data
+
+Returns:
+
+	return1: error, a *ValidationError when the name is missing
+*/
+func (m *Model) UpdateShelf(db initialisers.IShelfCollection, shelf *data.Shelf) error {
+	shelf.Name = strings.TrimSpace(shelf.Name)
+	if shelf.Name == "" {
+		return &ValidationError{Field: "name", Message: "must be provided"}
+	}
+
+	return db.Update(shelf)
+}
+
+/*
+Calls the DB to delete a shelf. The books on it are not affected.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) DeleteShelf(db initialisers.IShelfCollection, id string) error {
+	return db.Delete(id)
+}
+
+/*
+Puts a book on a shelf at a position counted from 1, or at the end when position is 0.
+
+Parameters:
+
+	param1: shelfID string
+	param2: bookID string
+	param3: position int
+
+Returns:
+
+	return1: pointer of the updated shelf
+	return2: error, wrapping ErrDuplicateRecord when the book is already on the shelf, a *ValidationError for a position past the end
+*/
+func (m *Model) AddToShelf(shelves initialisers.IShelfCollection, books initialisers.IBookCollection, shelfID, bookID string, position int) (*data.Shelf, error) {
+	shelf, err := shelves.Get(shelfID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := books.Get(bookID); err != nil {
+		return nil, err
+	}
+
+	if shelfIndex(shelf, bookID) >= 0 {
+		return nil, fmt.Errorf("%w: the book is already on this shelf", initialisers.ErrDuplicateRecord)
+	}
+
+	if position < 0 || position > len(shelf.BookIDs)+1 {
+		return nil, &ValidationError{Field: "position", Message: fmt.Sprintf("must be between 1 and %d, or 0 for the end", len(shelf.BookIDs)+1)}
+	}
+
+	if position == 0 {
+		position = len(shelf.BookIDs) + 1
+	}
+
+	ids := make([]string, 0, len(shelf.BookIDs)+1)
+	ids = append(ids, shelf.BookIDs[:position-1]...)
+	ids = append(ids, bookID)
+	shelf.BookIDs = append(ids, shelf.BookIDs[position-1:]...)
+	shelf.Version++
+
+	if err := shelves.Update(shelf); err != nil {
+		return nil, err
+	}
+	return shelf, nil
+}
+
+/*
+Takes a book off a shelf.
+
+Parameters:
+
+	param1: shelfID string
+	param2: bookID string
+
+Returns:
+
+	return1: pointer of the updated shelf
+	return2: error, wrapping ErrRecordNotFound when the book isn't on the shelf
+*/
+func (m *Model) RemoveFromShelf(shelves initialisers.IShelfCollection, shelfID, bookID string) (*data.Shelf, error) {
+	shelf, err := shelves.Get(shelfID)
+	if err != nil {
+		return nil, err
+	}
+
+	i := shelfIndex(shelf, bookID)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: the book isn't on this shelf", initialisers.ErrRecordNotFound)
+	}
+
+	shelf.BookIDs = append(shelf.BookIDs[:i], shelf.BookIDs[i+1:]...)
+	shelf.Version++
+
+	if err := shelves.Update(shelf); err != nil {
+		return nil, err
+	}
+	return shelf, nil
+}
+
+/*
+Puts the books on a shelf in a new order. bookIDs must list every book on the shelf exactly once.
+
+Parameters:
+
+	param1: shelfID string
+	param2: bookIDs []string, the shelf's books in their new order
+
+Returns:
+
+	return1: pointer of the updated shelf
+	return2: error, a *ValidationError when bookIDs aren't the books on the shelf
+*/
+func (m *Model) ReorderShelf(shelves initialisers.IShelfCollection, shelfID string, bookIDs []string) (*data.Shelf, error) {
+	shelf, err := shelves.Get(shelfID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bookIDs) != len(shelf.BookIDs) {
+		return nil, &ValidationError{Field: "bookIds", Message: fmt.Sprintf("must list the %d books on the shelf", len(shelf.BookIDs))}
+	}
+
+	seen := make(map[string]bool, len(bookIDs))
+	for _, id := range bookIDs {
+		if seen[id] || shelfIndex(shelf, id) < 0 {
+			return nil, &ValidationError{Field: "bookIds", Message: fmt.Sprintf("%q is repeated or not on the shelf", id)}
+		}
+		seen[id] = true
+	}
+
+	shelf.BookIDs = bookIDs
+	shelf.Version++
+
+	if err := shelves.Update(shelf); err != nil {
+		return nil, err
+	}
+	return shelf, nil
+}
+
+func shelfIndex(shelf *data.Shelf, bookID string) int {
+	for i, id := range shelf.BookIDs {
+		if id == bookID {
+			return i
+		}
+	}
+
+	return -1
+}
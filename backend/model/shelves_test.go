@@ -0,0 +1,89 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"reflect"
+	"testing"
+)
+
+func TestShelfOrdering(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Mort"},
+		"b2": {ID: "b2", Title: "Sourcery"},
+		"b3": {ID: "b3", Title: "Wyrd Sisters"},
+	}}
+	shelves := memoryShelves{"s1": {ID: "s1", Name: "Book club", BookIDs: []string{}}}
+
+	model.AddToShelf(shelves, books, "s1", "b1", 0)
+	model.AddToShelf(shelves, books, "s1", "b2", 0)
+
+	shelf, err := model.AddToShelf(shelves, books, "s1", "b3", 1)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if expected := []string{"b3", "b1", "b2"}; !reflect.DeepEqual(shelf.BookIDs, expected) {
+		t.Errorf("got %v, expected %v", shelf.BookIDs, expected)
+	}
+
+	if _, err := model.AddToShelf(shelves, books, "s1", "b1", 0); !errors.Is(err, initialisers.ErrDuplicateRecord) {
+		t.Errorf("got error %v, expected ErrDuplicateRecord for a book already on the shelf", err)
+	}
+
+	if _, err := model.AddToShelf(shelves, books, "s1", "missing", 0); !errors.Is(err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected ErrRecordNotFound for an unknown book", err)
+	}
+
+	var validationErr *ValidationError
+	if _, err := model.ReorderShelf(shelves, "s1", []string{"b1", "b1", "b2"}); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError for a repeated book", err)
+	}
+
+	if _, err := model.ReorderShelf(shelves, "s1", []string{"b2", "b1", "b3"}); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	model.RemoveFromShelf(shelves, "s1", "b1")
+	delete(books.books, "b3")
+
+	shelf, err = model.GetShelf(shelves, books, "s1")
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	var titles []string
+	for _, book := range shelf.Books {
+		titles = append(titles, book.Title)
+	}
+
+	if expected := []string{"Sourcery"}; !reflect.DeepEqual(titles, expected) {
+		t.Errorf("got %v, expected %v without removed or deleted books", titles, expected)
+	}
+}
+
+func TestShelfUpdateFromStaleCopy(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Mort"},
+		"b2": {ID: "b2", Title: "Sourcery"},
+	}}
+	shelves := memoryShelves{"s1": {ID: "s1", Name: "Book club", BookIDs: []string{}, Version: 1}}
+
+	stale, _ := shelves.Get("s1")
+
+	if _, err := model.AddToShelf(shelves, books, "s1", "b1", 0); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	stale.BookIDs = append(stale.BookIDs, "b2")
+	stale.Version++
+
+	if err := model.UpdateShelf(shelves, stale); !errors.Is(err, initialisers.ErrEditConflict) {
+		t.Errorf("got error %v, expected ErrEditConflict for a change made from a stale copy", err)
+	}
+
+	if shelf, _ := shelves.Get("s1"); !reflect.DeepEqual(shelf.BookIDs, []string{"b1"}) {
+		t.Errorf("got %v, expected the concurrent change to be kept", shelf.BookIDs)
+	}
+}
@@ -0,0 +1,518 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strings"
+)
+
+// tagSuggestions is how many tags autocomplete returns.
+const tagSuggestions = 10
+
+/*
+Calls the DB to list every tag, ordered by name, with the number of books that have each.
+
+Returns:
+
+	return1: slice of a pointer of tags
+	return2: error
+*/
+func (m *Model) GetTags(tags initialisers.ITagCollection, books initialisers.IBookCollection) ([]*data.Tag, error) {
+	all, err := tags.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := countTagUsage(books, all); err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+/*
+Lists the tag taxonomy as a tree: the top-level tags, ordered by name, each with its children nested inside.
+
+Returns:
+
+	return1: slice of a pointer of top-level tags
+	return2: error
+*/
+func (m *Model) GetTagTree(tags initialisers.ITagCollection, books initialisers.IBookCollection) ([]*data.Tag, error) {
+	all, err := m.GetTags(tags, books)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*data.Tag, len(all))
+	for _, tag := range all {
+		byID[tag.ID] = tag
+	}
+
+	roots := []*data.Tag{}
+	for _, tag := range all {
+		if parent, ok := byID[tag.ParentID]; ok {
+			parent.Children = append(parent.Children, tag)
+		} else {
+			roots = append(roots, tag)
+		}
+	}
+
+	return roots, nil
+}
+
+/*
+Calls the DB to autocomplete a tag name.
+
+Parameters:
+
+	param1: prefix string, the start of the name typed so far
+
+Returns:
+
+	return1: slice of a pointer of tags, at most tagSuggestions
+	return2: error
+*/
+func (m *Model) SuggestTags(tags initialisers.ITagCollection, prefix string) ([]*data.Tag, error) {
+	return tags.GetByPrefix(normaliseTag(prefix), tagSuggestions)
+}
+
+/*
+Calls the DB to find a tag, with the number of books that have it.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: pointer of tag data
+	return2: error
+*/
+func (m *Model) GetTag(tags initialisers.ITagCollection, books initialisers.IBookCollection, id string) (*data.Tag, error) {
+	tag, err := tags.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := countTagUsage(books, []*data.Tag{tag}); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+/*
+Calls the DB to add a tag to the taxonomy.
+
+Parameters:
+
+	param1: input TagInput
+
+Returns:
+
+	return1: interface{}, ID of the inserted document
+	return2: pointer of tag data
+	return3: error, a *ValidationError when the name is invalid or the parent is unknown,
+	ErrDuplicateRecord when the name is taken
+*/
+func (m *Model) CreateTag(tags initialisers.ITagCollection, input TagInput) (interface{}, *data.Tag, error) {
+	tag := &data.Tag{
+		Name:     input.Name,
+		ParentID: input.ParentID,
+		Version:  1,
+	}
+
+	if err := validateTag(tags, tag); err != nil {
+		return nil, nil, err
+	}
+
+	id, err := tags.Create(tag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return id, tag, nil
+}
+
+/*
+Calls the DB to rename or move a tag. Renaming a tag rewrites the books that have it, in one transaction,
+before the tag itself is renamed, so renaming it again after a partial failure finishes the job.
+
+Parameters:
+
+	param1: pointer of tag data, with the new name and parent
+
+Returns:
+
+	return1: error, a *ValidationError when the name is invalid or the parent is unknown or
+	one of the tag's descendants, ErrDuplicateRecord when the name is taken
+*/
+func (m *Model) UpdateTag(tags initialisers.ITagCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, tag *data.Tag) error {
+	stored, err := tags.Get(tag.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateTag(tags, tag); err != nil {
+		return err
+	}
+
+	if tag.Name != stored.Name {
+		// The books can't be rewritten to a name another tag already has
+		all, err := tags.GetAll()
+		if err != nil {
+			return err
+		}
+
+		for _, other := range all {
+			if other.ID != tag.ID && other.Name == tag.Name {
+				return fmt.Errorf("%w: tag %q already exists", initialisers.ErrDuplicateRecord, tag.Name)
+			}
+		}
+
+		err = rewriteBooks(transactor, books, func(books initialisers.IBookCollection) error {
+			return retagBooks(books, stored.Name, tag.Name)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tags.Update(tag)
+}
+
+/*
+MergeTags folds tags into the target: their books are tagged with the target instead, their children
+move under the target and they are deleted.
+The books are rewritten in one transaction first and the merged tags deleted last, so merging again after
+a partial failure finishes the job.
+
+Parameters:
+
+	param1: targetID string, the tag to keep
+	param2: sourceIDs []string, the tags to merge into it
+
+Returns:
+
+	return1: pointer of the merged tag data
+	return2: error
+*/
+func (m *Model) MergeTags(tags initialisers.ITagCollection, books initialisers.IBookCollection, transactor initialisers.ITransactor, targetID string, sourceIDs []string) (*data.Tag, error) {
+	if len(sourceIDs) == 0 {
+		return nil, &ValidationError{Field: "sourceIds", Message: "must list at least one tag"}
+	}
+
+	all, err := tags.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*data.Tag, len(all))
+	for _, tag := range all {
+		byID[tag.ID] = tag
+	}
+
+	target, ok := byID[targetID]
+	if !ok {
+		return nil, fmt.Errorf("%w: no tag %s", initialisers.ErrRecordNotFound, targetID)
+	}
+
+	merged := make(map[string]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return nil, &ValidationError{Field: "sourceIds", Message: "cannot merge a tag into itself"}
+		}
+
+		if _, ok := byID[id]; !ok {
+			return nil, fmt.Errorf("%w: no tag %s", initialisers.ErrRecordNotFound, id)
+		}
+		merged[id] = true
+	}
+
+	err = rewriteBooks(transactor, books, func(books initialisers.IBookCollection) error {
+		for _, id := range sourceIDs {
+			if err := retagBooks(books, byID[id].Name, target.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// When the target sits below a merged tag, it takes the place of the nearest ancestor that is kept.
+	for merged[target.ParentID] {
+		target.ParentID = byID[target.ParentID].ParentID
+	}
+
+	for _, tag := range all {
+		if tag == target || merged[tag.ID] {
+			continue
+		}
+
+		if merged[tag.ParentID] {
+			tag.ParentID = target.ID
+			tag.Version++
+
+			if err := tags.Update(tag); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	target.Version++
+	if err := tags.Update(target); err != nil {
+		return nil, err
+	}
+
+	for _, id := range sourceIDs {
+		if err := tags.Delete(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := countTagUsage(books, []*data.Tag{target}); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+/*
+Calls the DB to delete a tag. It is removed from every book and its children move up to its parent.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: error
+*/
+func (m *Model) DeleteTag(tags initialisers.ITagCollection, books initialisers.IBookCollection, id string) error {
+	tag, err := tags.Get(id)
+	if err != nil {
+		return err
+	}
+
+	all, err := tags.GetAll()
+	if err != nil {
+		return err
+	}
+
+	for _, child := range all {
+		if child.ParentID != tag.ID {
+			continue
+		}
+
+		child.ParentID = tag.ParentID
+		child.Version++
+
+		if err := tags.Update(child); err != nil {
+			return err
+		}
+	}
+
+	if err := retagBooks(books, tag.Name, ""); err != nil {
+		return err
+	}
+
+	return tags.Delete(id)
+}
+
+/*
+Sets the tags of a book, replacing any it had.
+
+Parameters:
+
+	param1: bookID string
+	param2: names []string, names of tags in the taxonomy
+
+Returns:
+
+	return1: pointer of the updated book data
+	return2: error, a *ValidationError when a name is not in the taxonomy
+*/
+func (m *Model) SetBookTags(tags initialisers.ITagCollection, books initialisers.IBookCollection, bookID string, names []string) (*data.Book, error) {
+	book, err := books.Get(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := tags.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(all))
+	for _, tag := range all {
+		known[tag.Name] = true
+	}
+
+	book.Tags = []string{}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = normaliseTag(name)
+		if name == "" || seen[name] {
+			continue
+		}
+
+		if !known[name] {
+			return nil, &ValidationError{Field: "tags", Message: fmt.Sprintf("%q is not a known tag", name)}
+		}
+
+		seen[name] = true
+		book.Tags = append(book.Tags, name)
+	}
+
+	book.Version++
+
+	if err := books.Update(book); err != nil {
+		return nil, err
+	}
+
+	fillPercent(book)
+
+	return book, nil
+}
+
+/*
+Expands a tag to its own name and the names of every tag below it, so filtering by a tag
+also finds books with a narrower one.
+
+Parameters:
+
+	param1: name string
+
+Returns:
+
+	return1: []string, names of the tag and its descendants
+	return2: error, a *ValidationError when the name is not in the taxonomy
+*/
+func (m *Model) ExpandTag(tags initialisers.ITagCollection, name string) ([]string, error) {
+	name = normaliseTag(name)
+
+	all, err := tags.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]*data.Tag, len(all))
+	var root *data.Tag
+	for _, tag := range all {
+		children[tag.ParentID] = append(children[tag.ParentID], tag)
+		if tag.Name == name {
+			root = tag
+		}
+	}
+
+	if root == nil {
+		return nil, &ValidationError{Field: "tag", Message: fmt.Sprintf("%q is not a known tag", name)}
+	}
+
+	names := []string{}
+	pending := []*data.Tag{root}
+	for len(pending) > 0 {
+		tag := pending[0]
+		pending = pending[1:]
+
+		names = append(names, tag.Name)
+		pending = append(pending, children[tag.ID]...)
+	}
+
+	return names, nil
+}
+
+// validateTag tidies the name of a tag and checks its parent exists and is not the tag or one of its descendants.
+func validateTag(tags initialisers.ITagCollection, tag *data.Tag) error {
+	tag.Name = normaliseTag(tag.Name)
+
+	if tag.Name == "" {
+		return &ValidationError{Field: "name", Message: "must be provided"}
+	}
+
+	if strings.Contains(tag.Name, ",") {
+		return &ValidationError{Field: "name", Message: "must not contain a comma"}
+	}
+
+	seen := make(map[string]bool)
+	for parentID := tag.ParentID; parentID != ""; {
+		if parentID == tag.ID || seen[parentID] {
+			return &ValidationError{Field: "parentId", Message: "must not be the tag itself or one of its descendants"}
+		}
+
+		parent, err := tags.Get(parentID)
+		if errors.Is(err, initialisers.ErrRecordNotFound) {
+			return &ValidationError{Field: "parentId", Message: "must be an existing tag"}
+		}
+		if err != nil {
+			return err
+		}
+
+		seen[parentID] = true
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
+
+// countTagUsage sets the Usage of each tag to the number of books that have it.
+func countTagUsage(books initialisers.IBookCollection, tags []*data.Tag) error {
+	all, err := books.GetAll()
+	if err != nil {
+		return err
+	}
+
+	usage := make(map[string]int)
+	for _, book := range all {
+		for _, name := range book.Tags {
+			usage[name]++
+		}
+	}
+
+	for _, tag := range tags {
+		tag.Usage = usage[tag.Name]
+	}
+
+	return nil
+}
+
+/*
+retagBooks replaces a tag name on every book that has it, including those in the trash, keeping its position
+in the book's list. An empty replacement removes the tag.
+*/
+func retagBooks(books initialisers.IBookCollection, name, replacement string) error {
+	tagged, err := books.GetFiltered(data.BookFilter{Tags: []string{name}, IncludeDeleted: true})
+	if err != nil {
+		return err
+	}
+
+	for _, book := range tagged {
+		names := []string{}
+		seen := make(map[string]bool)
+
+		for _, tag := range book.Tags {
+			if tag == name {
+				tag = replacement
+			}
+
+			if tag != "" && !seen[tag] {
+				seen[tag] = true
+				names = append(names, tag)
+			}
+		}
+
+		book.Tags = names
+		book.Version++
+
+		if err := books.Update(book); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTaxonomy creates fiction > fantasy > epic fantasy and fiction > science fiction.
+func newTaxonomy(t *testing.T, tags memoryTags) map[string]*data.Tag {
+	t.Helper()
+
+	created := map[string]*data.Tag{}
+	for _, input := range []struct{ name, parent string }{
+		{" Fiction", ""}, {"fantasy", "fiction"}, {"epic fantasy", "fantasy"}, {"science fiction", "fiction"},
+	} {
+		parentID := ""
+		if input.parent != "" {
+			parentID = created[input.parent].ID
+		}
+
+		_, tag, err := model.CreateTag(tags, TagInput{Name: input.name, ParentID: parentID})
+		if err != nil {
+			t.Fatalf("got error %v creating %q, expected nil", err, input.name)
+		}
+		created[tag.Name] = tag
+	}
+
+	return created
+}
+
+func TestCreateTag(t *testing.T) {
+	tags := memoryTags{}
+	created := newTaxonomy(t, tags)
+
+	if _, ok := created["fiction"]; !ok {
+		t.Errorf("got %v, expected names to be trimmed and lowercased", created)
+	}
+
+	var validationErr *ValidationError
+	for _, input := range []TagInput{{Name: " "}, {Name: "a, b"}, {Name: "poetry", ParentID: "missing"}} {
+		if _, _, err := model.CreateTag(tags, input); !errors.As(err, &validationErr) {
+			t.Errorf("got error %v for %+v, expected a ValidationError", err, input)
+		}
+	}
+}
+
+func TestUpdateTag(t *testing.T) {
+	tags := memoryTags{}
+	created := newTaxonomy(t, tags)
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Tags: []string{"fantasy", "classic"}},
+		"b2": {ID: "b2", Tags: []string{"science fiction"}},
+	}}
+
+	var validationErr *ValidationError
+	moved := *created["fiction"]
+	moved.ParentID = created["epic fantasy"].ID
+	if err := model.UpdateTag(tags, books, memoryTransactor{books}, &moved); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError for moving a tag below its descendant", err)
+	}
+
+	renamed := *created["fantasy"]
+	renamed.Name = "Fantasy Fiction"
+	if err := model.UpdateTag(tags, books, memoryTransactor{books}, &renamed); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if got := books.books["b1"].Tags; !reflect.DeepEqual(got, []string{"fantasy fiction", "classic"}) {
+		t.Errorf("got tags %v, expected the renamed tag in place", got)
+	}
+
+	if got := books.books["b2"].Tags; !reflect.DeepEqual(got, []string{"science fiction"}) {
+		t.Errorf("got tags %v, expected other books untouched", got)
+	}
+
+	taken := *created["fantasy"]
+	taken.Name = "science fiction"
+	if err := model.UpdateTag(tags, books, memoryTransactor{books}, &taken); !errors.Is(err, initialisers.ErrDuplicateRecord) {
+		t.Errorf("got error %v, expected ErrDuplicateRecord for a taken name", err)
+	}
+
+	if got := books.books["b1"].Tags; !reflect.DeepEqual(got, []string{"fantasy fiction", "classic"}) {
+		t.Errorf("got tags %v, expected books untouched when the name is taken", got)
+	}
+}
+
+func TestMergeTagsWithoutTransactions(t *testing.T) {
+	tags := memoryTags{}
+	created := newTaxonomy(t, tags)
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Tags: []string{"fantasy"}},
+	}}
+
+	// A standalone server can't run transactions: the books are rewritten without one
+	_, err := model.MergeTags(tags, books, unsupportedTransactor{}, created["science fiction"].ID, []string{created["fantasy"].ID})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if got := books.books["b1"].Tags; !reflect.DeepEqual(got, []string{"science fiction"}) {
+		t.Errorf("got tags %v, expected the merged tag", got)
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	deleted := time.Now()
+	tags := memoryTags{}
+	created := newTaxonomy(t, tags)
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Tags: []string{"fantasy", "science fiction"}},
+		"b2": {ID: "b2", Tags: []string{"fantasy"}},
+		"b3": {ID: "b3", Tags: []string{"fantasy"}, DeletedAt: &deleted},
+	}}
+
+	target, err := model.MergeTags(tags, books, memoryTransactor{books}, created["science fiction"].ID, []string{created["fantasy"].ID})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if target.Usage != 2 {
+		t.Errorf("got usage %d, expected both books to have the merged tag", target.Usage)
+	}
+
+	if got := books.books["b1"].Tags; !reflect.DeepEqual(got, []string{"science fiction"}) {
+		t.Errorf("got tags %v, expected the merged tag once", got)
+	}
+
+	if got := books.books["b3"].Tags; !reflect.DeepEqual(got, []string{"science fiction"}) {
+		t.Errorf("got tags %v, expected the book in the trash retagged too", got)
+	}
+
+	if _, ok := tags[created["fantasy"].ID]; ok {
+		t.Error("expected the merged tag to be deleted")
+	}
+
+	if got := tags[created["epic fantasy"].ID].ParentID; got != target.ID {
+		t.Errorf("got parent %q, expected the children of the merged tag to move under %q", got, target.ID)
+	}
+
+	if _, err := model.MergeTags(tags, books, memoryTransactor{books}, target.ID, []string{target.ID}); err == nil {
+		t.Error("got nil, expected an error merging a tag into itself")
+	}
+}
+
+func TestMergeTagsIntoDescendant(t *testing.T) {
+	tags := memoryTags{}
+	created := newTaxonomy(t, tags)
+	books := memoryBooks{books: map[string]*data.Book{}}
+
+	target, err := model.MergeTags(tags, books, memoryTransactor{books}, created["epic fantasy"].ID, []string{created["fantasy"].ID})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if target.ParentID != created["fiction"].ID {
+		t.Errorf("got parent %q, expected the target to take the merged tag's place under fiction", target.ParentID)
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	tags := memoryTags{}
+	created := newTaxonomy(t, tags)
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Tags: []string{"classic", "fantasy"}},
+	}}
+
+	if err := model.DeleteTag(tags, books, created["fantasy"].ID); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if got := books.books["b1"].Tags; !reflect.DeepEqual(got, []string{"classic"}) {
+		t.Errorf("got tags %v, expected the deleted tag removed", got)
+	}
+
+	if got := tags[created["epic fantasy"].ID].ParentID; got != created["fiction"].ID {
+		t.Errorf("got parent %q, expected children to move up to fiction", got)
+	}
+}
+
+func TestExpandTag(t *testing.T) {
+	tags := memoryTags{}
+	newTaxonomy(t, tags)
+
+	names, err := model.ExpandTag(tags, "Fantasy")
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	sort.Strings(names)
+	if expected := []string{"epic fantasy", "fantasy"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("got %v, expected %v", names, expected)
+	}
+
+	var validationErr *ValidationError
+	if _, err := model.ExpandTag(tags, "poetry"); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError for an unknown tag", err)
+	}
+}
+
+func TestSetBookTags(t *testing.T) {
+	tags := memoryTags{}
+	newTaxonomy(t, tags)
+	books := memoryBooks{books: map[string]*data.Book{"b1": {ID: "b1"}}}
+
+	var validationErr *ValidationError
+	if _, err := model.SetBookTags(tags, books, "b1", []string{"fantasy", "poetry"}); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, expected a ValidationError for a tag outside the taxonomy", err)
+	}
+
+	book, err := model.SetBookTags(tags, books, "b1", []string{"Fantasy", "fantasy", "science fiction"})
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if expected := []string{"fantasy", "science fiction"}; !reflect.DeepEqual(book.Tags, expected) {
+		t.Errorf("got %v, expected %v", book.Tags, expected)
+	}
+
+	all, _ := model.GetTags(tags, books)
+	for _, tag := range all {
+		if tag.Name == "fantasy" && tag.Usage != 1 {
+			t.Errorf("got usage %d for fantasy, expected 1", tag.Usage)
+		}
+	}
+
+	tree, _ := model.GetTagTree(tags, books)
+	if len(tree) != 1 || tree[0].Name != "fiction" || len(tree[0].Children) != 2 {
+		t.Errorf("got %v, expected fiction at the top with two children", tree)
+	}
+}
@@ -0,0 +1,186 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"time"
+)
+
+/*
+Calls the DB to list the books in the trash, the most recently deleted first.
+
+Returns:
+
+	return1: slice of a pointer of books
+	return2: error
+*/
+func (m *Model) GetTrash(db initialisers.IBookCollection) ([]*data.Book, error) {
+	books, err := db.GetDeleted()
+	if err != nil {
+		return nil, err
+	}
+	fillPercent(books...)
+	return books, nil
+}
+
+/*
+Takes a book out of the trash. It comes back as it was deleted, with its shelves, reviews and notes,
+unless another book has been given its ISBN in the meantime.
+
+Parameters:
+
+	param1: id string
+
+Returns:
+
+	return1: pointer of the restored book
+	return2: error, ErrRecordNotFound when the book is not in the trash, ErrDuplicateRecord when another book has its ISBN
+*/
+func (m *Model) RestoreBook(db initialisers.IBookCollection, id string) (*data.Book, error) {
+	trash, err := db.GetDeleted()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, book := range trash {
+		if book.ID != id || book.ISBN13 == "" {
+			continue
+		}
+
+		holder, err := db.GetByISBN(book.Owner, book.ISBN13)
+		if err == nil {
+			return nil, fmt.Errorf("%w: %q now has ISBN %s", initialisers.ErrDuplicateRecord, holder.Title, book.ISBN13)
+		}
+		if !errors.Is(err, initialisers.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if err := db.Restore(id); err != nil {
+		return nil, err
+	}
+
+	return m.Get(db, id)
+}
+
+/*
+BookDependents are the collections holding records that belong to a book, removed with it when it is purged.
+*/
+type BookDependents struct {
+	Shelves    initialisers.IShelfCollection
+	Reviews    initialisers.IReviewCollection
+	Notes      initialisers.INoteCollection
+	Highlights initialisers.IHighlightCollection
+	Sessions   initialisers.IReadingSessionCollection
+}
+
+/*
+Calls the DB to permanently remove a book from the trash, with its reviews, notes, highlights and reading sessions,
+and takes it off its shelves.
+
+Parameters:
+
+	param1: dependents BookDependents
+	param2: id string
+
+Returns:
+
+	return1: error, ErrRecordNotFound when the book is not in the trash
+*/
+func (m *Model) PurgeBook(db initialisers.IBookCollection, dependents BookDependents, id string) error {
+	trash, err := db.GetDeleted()
+	if err != nil {
+		return err
+	}
+
+	for _, book := range trash {
+		if book.ID == id {
+			return purgeWithDependents(db, dependents, id)
+		}
+	}
+
+	return initialisers.ErrRecordNotFound
+}
+
+/*
+Permanently removes the books that have been in the trash for longer than retention, with their dependents as
+PurgeBook does. A retention of 0 empties the trash.
+
+Parameters:
+
+	param1: dependents BookDependents
+	param2: retention time.Duration
+	param3: now time.Time
+
+Returns:
+
+	return1: number of books removed
+	return2: error, a *ValidationError when retention is negative
+*/
+func (m *Model) PurgeTrash(db initialisers.IBookCollection, dependents BookDependents, retention time.Duration, now time.Time) (int64, error) {
+	if retention < 0 {
+		return 0, &ValidationError{Field: "retention", Message: "must not be negative"}
+	}
+
+	// Books deleted at this very instant are purged too when the trash is emptied
+	cutoff := now.Add(-retention)
+	if retention == 0 {
+		cutoff = cutoff.Add(time.Nanosecond)
+	}
+
+	trash, err := db.GetDeleted()
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, book := range trash {
+		if !book.DeletedAt.Before(cutoff) {
+			continue
+		}
+
+		if err := purgeWithDependents(db, dependents, book.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+/*
+purgeWithDependents removes what belongs to a book in the trash before the book itself, so purging it again after a partial
+failure finishes the job.
+*/
+func purgeWithDependents(db initialisers.IBookCollection, dependents BookDependents, id string) error {
+	shelves, err := dependents.Shelves.GetByBook(id)
+	if err != nil {
+		return err
+	}
+
+	for _, shelf := range shelves {
+		if i := shelfIndex(shelf, id); i >= 0 {
+			shelf.BookIDs = append(shelf.BookIDs[:i], shelf.BookIDs[i+1:]...)
+			shelf.Version++
+
+			if err := dependents.Shelves.Update(shelf); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, deleteByBook := range []func(string) error{
+		dependents.Reviews.DeleteByBook,
+		dependents.Notes.DeleteByBook,
+		dependents.Highlights.DeleteByBook,
+		dependents.Sessions.DeleteByBook,
+	} {
+		if err := deleteByBook(id); err != nil {
+			return err
+		}
+	}
+
+	return db.Purge(id)
+}
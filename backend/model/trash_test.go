@@ -0,0 +1,135 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"testing"
+	"time"
+)
+
+func TestTrash(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma", Pages: 400, CurrentPage: 100},
+		"b2": {ID: "b2", Title: "Persuasion"},
+	}}
+
+	if err := model.Delete(books, "b1"); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if all, _ := model.GetAll(books); len(all) != 1 || all[0].ID != "b2" {
+		t.Errorf("got %v, expected the deleted book left out", all)
+	}
+
+	if _, err := model.Get(books, "b1"); !errors.Is(err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected ErrRecordNotFound", err)
+	}
+
+	trash, err := model.GetTrash(books)
+	if err != nil || len(trash) != 1 || trash[0].ID != "b1" || trash[0].DeletedAt == nil || trash[0].Percent != 25 {
+		t.Fatalf("got %v, %v, expected Emma in the trash", trash, err)
+	}
+
+	book, err := model.RestoreBook(books, "b1")
+	if err != nil || book.Title != "Emma" || book.DeletedAt != nil {
+		t.Fatalf("got %+v, %v, expected Emma restored", book, err)
+	}
+
+	if _, err := model.RestoreBook(books, "b1"); !errors.Is(err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected ErrRecordNotFound restoring a book that isn't in the trash", err)
+	}
+}
+
+func TestRestoreBookWithTakenISBN(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{"b1": {ID: "b1", Title: "Emma", ISBN13: "9780141439587"}}}
+
+	_ = model.Delete(books, "b1")
+
+	if _, _, err := model.Insert(books, Input{Title: "Emma (Penguin Classics)", ISBN13: "9780141439587"}); err != nil {
+		t.Fatalf("got error %v, expected the ISBN of a book in the trash to be free", err)
+	}
+
+	if _, err := model.RestoreBook(books, "b1"); !errors.Is(err, initialisers.ErrDuplicateRecord) {
+		t.Errorf("got error %v, expected ErrDuplicateRecord restoring a book whose ISBN is taken", err)
+	}
+
+	if trash, _ := model.GetTrash(books); len(trash) != 1 {
+		t.Errorf("got %v, expected the book left in the trash", trash)
+	}
+}
+
+func TestPurgeBook(t *testing.T) {
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma"},
+		"b2": {ID: "b2", Title: "Persuasion"},
+	}}
+	dependents := BookDependents{
+		Shelves:    memoryShelves{"s1": {ID: "s1", Name: "Austen", BookIDs: []string{"b1", "b2"}, Version: 1}},
+		Reviews:    memoryReviews{"r1": {ID: "r1", BookID: "b1"}, "r2": {ID: "r2", BookID: "b2"}},
+		Notes:      memoryNotes{"n1": {ID: "n1", BookID: "b1"}},
+		Highlights: memoryHighlights{"h1": {ID: "h1", BookID: "b1"}},
+		Sessions:   memorySessions{"s1": {ID: "s1", BookID: "b1"}},
+	}
+
+	if err := model.PurgeBook(books, dependents, "b1"); !errors.Is(err, initialisers.ErrRecordNotFound) {
+		t.Errorf("got error %v, expected ErrRecordNotFound purging a book that isn't in the trash", err)
+	}
+
+	_ = model.Delete(books, "b1")
+
+	if err := model.PurgeBook(books, dependents, "b1"); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if books.books["b1"] != nil || books.books["b2"] == nil {
+		t.Errorf("got %v, expected only Emma gone", books.books)
+	}
+
+	if shelf, _ := dependents.Shelves.Get("s1"); len(shelf.BookIDs) != 1 || shelf.BookIDs[0] != "b2" {
+		t.Errorf("got %v, expected Emma taken off the shelf", shelf.BookIDs)
+	}
+
+	reviews := dependents.Reviews.(memoryReviews)
+	if len(reviews) != 1 || reviews["r2"] == nil {
+		t.Errorf("got %v, expected only the review of Persuasion kept", reviews)
+	}
+
+	if len(dependents.Notes.(memoryNotes)) != 0 || len(dependents.Highlights.(memoryHighlights)) != 0 || len(dependents.Sessions.(memorySessions)) != 0 {
+		t.Error("expected the notes, highlights and reading sessions of Emma removed")
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	old, recent := now.Add(-31*24*time.Hour), now.Add(-time.Hour)
+
+	books := memoryBooks{books: map[string]*data.Book{
+		"b1": {ID: "b1", Title: "Emma", DeletedAt: &old},
+		"b2": {ID: "b2", Title: "Persuasion", DeletedAt: &recent},
+		"b3": {ID: "b3", Title: "Sanditon"},
+	}}
+	dependents := BookDependents{memoryShelves{}, memoryReviews{}, memoryNotes{}, memoryHighlights{}, memorySessions{
+		"s1": {ID: "s1", BookID: "b1"},
+		"s2": {ID: "s2", BookID: "b2"},
+	}}
+
+	purged, err := model.PurgeTrash(books, dependents, 30*24*time.Hour, now)
+	if err != nil || purged != 1 || books.books["b1"] != nil || books.books["b2"] == nil {
+		t.Errorf("got %d, %v, expected only the book deleted over 30 days ago purged", purged, err)
+	}
+
+	if sessions := dependents.Sessions.(memorySessions); len(sessions) != 1 || sessions["s2"] == nil {
+		t.Errorf("got %v, expected only the reading sessions of Persuasion kept", sessions)
+	}
+
+	purged, err = model.PurgeTrash(books, dependents, 0, now)
+	if err != nil || purged != 1 || len(books.books) != 1 || books.books["b3"] == nil {
+		t.Errorf("got %d, %v, expected the trash emptied and Sanditon kept", purged, err)
+	}
+
+	var validation *ValidationError
+	if _, err := model.PurgeTrash(books, dependents, -time.Hour, now); !errors.As(err, &validation) {
+		t.Errorf("got error %v, expected a *ValidationError", err)
+	}
+}
@@ -1,5 +1,10 @@
 package model
 
+import (
+	"readinglistapp/initialisers"
+	"time"
+)
+
 type Model struct {
 }
 
@@ -9,10 +14,114 @@ type ResponseHealthCheck struct {
 	Version     string `json:"version"`
 }
 
+type ResponseReadiness struct {
+	Status         string                      `json:"status"`
+	Database       string                      `json:"database"`
+	CircuitBreaker *initialisers.BreakerStatus `json:"circuitBreaker,omitempty"`
+}
+
 type Input struct {
-	Title     string   `json:"title"`
-	Published int      `json:"published"`
-	Pages     int      `json:"pages"`
-	Genres    []string `json:"genres"`
-	Rating    float64  `json:"rating"`
+	Owner       string   `json:"owner"`
+	Title       string   `json:"title"`
+	Authors     []string `json:"authors"`
+	AuthorIDs   []string `json:"authorIds"`
+	ISBN10      string   `json:"isbn10"`
+	ISBN13      string   `json:"isbn13"`
+	Publisher   string   `json:"publisher"`
+	Language    string   `json:"language"`
+	Description string   `json:"description"`
+	Edition     string   `json:"edition"`
+	Series      string   `json:"series"`
+	Published   int      `json:"published"`
+	Pages       int      `json:"pages"`
+	Genres      []string `json:"genres"`
+	Rating      float64  `json:"rating"`
+	Status      string   `json:"status"`
+}
+
+// UpdateInput holds a partial update of a book: only the fields present are changed.
+type UpdateInput struct {
+	Title       *string  `json:"title"`
+	Authors     []string `json:"authors"`
+	AuthorIDs   []string `json:"authorIds"`
+	ISBN10      *string  `json:"isbn10"`
+	ISBN13      *string  `json:"isbn13"`
+	Publisher   *string  `json:"publisher"`
+	Language    *string  `json:"language"`
+	Description *string  `json:"description"`
+	Edition     *string  `json:"edition"`
+	Series      *string  `json:"series"`
+	Published   *int     `json:"published"`
+	Pages       *int     `json:"pages"`
+	Genres      []string `json:"genres"`
+	Rating      *float64 `json:"rating"`
+}
+
+type AuthorInput struct {
+	Name      string   `json:"name"`
+	SortName  string   `json:"sortName"`
+	BirthYear int      `json:"birthYear"`
+	DeathYear int      `json:"deathYear"`
+	Bio       string   `json:"bio"`
+	Aliases   []string `json:"aliases"`
+}
+
+type SeriesInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ProgressInput struct {
+	CurrentPage *int    `json:"currentPage"`
+	Status      *string `json:"status"`
+}
+
+type ReadingSessionInput struct {
+	StartedAt *time.Time `json:"startedAt"`
+	Pages     int        `json:"pages"`
+	Minutes   int        `json:"minutes"`
+}
+
+type StopReadingSessionInput struct {
+	EndPage *int `json:"endPage"`
+	Pages   *int `json:"pages"`
+}
+
+type GoalInput struct {
+	Owner  string `json:"owner"`
+	Metric string `json:"metric"`
+	Year   int    `json:"year"`
+	Month  int    `json:"month"`
+	Target int    `json:"target"`
+}
+
+type ShelfInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ReviewInput struct {
+	Body    string `json:"body"`
+	Spoiler bool   `json:"spoiler"`
+}
+
+type NoteInput struct {
+	Body string `json:"body"`
+}
+
+type HighlightInput struct {
+	Quote    string   `json:"quote"`
+	Page     int      `json:"page"`
+	Location string   `json:"location"`
+	Comment  string   `json:"comment"`
+	Tags     []string `json:"tags"`
+}
+
+type UserInput struct {
+	Name string `json:"name"`
+}
+
+type TagInput struct {
+	Name     string `json:"name"`
+	ParentID string `json:"parentId"`
 }
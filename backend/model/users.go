@@ -0,0 +1,55 @@
+package model
+
+import (
+	"fmt"
+	"readinglistapp/initialisers"
+	"readinglistapp/internal/data"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxUserName is the longest user name, in characters.
+const maxUserName = 100
+
+/*
+Calls the DB to create a user. The name is what books and goals give as their owner.
+
+Parameters:
+
+	param1: input UserInput
+
+Returns:
+
+	return1: interface{}, ID of the inserted document
+	return2: pointer of user data
+	return3: error, a *ValidationError when the name is empty or too long, ErrDuplicateRecord when it is taken
+*/
+func (m *Model) CreateUser(db initialisers.IUserCollection, input UserInput) (interface{}, *data.User, error) {
+	user := &data.User{Name: strings.TrimSpace(input.Name)}
+
+	switch {
+	case user.Name == "":
+		return nil, nil, &ValidationError{Field: "name", Message: "must be provided"}
+	case utf8.RuneCountInString(user.Name) > maxUserName:
+		return nil, nil, &ValidationError{Field: "name", Message: fmt.Sprintf("must not be more than %d characters long", maxUserName)}
+	}
+
+	id, err := db.Create(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return id, user, nil
+}
+
+/*
+Calls the DB to list the users, ordered by name.
+
+Returns:
+
+	return1: slice of a pointer of users
+	return2: error
+*/
+func (m *Model) GetUsers(db initialisers.IUserCollection) ([]*data.User, error) {
+	return db.GetAll()
+}
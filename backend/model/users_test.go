@@ -0,0 +1,28 @@
+package model
+
+import (
+	"errors"
+	"readinglistapp/initialisers"
+	"strings"
+	"testing"
+)
+
+func TestCreateUser(t *testing.T) {
+	users := memoryUsers{}
+
+	_, user, err := model.CreateUser(users, UserInput{Name: " ana "})
+	if err != nil || user.Name != "ana" || users[user.ID] == nil {
+		t.Fatalf("got %+v and error %v, expected ana created with the name trimmed", user, err)
+	}
+
+	if _, _, err := model.CreateUser(users, UserInput{Name: "ana"}); !errors.Is(err, initialisers.ErrDuplicateRecord) {
+		t.Errorf("got error %v, expected a taken name refused", err)
+	}
+
+	for _, name := range []string{"  ", strings.Repeat("a", maxUserName+1)} {
+		var validationErr *ValidationError
+		if _, _, err := model.CreateUser(users, UserInput{Name: name}); !errors.As(err, &validationErr) || validationErr.Field != "name" {
+			t.Errorf("got error %v for %q, expected an invalid name", err, name)
+		}
+	}
+}
@@ -4,14 +4,20 @@ import (
 	"net/http"
 	controller "readinglistapp/controller"
 	"readinglistapp/internal"
+	"readinglistapp/middleware"
 
 	"github.com/gorilla/mux"
 )
 
 /*
-SetUpRoutes configures the router with appropriate handlers for different endpoints.
-It serves static files for UI assets, defines routes for home page, book view, creation,
-health check endpoint, and CRUD operations for books under /v1/books endpoint.
+SetUpRoutes configures the router with appropriate handlers for different endpoints. It serves static
+files for UI assets, defines routes for home page, book view, creation, deletion, author, shelf and
+statistics pages, health and readiness check endpoints, and CRUD, batch, restore and CSV import/export
+operations for books under /v1/books, deleted books under /v1/trash, authors under /v1/authors, series
+under /v1/series, reading statistics under /v1/sessions, reading goals under /v1/goals, shelves under
+/v1/shelves, note search under /v1/notes, highlights under /v1/highlights, the tag taxonomy under
+/v1/tags, library statistics under /v1/stats and backup and restore under /v1/admin, which need the admin
+token.
 
 Parameters:
 
@@ -24,36 +30,350 @@ func SetUpRoutes(router *mux.Router, app internal.IApp) {
 	router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", fileServer))
 
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		controller.Home(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+		controller.Home(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetGoalCollection(), app.GetTagCollection())
 	})
 	router.HandleFunc("/book/view", func(w http.ResponseWriter, r *http.Request) {
-		controller.BookView(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+		controller.BookView(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetReviewCollection(), app.GetNoteCollection())
 	})
 	router.HandleFunc("/book/create", func(w http.ResponseWriter, r *http.Request) {
-		controller.BookCreate(w, r, app.GetView())
+		controller.BookCreate(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+	})
+	router.HandleFunc("/book/delete", func(w http.ResponseWriter, r *http.Request) {
+		controller.BookDelete(w, r, app.GetModel(), app.GetBookCollection())
+	})
+
+	router.HandleFunc("/authors", func(w http.ResponseWriter, r *http.Request) {
+		controller.AuthorList(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection())
+	})
+	router.HandleFunc("/author/view", func(w http.ResponseWriter, r *http.Request) {
+		controller.AuthorView(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection(), app.GetBookCollection())
+	})
+
+	router.HandleFunc("/shelves", func(w http.ResponseWriter, r *http.Request) {
+		controller.ShelfList(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection())
+	})
+	router.HandleFunc("/shelf/view", func(w http.ResponseWriter, r *http.Request) {
+		controller.ShelfView(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection(), app.GetBookCollection())
+	})
+	router.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		controller.StatsView(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
 	})
 
 	router.HandleFunc("/v1/healthcheck", func(w http.ResponseWriter, r *http.Request) {
-		controller.HealthCheck(w, r, app.GetView())
+		controller.HealthCheck(w, r, app.GetView(), app.GetConfig())
+	})
+
+	router.HandleFunc("/v1/readiness", func(w http.ResponseWriter, r *http.Request) {
+		controller.Readiness(w, r, app.GetView(), app.GetDB(), app.GetBookCollection())
 	})
 
 	router.HandleFunc("/v1/books", func(w http.ResponseWriter, r *http.Request) {
-		controller.GetBooksHandler(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+		controller.GetBooksHandler(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetTagCollection())
 	}).Methods(http.MethodGet)
 
 	router.HandleFunc("/v1/books", func(w http.ResponseWriter, r *http.Request) {
-		controller.CreateBooksHandler(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+		controller.CreateBooksHandler(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetAuthorCollection())
 	}).Methods(http.MethodPost)
 
+	router.HandleFunc("/v1/books/batch", func(w http.ResponseWriter, r *http.Request) {
+		controller.BatchBooks(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetAuthorCollection(), app.GetTransactor())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/books/export.csv", func(w http.ResponseWriter, r *http.Request) {
+		controller.ExportBooksCSV(w, r, app.GetModel(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/books/import", func(w http.ResponseWriter, r *http.Request) {
+		controller.ImportBooksCSV(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetShelfCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/books/isbn/{isbn}", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetBookByISBN(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
 	router.HandleFunc("/v1/books/{id}", func(w http.ResponseWriter, r *http.Request) {
 		controller.GetBook(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
 	}).Methods(http.MethodGet)
 
 	router.HandleFunc("/v1/books/{id}", func(w http.ResponseWriter, r *http.Request) {
-		controller.UpdateBook(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+		controller.UpdateBook(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetAuthorCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/books/{id}/progress", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateBookProgress(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+	}).Methods(http.MethodPatch)
+
+	router.HandleFunc("/v1/books/{id}/sessions", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetBookSessions(w, r, app.GetView(), app.GetModel(), app.GetReadingSessionCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/books/{id}/sessions", func(w http.ResponseWriter, r *http.Request) {
+		controller.LogSession(w, r, app.GetView(), app.GetModel(), app.GetReadingSessionCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/books/{id}/sessions/start", func(w http.ResponseWriter, r *http.Request) {
+		controller.StartSession(w, r, app.GetView(), app.GetModel(), app.GetReadingSessionCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/books/{id}/sessions/stop", func(w http.ResponseWriter, r *http.Request) {
+		controller.StopSession(w, r, app.GetView(), app.GetModel(), app.GetReadingSessionCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/books/{id}/shelves", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetBookShelves(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/books/{id}/reviews", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetReviews(w, r, app.GetView(), app.GetModel(), app.GetReviewCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/books/{id}/reviews", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateReview(w, r, app.GetView(), app.GetModel(), app.GetReviewCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/books/{id}/reviews/{reviewId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateReview(w, r, app.GetView(), app.GetModel(), app.GetReviewCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/books/{id}/reviews/{reviewId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.DeleteReview(w, r, app.GetView(), app.GetModel(), app.GetReviewCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/books/{id}/notes", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetNotes(w, r, app.GetView(), app.GetModel(), app.GetNoteCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/books/{id}/notes", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateNote(w, r, app.GetView(), app.GetModel(), app.GetNoteCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/books/{id}/notes/{noteId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateNote(w, r, app.GetView(), app.GetModel(), app.GetNoteCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/books/{id}/notes/{noteId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.DeleteNote(w, r, app.GetView(), app.GetModel(), app.GetNoteCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/books/{id}/highlights", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetBookHighlights(w, r, app.GetView(), app.GetModel(), app.GetHighlightCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/books/{id}/highlights", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateHighlight(w, r, app.GetView(), app.GetModel(), app.GetHighlightCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/books/{id}/highlights/export.md", func(w http.ResponseWriter, r *http.Request) {
+		controller.ExportHighlights(w, r, app.GetView(), app.GetModel(), app.GetHighlightCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/books/{id}/highlights/{highlightId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateHighlight(w, r, app.GetView(), app.GetModel(), app.GetHighlightCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/books/{id}/highlights/{highlightId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.DeleteHighlight(w, r, app.GetView(), app.GetModel(), app.GetHighlightCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/books/{id}/tags", func(w http.ResponseWriter, r *http.Request) {
+		controller.SetBookTags(w, r, app.GetView(), app.GetModel(), app.GetTagCollection(), app.GetBookCollection())
 	}).Methods(http.MethodPut)
 
+	router.HandleFunc("/v1/books/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		controller.RestoreBook(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+	}).Methods(http.MethodPost)
+
 	router.HandleFunc("/v1/books/{id}", func(w http.ResponseWriter, r *http.Request) {
 		controller.DeleteBook(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
 	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/authors", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetAuthorsHandler(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/authors", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateAuthorHandler(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetAuthor(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateAuthor(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection(), app.GetBookCollection(), app.GetTransactor())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.DeleteAuthor(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection(), app.GetBookCollection(), app.GetTransactor())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/authors/{id}/books", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetAuthorBooks(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/authors/{id}/merge", func(w http.ResponseWriter, r *http.Request) {
+		controller.MergeAuthors(w, r, app.GetView(), app.GetModel(), app.GetAuthorCollection(), app.GetBookCollection(), app.GetTransactor())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/series", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetSeriesListHandler(w, r, app.GetView(), app.GetModel(), app.GetSeriesCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/series", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateSeriesHandler(w, r, app.GetView(), app.GetModel(), app.GetSeriesCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/series/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetSeries(w, r, app.GetView(), app.GetModel(), app.GetSeriesCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/series/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateSeries(w, r, app.GetView(), app.GetModel(), app.GetSeriesCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/series/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.DeleteSeries(w, r, app.GetView(), app.GetModel(), app.GetSeriesCollection(), app.GetBookCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/series/{id}/next", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetNextUnread(w, r, app.GetView(), app.GetModel(), app.GetSeriesCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/series/{id}/entries/{bookId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.SetSeriesEntry(w, r, app.GetView(), app.GetModel(), app.GetSeriesCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/series/{id}/entries/{bookId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.RemoveSeriesEntry(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/sessions/stats", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetReadingStats(w, r, app.GetView(), app.GetModel(), app.GetReadingSessionCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/goals", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetGoalsHandler(w, r, app.GetView(), app.GetModel(), app.GetGoalCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/goals", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateGoalHandler(w, r, app.GetView(), app.GetModel(), app.GetGoalCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/goals/progress", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetCurrentGoalsProgress(w, r, app.GetView(), app.GetModel(), app.GetGoalCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/goals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetGoal(w, r, app.GetView(), app.GetModel(), app.GetGoalCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/goals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateGoal(w, r, app.GetView(), app.GetModel(), app.GetGoalCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/goals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.DeleteGoal(w, r, app.GetView(), app.GetModel(), app.GetGoalCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/goals/{id}/progress", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetGoalProgress(w, r, app.GetView(), app.GetModel(), app.GetGoalCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/shelves", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetShelvesHandler(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/shelves", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateShelfHandler(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/shelves/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetShelf(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/shelves/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateShelf(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/shelves/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.DeleteShelf(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/shelves/{id}/order", func(w http.ResponseWriter, r *http.Request) {
+		controller.ReorderShelf(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/shelves/{id}/books/{bookId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.AddToShelf(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection(), app.GetBookCollection())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/shelves/{id}/books/{bookId}", func(w http.ResponseWriter, r *http.Request) {
+		controller.RemoveFromShelf(w, r, app.GetView(), app.GetModel(), app.GetShelfCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/notes", func(w http.ResponseWriter, r *http.Request) {
+		controller.SearchNotes(w, r, app.GetView(), app.GetModel(), app.GetNoteCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/highlights", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetHighlights(w, r, app.GetView(), app.GetModel(), app.GetHighlightCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/highlights/today", func(w http.ResponseWriter, r *http.Request) {
+		controller.HighlightOfTheDay(w, r, app.GetView(), app.GetModel(), app.GetHighlightCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/tags", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetTagsHandler(w, r, app.GetView(), app.GetModel(), app.GetTagCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/tags", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateTagHandler(w, r, app.GetView(), app.GetModel(), app.GetTagCollection())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/tags/tree", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetTagTree(w, r, app.GetView(), app.GetModel(), app.GetTagCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/tags/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetTag(w, r, app.GetView(), app.GetModel(), app.GetTagCollection(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/tags/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateTag(w, r, app.GetView(), app.GetModel(), app.GetTagCollection(), app.GetBookCollection(), app.GetTransactor())
+	}).Methods(http.MethodPut)
+
+	router.HandleFunc("/v1/tags/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.DeleteTag(w, r, app.GetView(), app.GetModel(), app.GetTagCollection(), app.GetBookCollection())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/tags/{id}/merge", func(w http.ResponseWriter, r *http.Request) {
+		controller.MergeTags(w, r, app.GetView(), app.GetModel(), app.GetTagCollection(), app.GetBookCollection(), app.GetTransactor())
+	}).Methods(http.MethodPost)
+
+	router.HandleFunc("/v1/stats", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetStatsHandler(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/trash", func(w http.ResponseWriter, r *http.Request) {
+		controller.GetTrash(w, r, app.GetView(), app.GetModel(), app.GetBookCollection())
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/trash", func(w http.ResponseWriter, r *http.Request) {
+		controller.EmptyTrash(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetBookDependents())
+	}).Methods(http.MethodDelete)
+
+	router.HandleFunc("/v1/trash/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.PurgeBook(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetBookDependents())
+	}).Methods(http.MethodDelete)
+
+	admin := middleware.AdminToken(app.GetConfig().Admin.Token)
+
+	router.Handle("/v1/admin/backup", admin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.BackupLibrary(w, r, app.GetModel(), app.GetBookCollection(), app.GetDocumentStore())
+	}))).Methods(http.MethodGet)
+
+	router.Handle("/v1/admin/restore", admin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.RestoreLibrary(w, r, app.GetView(), app.GetModel(), app.GetBookCollection(), app.GetDocumentStore(), app.GetTransactor())
+	}))).Methods(http.MethodPost)
 }
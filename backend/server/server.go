@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+const DefaultCertReloadInterval = time.Minute
+
+// ShutdownTimeout bounds how long Run waits for requests in flight once it is asked to stop.
+const ShutdownTimeout = 10 * time.Second
+
+/*
+Options describes how the application is served.
+TLS is enabled when both CertFile and KeyFile are set.
+*/
+type Options struct {
+	Port               string
+	CertFile           string
+	KeyFile            string
+	MinTLSVersion      string
+	CipherSuites       []string
+	RedirectPort       string
+	CertReloadInterval time.Duration
+}
+
+/*
+TLSEnabled reports whether the server should be started with TLS.
+*/
+func (o Options) TLSEnabled() bool {
+	return o.CertFile != "" && o.KeyFile != ""
+}
+
+/*
+Run serves the handler until ctx is cancelled or the listener fails.
+Without TLS it behaves like http.ListenAndServe. With TLS it serves HTTP/2 and HTTP/1.1 over TLS,
+hot-reloads the certificate and, when RedirectPort is set, redirects plain HTTP requests on that port to HTTPS.
+Once ctx is cancelled it stops accepting connections and waits up to ShutdownTimeout for the requests in flight,
+returning nil when they all finished.
+
+Parameters:
+
+	param1: context.Context, cancelled to shut the server down
+	param2: Options
+	param3: http.Handler
+
+Returns:
+
+	return1: error
+*/
+func Run(ctx context.Context, opts Options, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%s", opts.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if !opts.TLSEnabled() {
+		return serveUntilDone(ctx, srv.ListenAndServe, srv)
+	}
+
+	tlsConfig, reloader, err := newTLSConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	srv.TLSConfig = tlsConfig
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	interval := opts.CertReloadInterval
+	if interval <= 0 {
+		interval = DefaultCertReloadInterval
+	}
+
+	go reloader.Watch(ctx, interval)
+
+	servers := []*http.Server{srv}
+
+	if opts.RedirectPort != "" {
+		redirect := &http.Server{
+			Addr:              fmt.Sprintf(":%s", opts.RedirectPort),
+			Handler:           redirectToHTTPS(opts.Port),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		servers = append(servers, redirect)
+
+		go func() {
+			log.Printf("Redirecting HTTP on port %s to HTTPS", opts.RedirectPort)
+			if err := redirect.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				log.Println(err)
+			}
+		}()
+	}
+
+	// The certificate comes from TLSConfig.GetCertificate. Leaving TLSNextProto unset lets
+	// net/http negotiate HTTP/2 via ALPN.
+	return serveUntilDone(ctx, func() error { return srv.ListenAndServeTLS("", "") }, servers...)
+}
+
+/*
+serveUntilDone runs listen until it fails or ctx is cancelled, then shuts the servers down gracefully.
+*/
+func serveUntilDone(ctx context.Context, listen func() error, servers ...*http.Server) error {
+	failed := make(chan error, 1)
+	go func() { failed <- listen() }()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	var err error
+	for _, srv := range servers {
+		if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+
+	return err
+}
+
+/*
+newTLSConfig builds the tls.Config for the given options, backed by a CertReloader.
+*/
+func newTLSConfig(opts Options) (*tls.Config, *CertReloader, error) {
+	minVersion, err := ParseTLSVersion(opts.MinTLSVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cipherSuites, err := ParseCipherSuites(opts.CipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reloader, err := NewCertReloader(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	return tlsConfig, reloader, nil
+}
+
+/*
+redirectToHTTPS permanently redirects every request to the same host and path on the HTTPS port.
+*/
+func redirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRunShutsDownWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- Run(ctx, Options{Port: "0"}, http.NotFoundHandler()) }()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("got error %v, expected nil after a graceful shutdown", err)
+		}
+	case <-time.After(ShutdownTimeout):
+		t.Fatal("Run didn't return after its context was cancelled")
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/*
+CertReloader serves the TLS certificate loaded from disk and swaps it for a new one when the
+files change or the process receives SIGHUP.
+Because the certificate is looked up on every handshake, existing connections are never dropped.
+*/
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modTime  time.Time
+}
+
+/*
+NewCertReloader loads the certificate/key pair and returns a CertReloader serving it.
+
+Parameters:
+
+	param1: certFile string - path to the PEM encoded certificate (chain)
+	param2: keyFile string - path to the PEM encoded private key
+
+Returns:
+
+	return1: pointer CertReloader
+	return2: error
+*/
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	cr := &CertReloader{certFile: certFile, keyFile: keyFile}
+
+	if err := cr.Reload(); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+/*
+Reload reads the certificate/key pair from disk. On error the previous certificate keeps being served.
+
+Returns:
+
+	return1: error
+*/
+func (cr *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	modTime, err := cr.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cr.mu.Lock()
+	cr.cert = &cert
+	cr.modTime = modTime
+	cr.mu.Unlock()
+
+	return nil
+}
+
+/*
+GetCertificate returns the current certificate, it is meant to be used as tls.Config.GetCertificate.
+*/
+func (cr *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	return cr.cert, nil
+}
+
+/*
+Watch reloads the certificate whenever SIGHUP is received or, every interval, when the certificate
+or key file has been modified. It blocks until ctx is cancelled.
+
+Parameters:
+
+	param1: ctx context.Context
+	param2: interval time.Duration - how often to check the files for changes
+*/
+func (cr *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			cr.reloadAndLog("SIGHUP")
+		case <-ticker.C:
+			modTime, err := cr.latestModTime()
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+
+			cr.mu.RLock()
+			changed := modTime.After(cr.modTime)
+			cr.mu.RUnlock()
+
+			if changed {
+				cr.reloadAndLog("file change")
+			}
+		}
+	}
+}
+
+func (cr *CertReloader) reloadAndLog(reason string) {
+	if err := cr.Reload(); err != nil {
+		log.Printf("TLS certificate reload after %s failed, keeping the previous certificate: %v", reason, err)
+		return
+	}
+
+	log.Printf("TLS certificate reloaded after %s", reason)
+}
+
+/*
+latestModTime returns the most recent modification time of the certificate and key files.
+*/
+func (cr *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+
+	for _, file := range []string{cr.certFile, cr.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+/*
+ParseTLSVersion converts "1.0", "1.1", "1.2" or "1.3" into the matching crypto/tls constant.
+An empty string defaults to TLS 1.2.
+
+Parameters:
+
+	param1: version string
+
+Returns:
+
+	return1: uint16
+	return2: error
+*/
+func ParseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimSpace(version) {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version '%s'", version)
+	}
+}
+
+/*
+ParseCipherSuites converts cipher suite names (e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) into their IDs,
+in the given order of preference. Only suites considered secure by crypto/tls are accepted.
+TLS 1.3 suites are not configurable and always enabled.
+
+Parameters:
+
+	param1: names []string
+
+Returns:
+
+	return1: []uint16
+	return2: error
+*/
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	available := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		available[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		id, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure cipher suite '%s'", name)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
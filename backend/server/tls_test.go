@@ -0,0 +1,146 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestCert(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, cr *CertReloader) string {
+	t.Helper()
+
+	cert, err := cr.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed.Subject.CommonName
+}
+
+func TestCertReloaderReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "first")
+
+	cr, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if got := commonName(t, cr); got != "first" {
+		t.Errorf("Expected certificate 'first' but got '%s'", got)
+	}
+
+	writeTestCert(t, dir, "second")
+
+	if err := cr.Reload(); err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if got := commonName(t, cr); got != "second" {
+		t.Errorf("Expected certificate 'second' but got '%s'", got)
+	}
+
+	os.WriteFile(keyFile, []byte("broken"), 0600)
+
+	if err := cr.Reload(); err == nil {
+		t.Errorf("Expected an error for an invalid key")
+	}
+
+	if got := commonName(t, cr); got != "second" {
+		t.Errorf("Expected the previous certificate to be kept but got '%s'", got)
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	if v, err := ParseTLSVersion(""); err != nil || v != tls.VersionTLS12 {
+		t.Errorf("Expected TLS 1.2 by default, got %x, %v", v, err)
+	}
+
+	if v, err := ParseTLSVersion("1.3"); err != nil || v != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3, got %x, %v", v, err)
+	}
+
+	if _, err := ParseTLSVersion("2.0"); err == nil {
+		t.Errorf("Expected an error for an unknown version")
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	ids, err := ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", " TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+
+	if err != nil || len(ids) != 2 || ids[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("Unexpected result %v, %v", ids, err)
+	}
+
+	if _, err := ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"}); err == nil {
+		t.Errorf("Expected an error for an insecure cipher suite")
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	w := httptest.NewRecorder()
+	redirectToHTTPS("8443").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com:8080/book/view?id=1", nil))
+
+	if w.Code != http.StatusPermanentRedirect {
+		t.Errorf("Expected status code %d but got %d", http.StatusPermanentRedirect, w.Code)
+	}
+
+	if got := w.Header().Get("Location"); got != "https://example.com:8443/book/view?id=1" {
+		t.Errorf("Unexpected redirect location '%s'", got)
+	}
+}
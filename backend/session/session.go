@@ -0,0 +1,335 @@
+package session
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CookieName      = "readinglist_session"
+	CSRFFormField   = "csrf_token"
+	CSRFHeader      = "X-CSRF-Token"
+	DefaultLifetime = 24 * time.Hour
+)
+
+type contextKey string
+
+const sessionContextKey = contextKey("session")
+
+/*
+Session holds the server-side state for a single browser session: the CSRF token
+injected into forms and any flash messages waiting to be displayed.
+*/
+type Session struct {
+	ID        string
+	CSRFToken string
+	expiresAt time.Time
+	flashes   []string
+	manager   *Manager
+	mu        sync.Mutex
+}
+
+/*
+Manager identifies sessions with an HMAC-signed cookie and keeps them in memory.
+
+The cookie only carries the session ID and its signature, all session data stays on the server.
+The CSRF token is derived from the session ID, so a session is only stored once something is written to it,
+a flash message, and dropped again once its flashes are shown or its lifetime has passed: visitors who only
+read pages, or send no cookies at all, take no memory.
+*/
+type Manager struct {
+	secret   []byte
+	lifetime time.Duration
+	Secure   bool
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+/*
+NewManager creates a new session Manager signing its cookies with the given secret.
+If the secret is empty a random one is generated, meaning sessions won't survive a restart.
+
+Parameters:
+
+	param1: secret []byte - HMAC key used to sign the session cookie
+	param2: lifetime time.Duration - how long an idle session stays valid
+
+Returns:
+
+	return1: pointer Manager
+	return2: error
+*/
+func NewManager(secret []byte, lifetime time.Duration) (*Manager, error) {
+	if len(secret) == 0 {
+		var err error
+		secret, err = randomBytes(32)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if lifetime <= 0 {
+		lifetime = DefaultLifetime
+	}
+
+	return &Manager{
+		secret:   secret,
+		lifetime: lifetime,
+		sessions: make(map[string]*Session),
+	}, nil
+}
+
+/*
+Load returns the session referenced by the request's session cookie.
+A new session is started when the cookie is missing or has an invalid signature; it isn't stored until a flash
+message is added to it. A signed cookie whose session isn't stored, or has expired, keeps its ID and CSRF token.
+
+Parameters:
+
+	param1: r *http.Request
+
+Returns:
+
+	return1: pointer Session
+	return2: error
+*/
+func (m *Manager) Load(r *http.Request) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	if cookie, err := r.Cookie(CookieName); err == nil {
+		if id, ok := m.verify(cookie.Value); ok {
+			if s, found := m.sessions[id]; found && now.Before(s.expiresAt) {
+				s.expiresAt = now.Add(m.lifetime)
+				return s, nil
+			}
+
+			return m.newSession(id, now), nil
+		}
+	}
+
+	id, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.newSession(id, now), nil
+}
+
+/*
+newSession returns a session with the given ID that isn't stored yet.
+*/
+func (m *Manager) newSession(id string, now time.Time) *Session {
+	return &Session{ID: id, CSRFToken: m.csrfToken(id), expiresAt: now.Add(m.lifetime), manager: m}
+}
+
+/*
+WriteCookie sets the signed session cookie on the response.
+It must be called before the response headers are written.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: pointer Session
+*/
+func (m *Manager) WriteCookie(w http.ResponseWriter, s *Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    m.sign(s.ID),
+		Path:     "/",
+		MaxAge:   int(m.lifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   m.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+/*
+sign appends a base64 encoded HMAC-SHA256 signature of the value to the value.
+*/
+func (m *Manager) sign(value string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(value))
+
+	return value + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+/*
+verify checks the signature produced by sign and returns the original value if it matches.
+*/
+func (m *Manager) verify(signed string) (string, bool) {
+	value, _, found := strings.Cut(signed, ".")
+	if !found {
+		return "", false
+	}
+
+	if !hmac.Equal([]byte(m.sign(value)), []byte(signed)) {
+		return "", false
+	}
+
+	return value, true
+}
+
+/*
+csrfToken derives the CSRF token of a session from its ID, so it can be checked without storing the session.
+The "csrf." prefix keeps it apart from the cookie signature of the same ID.
+*/
+func (m *Manager) csrfToken(id string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte("csrf." + id))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+/*
+store keeps a session something was written to, dropping the sessions whose lifetime has passed.
+*/
+func (m *Manager) store(s *Session) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeExpired(time.Now())
+	m.sessions[s.ID] = s
+}
+
+/*
+forget drops a stored session once it has no flash messages left.
+*/
+func (m *Manager) forget(s *Session) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.mu.Lock()
+	empty := len(s.flashes) == 0
+	s.mu.Unlock()
+
+	if empty && m.sessions[s.ID] == s {
+		delete(m.sessions, s.ID)
+	}
+}
+
+/*
+removeExpired drops every session whose lifetime has passed. The caller must hold m.mu.
+*/
+func (m *Manager) removeExpired(now time.Time) {
+	for id, s := range m.sessions {
+		if now.After(s.expiresAt) {
+			delete(m.sessions, id)
+		}
+	}
+}
+
+/*
+AddFlash queues a message to be shown on the next rendered page, storing the session until then.
+
+Parameters:
+
+	param1: message string
+*/
+func (s *Session) AddFlash(message string) {
+	s.mu.Lock()
+	s.flashes = append(s.flashes, message)
+	s.mu.Unlock()
+
+	if s.manager != nil {
+		s.manager.store(s)
+	}
+}
+
+/*
+PopFlashes returns all queued flash messages and clears them so they are only displayed once.
+
+Returns:
+
+	return1: []string
+*/
+func (s *Session) PopFlashes() []string {
+	s.mu.Lock()
+	flashes := s.flashes
+	s.flashes = nil
+	s.mu.Unlock()
+
+	if s.manager != nil && len(flashes) > 0 {
+		s.manager.forget(s)
+	}
+
+	return flashes
+}
+
+/*
+ValidCSRFToken reports whether the submitted token matches the session's CSRF token,
+using a constant time comparison.
+
+Parameters:
+
+	param1: token string
+
+Returns:
+
+	return1: boolean
+*/
+func (s *Session) ValidCSRFToken(token string) bool {
+	if token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(s.CSRFToken), []byte(token)) == 1
+}
+
+/*
+NewContext returns a copy of ctx carrying the session.
+*/
+func NewContext(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey, s)
+}
+
+/*
+FromContext returns the session stored in ctx, or nil if there isn't one.
+*/
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionContextKey).(*Session)
+	return s
+}
+
+/*
+AddFlash queues a flash message on the session attached to the request, if any.
+
+Parameters:
+
+	param1: r *http.Request
+	param2: message string
+*/
+func AddFlash(r *http.Request, message string) {
+	if s := FromContext(r.Context()); s != nil {
+		s.AddFlash(message)
+	}
+}
+
+func randomToken() (string, error) {
+	b, err := randomBytes(32)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.New("unable to generate random bytes")
+	}
+
+	return b, nil
+}
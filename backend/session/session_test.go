@@ -0,0 +1,130 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadReusesSignedSession(t *testing.T) {
+	manager, err := NewManager([]byte("unit-test-secret"), 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := manager.Load(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	w := httptest.NewRecorder()
+	manager.WriteCookie(w, s)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(w.Result().Cookies()[0])
+
+	loaded, err := manager.Load(req)
+
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if loaded.ID != s.ID {
+		t.Errorf("Expected session %s but got %s", s.ID, loaded.ID)
+	}
+}
+
+func TestLoadRejectsTamperedCookie(t *testing.T) {
+	manager, err := NewManager([]byte("unit-test-secret"), 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := manager.Load(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: CookieName, Value: s.ID + ".forged"})
+
+	loaded, err := manager.Load(req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.ID == s.ID {
+		t.Errorf("Expected a new session for a tampered cookie")
+	}
+}
+
+func TestLoadStoresOnlySessionsWithFlashes(t *testing.T) {
+	manager, err := NewManager([]byte("unit-test-secret"), 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, err := manager.Load(httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(manager.sessions) != 0 {
+		t.Fatalf("Expected no session stored for requests without cookies but got %d", len(manager.sessions))
+	}
+
+	s, _ := manager.Load(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	w := httptest.NewRecorder()
+	manager.WriteCookie(w, s)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(w.Result().Cookies()[0])
+
+	if again, _ := manager.Load(req); again.CSRFToken != s.CSRFToken {
+		t.Errorf("Expected the CSRF token to last without storing the session")
+	}
+
+	s.AddFlash("Book created")
+
+	if loaded, _ := manager.Load(req); loaded != s || len(manager.sessions) != 1 {
+		t.Fatalf("Expected the session stored once it has a flash message")
+	}
+
+	if flashes := s.PopFlashes(); len(flashes) != 1 || len(manager.sessions) != 0 {
+		t.Errorf("Expected the session dropped once its flashes are shown but got %v and %d sessions", flashes, len(manager.sessions))
+	}
+}
+
+func TestPopFlashes(t *testing.T) {
+	s := &Session{}
+
+	s.AddFlash("Book deleted")
+
+	if flashes := s.PopFlashes(); len(flashes) != 1 || flashes[0] != "Book deleted" {
+		t.Errorf("Expected [Book deleted] but got %v", flashes)
+	}
+
+	if flashes := s.PopFlashes(); len(flashes) != 0 {
+		t.Errorf("Expected flashes to be cleared but got %v", flashes)
+	}
+}
+
+func TestValidCSRFToken(t *testing.T) {
+	s := &Session{CSRFToken: "token"}
+
+	if !s.ValidCSRFToken("token") {
+		t.Errorf("Expected true but got false")
+	}
+
+	if s.ValidCSRFToken("") || s.ValidCSRFToken("other") {
+		t.Errorf("Expected false but got true")
+	}
+}
@@ -0,0 +1,542 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Config is the application configuration, loaded once at start up by Load and injected into internal.App.
+
+Values are resolved in order of increasing precedence: defaults, the optional YAML/TOML config file,
+the optional .env file and finally real environment variables (the `env` tags).
+*/
+type Config struct {
+	Env     string `yaml:"env" toml:"env" env:"ENV"`
+	Version string `yaml:"version" toml:"version" env:"VERSION"`
+	Port    string `yaml:"port" toml:"port" env:"PORT"`
+	SiteURL string `yaml:"siteURL" toml:"siteURL" env:"SITE_URL"`
+
+	DB      DBConfig      `yaml:"db" toml:"db"`
+	Breaker BreakerConfig `yaml:"breaker" toml:"breaker"`
+	Session SessionConfig `yaml:"session" toml:"session"`
+	TLS     TLSConfig     `yaml:"tls" toml:"tls"`
+	Admin   AdminConfig   `yaml:"admin" toml:"admin"`
+	Trash   TrashConfig   `yaml:"trash" toml:"trash"`
+	Headers HeadersConfig `yaml:"headers" toml:"headers"`
+}
+
+type DBConfig struct {
+	URL            string `yaml:"url" toml:"url" env:"DB_URL"`
+	Name           string `yaml:"name" toml:"name" env:"DB_NAME"`
+	MigrateOnStart bool   `yaml:"migrateOnStart" toml:"migrateOnStart" env:"DB_MIGRATE_ON_START"`
+
+	MaxPoolSize            uint64        `yaml:"maxPoolSize" toml:"maxPoolSize" env:"DB_MAX_POOL_SIZE"`
+	MinPoolSize            uint64        `yaml:"minPoolSize" toml:"minPoolSize" env:"DB_MIN_POOL_SIZE"`
+	ConnectTimeout         time.Duration `yaml:"connectTimeout" toml:"connectTimeout" env:"DB_CONNECT_TIMEOUT"`
+	ServerSelectionTimeout time.Duration `yaml:"serverSelectionTimeout" toml:"serverSelectionTimeout" env:"DB_SERVER_SELECTION_TIMEOUT"`
+	ReadPreference         string        `yaml:"readPreference" toml:"readPreference" env:"DB_READ_PREFERENCE"`
+	WriteConcern           string        `yaml:"writeConcern" toml:"writeConcern" env:"DB_WRITE_CONCERN"`
+
+	OperationTimeout time.Duration `yaml:"operationTimeout" toml:"operationTimeout" env:"DB_OPERATION_TIMEOUT"`
+
+	ConnectAttempts int           `yaml:"connectAttempts" toml:"connectAttempts" env:"DB_CONNECT_ATTEMPTS"`
+	ReadRetries     int           `yaml:"readRetries" toml:"readRetries" env:"DB_READ_RETRIES"`
+	RetryBackoff    time.Duration `yaml:"retryBackoff" toml:"retryBackoff" env:"DB_RETRY_BACKOFF"`
+	RetryMaxBackoff time.Duration `yaml:"retryMaxBackoff" toml:"retryMaxBackoff" env:"DB_RETRY_MAX_BACKOFF"`
+}
+
+type BreakerConfig struct {
+	FailureThreshold int           `yaml:"failureThreshold" toml:"failureThreshold" env:"BREAKER_FAILURE_THRESHOLD"`
+	ErrorRate        float64       `yaml:"errorRate" toml:"errorRate" env:"BREAKER_ERROR_RATE"`
+	MinRequests      int           `yaml:"minRequests" toml:"minRequests" env:"BREAKER_MIN_REQUESTS"`
+	Window           time.Duration `yaml:"window" toml:"window" env:"BREAKER_WINDOW"`
+	OpenTimeout      time.Duration `yaml:"openTimeout" toml:"openTimeout" env:"BREAKER_OPEN_TIMEOUT"`
+	HalfOpenProbes   int           `yaml:"halfOpenProbes" toml:"halfOpenProbes" env:"BREAKER_HALF_OPEN_PROBES"`
+}
+
+type SessionConfig struct {
+	Secret   string        `yaml:"secret" toml:"secret" env:"SESSION_SECRET"`
+	Lifetime time.Duration `yaml:"lifetime" toml:"lifetime" env:"SESSION_LIFETIME"`
+}
+
+type TLSConfig struct {
+	CertFile       string        `yaml:"certFile" toml:"certFile" env:"TLS_CERT_FILE"`
+	KeyFile        string        `yaml:"keyFile" toml:"keyFile" env:"TLS_KEY_FILE"`
+	MinVersion     string        `yaml:"minVersion" toml:"minVersion" env:"TLS_MIN_VERSION"`
+	CipherSuites   []string      `yaml:"cipherSuites" toml:"cipherSuites" env:"TLS_CIPHER_SUITES"`
+	RedirectPort   string        `yaml:"redirectPort" toml:"redirectPort" env:"HTTP_REDIRECT_PORT"`
+	ReloadInterval time.Duration `yaml:"reloadInterval" toml:"reloadInterval" env:"TLS_RELOAD_INTERVAL"`
+}
+
+/*
+AdminConfig guards the admin endpoints, such as backup and restore. Without a token they are disabled.
+*/
+type AdminConfig struct {
+	Token string `yaml:"token" toml:"token" env:"ADMIN_TOKEN"`
+}
+
+/*
+TrashConfig sets how long deleted books stay in the trash before the server purges them, checking every
+PurgeInterval. A retention of 0 keeps them until they are purged by hand.
+*/
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention" toml:"retention" env:"TRASH_RETENTION"`
+	PurgeInterval time.Duration `yaml:"purgeInterval" toml:"purgeInterval" env:"TRASH_PURGE_INTERVAL"`
+}
+
+/*
+HeadersConfig overrides the security headers. Unset fields keep the defaults of the environment: production
+enforces the Content-Security-Policy and sends HSTS over TLS, other environments only report CSP violations
+and never send HSTS. An empty string leaves the header out.
+
+X-Forwarded-Proto is only trusted from TrustedProxies, IP addresses or CIDR ranges of TLS terminating proxies.
+*/
+type HeadersConfig struct {
+	ContentSecurityPolicy *string        `yaml:"contentSecurityPolicy" toml:"contentSecurityPolicy" env:"HEADERS_CSP"`
+	CSPReportOnly         *bool          `yaml:"cspReportOnly" toml:"cspReportOnly" env:"HEADERS_CSP_REPORT_ONLY"`
+	FrameOptions          *string        `yaml:"frameOptions" toml:"frameOptions" env:"HEADERS_FRAME_OPTIONS"`
+	ReferrerPolicy        *string        `yaml:"referrerPolicy" toml:"referrerPolicy" env:"HEADERS_REFERRER_POLICY"`
+	PermissionsPolicy     *string        `yaml:"permissionsPolicy" toml:"permissionsPolicy" env:"HEADERS_PERMISSIONS_POLICY"`
+	HSTSMaxAge            *time.Duration `yaml:"hstsMaxAge" toml:"hstsMaxAge" env:"HEADERS_HSTS_MAX_AGE"`
+	HSTSIncludeSubdomains *bool          `yaml:"hstsIncludeSubdomains" toml:"hstsIncludeSubdomains" env:"HEADERS_HSTS_INCLUDE_SUBDOMAINS"`
+	TrustedProxies        []string       `yaml:"trustedProxies" toml:"trustedProxies" env:"TRUSTED_PROXIES"`
+}
+
+/*
+ValidationError lists every problem found while loading the configuration,
+so they can all be fixed in one go.
+*/
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+/*
+Default returns the configuration used when nothing else is set.
+
+Returns:
+
+	return1: pointer Config
+*/
+func Default() *Config {
+	return &Config{
+		Env:     "dev",
+		Version: "1.0",
+		Port:    "8080",
+		SiteURL: "http://localhost",
+		DB: DBConfig{
+			Name:                   "readinglist",
+			MigrateOnStart:         true,
+			MaxPoolSize:            100,
+			ConnectTimeout:         10 * time.Second,
+			ServerSelectionTimeout: 10 * time.Second,
+			ReadPreference:         "primary",
+			WriteConcern:           "majority",
+			OperationTimeout:       30 * time.Second,
+			ConnectAttempts:        5,
+			ReadRetries:            2,
+			RetryBackoff:           500 * time.Millisecond,
+			RetryMaxBackoff:        10 * time.Second,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: 5,
+			ErrorRate:        0.5,
+			MinRequests:      20,
+			Window:           time.Minute,
+			OpenTimeout:      30 * time.Second,
+			HalfOpenProbes:   1,
+		},
+		Session: SessionConfig{
+			Lifetime: 24 * time.Hour,
+		},
+		TLS: TLSConfig{
+			MinVersion:     "1.2",
+			ReloadInterval: time.Minute,
+		},
+		Trash: TrashConfig{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+	}
+}
+
+/*
+Load builds the configuration from the defaults, the optional config file (YAML or TOML, chosen by extension),
+the optional .env file in the working directory and the environment.
+If configFile is empty the CONFIG_FILE environment variable is used, if that is empty too no file is read.
+
+Parse and validation problems are collected and returned together as a *ValidationError.
+
+Parameters:
+
+	param1: configFile string - path to a .yaml, .yml or .toml file
+
+Returns:
+
+	return1: pointer Config
+	return2: error
+*/
+func Load(configFile string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("loading .env file: %w", err)
+	}
+
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+
+	cfg := Default()
+
+	if configFile != "" {
+		if err := loadFile(configFile, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []string
+
+	applyEnv(reflect.ValueOf(cfg).Elem(), &problems)
+
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+/*
+TLSEnabled reports whether a certificate and key have been configured.
+*/
+func (c *Config) TLSEnabled() bool {
+	return c.TLS.CertFile != "" && c.TLS.KeyFile != ""
+}
+
+/*
+IsProduction reports whether the application runs in the production environment.
+*/
+func (c *Config) IsProduction() bool {
+	switch strings.ToLower(c.Env) {
+	case "prod", "production":
+		return true
+	default:
+		return false
+	}
+}
+
+/*
+Proxies parses TrustedProxies. A bare IP address is a range of one.
+
+Returns:
+
+	return1: slice of netip.Prefix
+	return2: error, for the first entry that is neither an IP address nor a CIDR range
+*/
+func (h HeadersConfig) Proxies() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+
+	for _, proxy := range h.TrustedProxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not an IP address or CIDR range", proxy)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+/*
+loadFile decodes a YAML or TOML config file over cfg.
+*/
+func loadFile(path string, cfg *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, cfg)
+	case ".toml":
+		err = toml.Unmarshal(content, cfg)
+	default:
+		return fmt.Errorf("unsupported config file format '%s', expected .yaml, .yml or .toml", filepath.Ext(path))
+	}
+
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+/*
+applyEnv walks the struct and overrides every field carrying an `env` tag whose variable is set,
+converting the string to the field's type. Conversion problems are appended to problems.
+A variable set to an empty string overrides too: text, such as a header to leave out, and lists become empty
+and other settings go back to their zero value, or to the environment's default for the optional ones.
+*/
+func applyEnv(v reflect.Value, problems *[]string) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			applyEnv(value, problems)
+			continue
+		}
+
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if raw == "" && !isText(value.Type()) {
+			value.Set(reflect.Zero(value.Type()))
+			continue
+		}
+
+		if err := setField(value, raw); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+}
+
+// isText reports whether an empty string is a value of the type itself, as for strings and lists of them.
+func isText(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.String
+}
+
+/*
+setField parses raw into the field. Durations use time.ParseDuration syntax ("30s", "5m")
+and lists are comma-separated. Pointer fields, for optional settings, are allocated.
+*/
+func setField(value reflect.Value, raw string) error {
+	if value.Kind() == reflect.Pointer {
+		elem := reflect.New(value.Type().Elem())
+		if err := setField(elem.Elem(), raw); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	if value.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid duration", raw)
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid integer", raw)
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid unsigned integer", raw)
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid number", raw)
+		}
+		value.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid boolean", raw)
+		}
+		value.SetBool(b)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", value.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+
+	return nil
+}
+
+/*
+validate returns every semantic problem with the configuration.
+*/
+func (c *Config) validate() []string {
+	var problems []string
+
+	if c.DB.URL == "" {
+		problems = append(problems, "DB_URL: must be set")
+	}
+
+	if c.DB.Name == "" {
+		problems = append(problems, "DB_NAME: must not be empty")
+	}
+
+	if c.DB.MaxPoolSize > 0 && c.DB.MinPoolSize > c.DB.MaxPoolSize {
+		problems = append(problems, fmt.Sprintf("DB_MIN_POOL_SIZE: %d exceeds DB_MAX_POOL_SIZE %d", c.DB.MinPoolSize, c.DB.MaxPoolSize))
+	}
+
+	if c.DB.ConnectTimeout <= 0 {
+		problems = append(problems, "DB_CONNECT_TIMEOUT: must be positive")
+	}
+
+	if c.DB.ServerSelectionTimeout <= 0 {
+		problems = append(problems, "DB_SERVER_SELECTION_TIMEOUT: must be positive")
+	}
+
+	switch strings.ToLower(c.DB.ReadPreference) {
+	case "primary", "primarypreferred", "secondary", "secondarypreferred", "nearest":
+	default:
+		problems = append(problems, fmt.Sprintf("DB_READ_PREFERENCE: '%s' must be one of primary, primaryPreferred, secondary, secondaryPreferred, nearest", c.DB.ReadPreference))
+	}
+
+	if n, err := strconv.Atoi(c.DB.WriteConcern); c.DB.WriteConcern != "majority" && (err != nil || n < 0) {
+		problems = append(problems, fmt.Sprintf("DB_WRITE_CONCERN: '%s' must be 'majority' or a number of nodes", c.DB.WriteConcern))
+	}
+
+	if c.DB.OperationTimeout <= 0 {
+		problems = append(problems, "DB_OPERATION_TIMEOUT: must be positive")
+	}
+
+	if c.DB.ConnectAttempts < 1 {
+		problems = append(problems, "DB_CONNECT_ATTEMPTS: must be at least 1")
+	}
+
+	if c.DB.ReadRetries < 0 {
+		problems = append(problems, "DB_READ_RETRIES: must not be negative")
+	}
+
+	if c.DB.RetryBackoff <= 0 || c.DB.RetryMaxBackoff < c.DB.RetryBackoff {
+		problems = append(problems, "DB_RETRY_BACKOFF/DB_RETRY_MAX_BACKOFF: must be positive with the maximum not below the initial backoff")
+	}
+
+	if c.Breaker.FailureThreshold < 1 {
+		problems = append(problems, "BREAKER_FAILURE_THRESHOLD: must be at least 1")
+	}
+
+	if c.Breaker.ErrorRate <= 0 || c.Breaker.ErrorRate > 1 {
+		problems = append(problems, fmt.Sprintf("BREAKER_ERROR_RATE: %g must be between 0 (exclusive) and 1", c.Breaker.ErrorRate))
+	}
+
+	if c.Breaker.MinRequests < 1 {
+		problems = append(problems, "BREAKER_MIN_REQUESTS: must be at least 1")
+	}
+
+	if c.Breaker.Window <= 0 || c.Breaker.OpenTimeout <= 0 {
+		problems = append(problems, "BREAKER_WINDOW/BREAKER_OPEN_TIMEOUT: must be positive")
+	}
+
+	if c.Breaker.HalfOpenProbes < 1 {
+		problems = append(problems, "BREAKER_HALF_OPEN_PROBES: must be at least 1")
+	}
+
+	if c.Env == "" {
+		problems = append(problems, "ENV: must not be empty")
+	}
+
+	if !isPort(c.Port) {
+		problems = append(problems, fmt.Sprintf("PORT: '%s' is not a valid port", c.Port))
+	}
+
+	if !strings.HasPrefix(c.SiteURL, "http://") && !strings.HasPrefix(c.SiteURL, "https://") {
+		problems = append(problems, fmt.Sprintf("SITE_URL: '%s' must start with http:// or https://", c.SiteURL))
+	}
+
+	if c.Session.Lifetime <= 0 {
+		problems = append(problems, "SESSION_LIFETIME: must be positive")
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		problems = append(problems, "TLS_CERT_FILE/TLS_KEY_FILE: both or neither must be set")
+	}
+
+	switch c.TLS.MinVersion {
+	case "1.0", "1.1", "1.2", "1.3":
+	default:
+		problems = append(problems, fmt.Sprintf("TLS_MIN_VERSION: '%s' must be one of 1.0, 1.1, 1.2, 1.3", c.TLS.MinVersion))
+	}
+
+	if c.TLS.RedirectPort != "" && !isPort(c.TLS.RedirectPort) {
+		problems = append(problems, fmt.Sprintf("HTTP_REDIRECT_PORT: '%s' is not a valid port", c.TLS.RedirectPort))
+	}
+
+	if c.TLS.ReloadInterval <= 0 {
+		problems = append(problems, "TLS_RELOAD_INTERVAL: must be positive")
+	}
+
+	if _, err := c.Headers.Proxies(); err != nil {
+		problems = append(problems, fmt.Sprintf("TRUSTED_PROXIES: %v", err))
+	}
+
+	if c.Headers.HSTSMaxAge != nil && *c.Headers.HSTSMaxAge < 0 {
+		problems = append(problems, "HEADERS_HSTS_MAX_AGE: must not be negative")
+	}
+
+	if c.Trash.Retention < 0 {
+		problems = append(problems, "TRASH_RETENTION: must not be negative")
+	}
+
+	if c.Trash.PurgeInterval <= 0 {
+		problems = append(problems, "TRASH_PURGE_INTERVAL: must be positive")
+	}
+
+	return problems
+}
+
+func isPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
@@ -0,0 +1,133 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadFromEnv(t *testing.T) {
+	t.Setenv("DB_URL", "mongodb://localhost:27017")
+	t.Setenv("PORT", "9000")
+	t.Setenv("SESSION_LIFETIME", "2h")
+	t.Setenv("TRASH_RETENTION", "0")
+	t.Setenv("HEADERS_CSP_REPORT_ONLY", "false")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+	t.Setenv("TLS_CIPHER_SUITES", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256")
+
+	cfg, err := Load("")
+
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if cfg.Port != "9000" {
+		t.Errorf("Expected port 9000 but got %s", cfg.Port)
+	}
+
+	if cfg.Session.Lifetime != 2*time.Hour {
+		t.Errorf("Expected session lifetime 2h but got %s", cfg.Session.Lifetime)
+	}
+
+	if len(cfg.TLS.CipherSuites) != 2 || cfg.TLS.CipherSuites[1] != "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256" {
+		t.Errorf("Unexpected cipher suites %v", cfg.TLS.CipherSuites)
+	}
+
+	if cfg.DB.Name != "readinglist" {
+		t.Errorf("Expected default DB name but got %s", cfg.DB.Name)
+	}
+
+	if cfg.Trash.Retention != 0 || cfg.Trash.PurgeInterval != time.Hour {
+		t.Errorf("Expected the trash kept until purged by hand, checked hourly, but got %+v", cfg.Trash)
+	}
+
+	if cfg.Headers.CSPReportOnly == nil || *cfg.Headers.CSPReportOnly || cfg.Headers.HSTSMaxAge != nil {
+		t.Errorf("Expected only the CSP report-only override to be set but got %+v", cfg.Headers)
+	}
+
+	if proxies, err := cfg.Headers.Proxies(); err != nil || len(proxies) != 2 || proxies[1].Bits() != 32 {
+		t.Errorf("Expected two trusted proxy ranges but got %v, %v", proxies, err)
+	}
+}
+
+func TestLoadFileThenEnv(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlFile := filepath.Join(dir, "config.yaml")
+	os.WriteFile(yamlFile, []byte("port: \"7000\"\ndb:\n  url: mongodb://file\n  name: fromfile\nsession:\n  lifetime: 30m\n"), 0600)
+
+	tomlFile := filepath.Join(dir, "config.toml")
+	os.WriteFile(tomlFile, []byte("port = \"7000\"\n[db]\nurl = \"mongodb://file\"\nname = \"fromfile\"\n[session]\nlifetime = \"30m\"\n"), 0600)
+
+	t.Setenv("DB_NAME", "fromenv")
+
+	for _, file := range []string{yamlFile, tomlFile} {
+		cfg, err := Load(file)
+
+		if err != nil {
+			t.Fatalf("%s: got error %v, expected nil", file, err)
+		}
+
+		if cfg.Port != "7000" || cfg.DB.URL != "mongodb://file" || cfg.Session.Lifetime != 30*time.Minute {
+			t.Errorf("%s: file values were not applied: %+v", file, cfg)
+		}
+
+		if cfg.DB.Name != "fromenv" {
+			t.Errorf("%s: expected env to override the file but got %s", file, cfg.DB.Name)
+		}
+	}
+}
+
+func TestLoadEmptyEnvOverridesFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(file, []byte("db:\n  url: mongodb://file\nheaders:\n  frameOptions: DENY\n  cspReportOnly: true\n  trustedProxies: [10.0.0.1]\n"), 0600)
+
+	t.Setenv("HEADERS_FRAME_OPTIONS", "")
+	t.Setenv("HEADERS_CSP_REPORT_ONLY", "")
+	t.Setenv("TRUSTED_PROXIES", "")
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("got error %v, expected nil", err)
+	}
+
+	if cfg.Headers.FrameOptions == nil || *cfg.Headers.FrameOptions != "" {
+		t.Errorf("got frame options %v, expected the header left out", cfg.Headers.FrameOptions)
+	}
+
+	if cfg.Headers.CSPReportOnly != nil || len(cfg.Headers.TrustedProxies) != 0 {
+		t.Errorf("got %+v, expected the report-only override unset and no trusted proxies", cfg.Headers)
+	}
+}
+
+func TestLoadListsEveryProblem(t *testing.T) {
+	t.Setenv("DB_URL", "")
+	t.Setenv("PORT", "not-a-port")
+	t.Setenv("SESSION_LIFETIME", "forever")
+	t.Setenv("TLS_CERT_FILE", "cert.pem")
+	t.Setenv("TRASH_RETENTION", "-1h")
+	t.Setenv("TRUSTED_PROXIES", "load-balancer")
+
+	_, err := Load("")
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError but got %v", err)
+	}
+
+	for _, expected := range []string{"DB_URL", "PORT", "SESSION_LIFETIME", "TLS_CERT_FILE", "TRASH_RETENTION", "TRUSTED_PROXIES"} {
+		found := false
+		for _, problem := range validationErr.Problems {
+			if strings.HasPrefix(problem, expected) {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("Expected a problem for %s in %v", expected, validationErr.Problems)
+		}
+	}
+}
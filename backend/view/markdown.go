@@ -0,0 +1,98 @@
+package view
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"readinglistapp/internal/data"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/russross/blackfriday/v2"
+)
+
+// markdownPolicy allows the formatting markdown produces and strips everything else, such as scripts,
+// event handlers and javascript: links.
+var markdownPolicy = bluemonday.UGCPolicy().RequireNoReferrerOnLinks(true)
+
+var markdownRenderer = blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+	Flags: blackfriday.SkipHTML | blackfriday.Safelink | blackfriday.NofollowLinks | blackfriday.NoreferrerLinks,
+})
+
+/*
+renderMarkdown converts user-written markdown, such as reviews and notes, to HTML that is safe to include in a page.
+Raw HTML in the text is dropped and the result is sanitised.
+
+Parameters:
+
+	param1: text string
+
+Returns:
+
+	return1: template.HTML
+*/
+func renderMarkdown(text string) template.HTML {
+	unsafe := blackfriday.Run([]byte(text), blackfriday.WithRenderer(markdownRenderer), blackfriday.WithExtensions(blackfriday.CommonExtensions))
+
+	return template.HTML(markdownPolicy.SanitizeBytes(unsafe))
+}
+
+/*
+RenderHighlightsMarkdown writes a book's highlights as a markdown document: a heading with the title and
+authors, then each highlight as a block quote followed by its page or location, comment and tags.
+
+Parameters:
+
+	param1: book *data.Book
+	param2: highlights []*data.Highlight - in the order they should appear
+
+Returns:
+
+	return1: slice of bytes
+*/
+func (v *View) RenderHighlightsMarkdown(book *data.Book, highlights []*data.Highlight) []byte {
+	var doc bytes.Buffer
+
+	fmt.Fprintf(&doc, "# %s\n\n", book.Title)
+
+	if len(book.Authors) > 0 {
+		fmt.Fprintf(&doc, "*%s*\n\n", strings.Join(book.Authors, ", "))
+	}
+
+	if len(highlights) == 0 {
+		doc.WriteString("No highlights yet.\n")
+		return doc.Bytes()
+	}
+
+	for i, highlight := range highlights {
+		if i > 0 {
+			doc.WriteString("---\n\n")
+		}
+
+		for _, line := range strings.Split(highlight.Quote, "\n") {
+			doc.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		doc.WriteString("\n")
+
+		var where []string
+		if highlight.Page > 0 {
+			where = append(where, fmt.Sprintf("page %d", highlight.Page))
+		}
+		if highlight.Location != "" {
+			where = append(where, "location "+highlight.Location)
+		}
+		if len(where) > 0 {
+			fmt.Fprintf(&doc, "— %s\n\n", strings.Join(where, ", "))
+		}
+
+		if highlight.Comment != "" {
+			fmt.Fprintf(&doc, "%s\n\n", highlight.Comment)
+		}
+
+		if len(highlight.Tags) > 0 {
+			fmt.Fprintf(&doc, "Tags: %s\n\n", strings.Join(highlight.Tags, ", "))
+		}
+	}
+
+	return doc.Bytes()
+}
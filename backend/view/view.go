@@ -5,7 +5,10 @@ import (
 	"html/template"
 	"io"
 	"net/http"
+	"net/url"
 	"readinglistapp/internal/data"
+	"readinglistapp/middleware"
+	"readinglistapp/session"
 	"strconv"
 	"strings"
 )
@@ -15,20 +18,31 @@ type IViewNew interface {
 }
 
 type IViewFuncs interface {
-	BookCreateForm(w http.ResponseWriter, r *http.Request) error
+	AuthorList(w http.ResponseWriter, r *http.Request, authors []*data.Author) error
+	AuthorView(w http.ResponseWriter, r *http.Request, author *data.Author, books []*data.Book) error
+	ShelfList(w http.ResponseWriter, r *http.Request, shelves []*data.Shelf) error
+	ShelfView(w http.ResponseWriter, r *http.Request, shelf *data.Shelf) error
+	StatsView(w http.ResponseWriter, r *http.Request, stats *data.LibraryStats) error
+	BookCreateForm(w http.ResponseWriter, r *http.Request, problem string) error
 	BookCreateProcess(w http.ResponseWriter, r *http.Request) ([]byte, error)
-	BookHome(w http.ResponseWriter, books []*data.Book) error
-	BookView(w http.ResponseWriter, id string, book *data.Book) error
+	BookHome(w http.ResponseWriter, r *http.Request, books []*data.Book, status string, goals []*data.GoalProgress) error
+	BookView(w http.ResponseWriter, r *http.Request, id string, book *data.Book, reviews []*data.Review, notes []*data.Note) error
 	ReadJSON(w http.ResponseWriter, r *http.Request, data any) error
 	RenderJSON(data Envelope) ([]byte, error)
+	RenderHighlightsMarkdown(book *data.Book, highlights []*data.Highlight) []byte
 }
 
 const (
-	BASEHTML   = "./ui/html/base.html"
-	NAVHTML    = "./ui/html/partials/nav.html"
-	HOMEHTML   = "./ui/html/pages/home.html"
-	VIEWHTML   = "./ui/html/pages/view.html"
-	CREATEHTML = "./ui/html/pages/create.html"
+	BASEHTML    = "./ui/html/base.html"
+	NAVHTML     = "./ui/html/partials/nav.html"
+	HOMEHTML    = "./ui/html/pages/home.html"
+	VIEWHTML    = "./ui/html/pages/view.html"
+	CREATEHTML  = "./ui/html/pages/create.html"
+	AUTHORSHTML = "./ui/html/pages/authors.html"
+	AUTHORHTML  = "./ui/html/pages/author.html"
+	SHELVESHTML = "./ui/html/pages/shelves.html"
+	SHELFHTML   = "./ui/html/pages/shelf.html"
+	STATSHTML   = "./ui/html/pages/stats.html"
 )
 
 type View struct {
@@ -100,26 +114,36 @@ This function BookCreateForm renders the HTML form for creating a book.
 It parses the HTML template files, including the base template, navigation template, and specific create book template.
 If there are any errors during the parsing or execution of the templates, it returns the error.
 Otherwise, it renders the form successfully.
+A rejected submission is rendered again with the problem above the form and the posted values filled in.
 
 Parameters:
 
 	param1: w http.ResponseWriter
 	param2: r *http.Request
+	param3: problem string, empty for a new form
 
 Returns:
 
 	return1: error
 */
-func (v *View) BookCreateForm(w http.ResponseWriter, r *http.Request) error {
+func (v *View) BookCreateForm(w http.ResponseWriter, r *http.Request, problem string) error {
 	files := []string{BASEHTML, NAVHTML, CREATEHTML}
 
-	ts, err := template.ParseFiles(files...)
+	ts, err := template.New("createBook").Funcs(templateFuncs(r)).ParseFiles(files...)
 
 	if err != nil {
 		return err
 	}
 
-	err = ts.ExecuteTemplate(w, "base", nil)
+	data := struct {
+		Problem string
+		Form    url.Values
+	}{
+		Problem: problem,
+		Form:    r.PostForm,
+	}
+
+	err = ts.ExecuteTemplate(w, "base", data)
 
 	if err != nil {
 		return err
@@ -165,6 +189,13 @@ func (v *View) BookCreateProcess(w http.ResponseWriter, r *http.Request) ([]byte
 
 	genres := strings.Split(r.PostForm.Get("genres"), ",")
 
+	var authors []string
+	for _, author := range strings.Split(r.PostForm.Get("authors"), ",") {
+		if author = strings.TrimSpace(author); author != "" {
+			authors = append(authors, author)
+		}
+	}
+
 	rating, err := strconv.ParseFloat(r.PostForm.Get("rating"), 64)
 
 	if err != nil {
@@ -172,17 +203,33 @@ func (v *View) BookCreateProcess(w http.ResponseWriter, r *http.Request) ([]byte
 	}
 
 	book := struct {
-		Title     string   `json:"title"`
-		Pages     int      `json:"pages,omitempty"`
-		Published int      `json:"published,omitempty"`
-		Genres    []string `json:"genres,omitempty"`
-		Rating    float64  `json:"rating,omitempty"`
+		Title       string   `json:"title"`
+		Authors     []string `json:"authors,omitempty"`
+		ISBN10      string   `json:"isbn10,omitempty"`
+		ISBN13      string   `json:"isbn13,omitempty"`
+		Publisher   string   `json:"publisher,omitempty"`
+		Language    string   `json:"language,omitempty"`
+		Description string   `json:"description,omitempty"`
+		Edition     string   `json:"edition,omitempty"`
+		Series      string   `json:"series,omitempty"`
+		Pages       int      `json:"pages,omitempty"`
+		Published   int      `json:"published,omitempty"`
+		Genres      []string `json:"genres,omitempty"`
+		Rating      float64  `json:"rating,omitempty"`
 	}{
-		Title:     title,
-		Pages:     pages,
-		Published: published,
-		Genres:    genres,
-		Rating:    rating,
+		Title:       title,
+		Authors:     authors,
+		ISBN10:      strings.TrimSpace(r.PostForm.Get("isbn10")),
+		ISBN13:      strings.TrimSpace(r.PostForm.Get("isbn13")),
+		Publisher:   r.PostForm.Get("publisher"),
+		Language:    r.PostForm.Get("language"),
+		Description: r.PostForm.Get("description"),
+		Edition:     r.PostForm.Get("edition"),
+		Series:      r.PostForm.Get("series"),
+		Pages:       pages,
+		Published:   published,
+		Genres:      genres,
+		Rating:      rating,
 	}
 
 	data, err := json.Marshal(book)
@@ -201,21 +248,31 @@ Returning any error encountered.
 Parameters:
 
 	param1: w http.ResponseWriter
-	param2: pointer of a slice of book data
+	param2: r *http.Request
+	param3: pointer of a slice of book data
+	param4: status string, the reading status filter applied, if any
+	param5: progress of the current reading goals, shown above the list
 
 Returns:
 
 	return1: error
 */
-func (v *View) BookHome(w http.ResponseWriter, books []*data.Book) error {
+func (v *View) BookHome(w http.ResponseWriter, r *http.Request, books []*data.Book, status string, goals []*data.GoalProgress) error {
 	files := []string{BASEHTML, NAVHTML, HOMEHTML}
 
-	ts, err := template.ParseFiles(files...)
+	ts, err := template.New("home").Funcs(templateFuncs(r)).ParseFiles(files...)
 	if err != nil {
 		return err
 	}
 
-	err = ts.ExecuteTemplate(w, "base", books)
+	page := struct {
+		Books    []*data.Book
+		Status   string
+		Statuses []string
+		Goals    []*data.GoalProgress
+	}{books, status, data.Statuses, goals}
+
+	err = ts.ExecuteTemplate(w, "base", page)
 
 	if err != nil {
 		return err
@@ -231,26 +288,32 @@ Returning any error encountered.
 Parameters:
 
 	param1: w http.ResponseWriter
-	param2: id of the book
-	param3: pointer of book data
+	param2: r *http.Request
+	param3: id of the book
+	param4: pointer of book data
+	param5: the book's reviews
+	param6: the book's private notes
 
 Returns:
 
 	return1: error
 */
-func (v *View) BookView(w http.ResponseWriter, id string, book *data.Book) error {
+func (v *View) BookView(w http.ResponseWriter, r *http.Request, id string, book *data.Book, reviews []*data.Review, notes []*data.Note) error {
 	files := []string{BASEHTML, NAVHTML, VIEWHTML}
 
-	// Used to convert comma-separated genres to a slice within the template.
-	funcs := template.FuncMap{"join": strings.Join}
-
-	ts, err := template.New("showBook").Funcs(funcs).ParseFiles(files...)
+	ts, err := template.New("showBook").Funcs(templateFuncs(r)).ParseFiles(files...)
 
 	if err != nil {
 		return err
 	}
 
-	err = ts.ExecuteTemplate(w, "base", book)
+	page := struct {
+		Book    *data.Book
+		Reviews []*data.Review
+		Notes   []*data.Note
+	}{book, reviews, notes}
+
+	err = ts.ExecuteTemplate(w, "base", page)
 
 	if err != nil {
 		return err
@@ -258,3 +321,224 @@ func (v *View) BookView(w http.ResponseWriter, id string, book *data.Book) error
 
 	return nil
 }
+
+/*
+Renders the authors page listing every author.
+Returning any error encountered.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+	param3: slice of a pointer of author data
+
+Returns:
+
+	return1: error
+*/
+func (v *View) AuthorList(w http.ResponseWriter, r *http.Request, authors []*data.Author) error {
+	files := []string{BASEHTML, NAVHTML, AUTHORSHTML}
+
+	ts, err := template.New("authors").Funcs(templateFuncs(r)).ParseFiles(files...)
+	if err != nil {
+		return err
+	}
+
+	return ts.ExecuteTemplate(w, "base", authors)
+}
+
+/*
+Renders an author's page with their details and books.
+Returning any error encountered.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+	param3: pointer of author data
+	param4: slice of a pointer of the author's books
+
+Returns:
+
+	return1: error
+*/
+func (v *View) AuthorView(w http.ResponseWriter, r *http.Request, author *data.Author, books []*data.Book) error {
+	files := []string{BASEHTML, NAVHTML, AUTHORHTML}
+
+	ts, err := template.New("showAuthor").Funcs(templateFuncs(r)).ParseFiles(files...)
+	if err != nil {
+		return err
+	}
+
+	page := struct {
+		Author *data.Author
+		Books  []*data.Book
+	}{author, books}
+
+	return ts.ExecuteTemplate(w, "base", page)
+}
+
+/*
+Renders the shelves page listing every shelf.
+Returning any error encountered.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+	param3: slice of a pointer of shelf data
+
+Returns:
+
+	return1: error
+*/
+func (v *View) ShelfList(w http.ResponseWriter, r *http.Request, shelves []*data.Shelf) error {
+	files := []string{BASEHTML, NAVHTML, SHELVESHTML}
+
+	ts, err := template.New("shelves").Funcs(templateFuncs(r)).ParseFiles(files...)
+	if err != nil {
+		return err
+	}
+
+	return ts.ExecuteTemplate(w, "base", shelves)
+}
+
+/*
+Renders a shelf's page with its books in shelf order.
+Returning any error encountered.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+	param3: pointer of shelf data, with its books
+
+Returns:
+
+	return1: error
+*/
+func (v *View) ShelfView(w http.ResponseWriter, r *http.Request, shelf *data.Shelf) error {
+	files := []string{BASEHTML, NAVHTML, SHELFHTML}
+
+	ts, err := template.New("showShelf").Funcs(templateFuncs(r)).ParseFiles(files...)
+	if err != nil {
+		return err
+	}
+
+	return ts.ExecuteTemplate(w, "base", shelf)
+}
+
+/*
+Renders the statistics page summarising the library.
+Returning any error encountered.
+
+Parameters:
+
+	param1: w http.ResponseWriter
+	param2: r *http.Request
+	param3: pointer of library statistics
+
+Returns:
+
+	return1: error
+*/
+func (v *View) StatsView(w http.ResponseWriter, r *http.Request, stats *data.LibraryStats) error {
+	files := []string{BASEHTML, NAVHTML, STATSHTML}
+
+	ts, err := template.New("stats").Funcs(templateFuncs(r)).ParseFiles(files...)
+	if err != nil {
+		return err
+	}
+
+	return ts.ExecuteTemplate(w, "base", stats)
+}
+
+/*
+templateFuncs returns the functions available to every page template for the given request.
+
+	join:      converts a slice of genres to a comma-separated string.
+	csrfField: renders the hidden CSRF token input every form must include.
+	flashes:   returns, and clears, the flash messages queued on the session.
+	cspNonce:  the Content-Security-Policy nonce every <script> tag must carry.
+	authors:   pairs a book's author names with the IDs of the author pages they link to.
+	status:    turns a reading status such as want_to_read into a label ("Want to read").
+	abs:       the absolute value of an int, such as how far a goal is behind.
+	markdown:  renders user-written markdown as sanitised HTML.
+
+Parameters:
+
+	param1: r *http.Request
+
+Returns:
+
+	return1: template.FuncMap
+*/
+func templateFuncs(r *http.Request) template.FuncMap {
+	s := session.FromContext(r.Context())
+
+	return template.FuncMap{
+		"join": strings.Join,
+		"csrfField": func() template.HTML {
+			if s == nil {
+				return ""
+			}
+
+			return template.HTML(`<input type="hidden" name="` + session.CSRFFormField + `" value="` + template.HTMLEscapeString(s.CSRFToken) + `">`)
+		},
+		"flashes": func() []string {
+			if s == nil {
+				return nil
+			}
+
+			return s.PopFlashes()
+		},
+		"cspNonce": func() string {
+			return middleware.CSPNonce(r.Context())
+		},
+		"authors":  bookAuthors,
+		"status":   statusLabel,
+		"markdown": renderMarkdown,
+		"abs": func(n int) int {
+			if n < 0 {
+				return -n
+			}
+			return n
+		},
+	}
+}
+
+type authorLink struct {
+	ID   string
+	Name string
+}
+
+/*
+bookAuthors pairs the book's author names with their IDs. Names are only linked while they line up with
+the IDs one to one; after an author was deleted the remaining names are shown as plain text.
+*/
+func bookAuthors(book *data.Book) []authorLink {
+	links := make([]authorLink, len(book.Authors))
+
+	for i, name := range book.Authors {
+		links[i].Name = name
+
+		if len(book.AuthorIDs) == len(book.Authors) {
+			links[i].ID = book.AuthorIDs[i]
+		}
+	}
+
+	return links
+}
+
+/*
+statusLabel turns a reading status into the label shown on pages, books without one are still to be read.
+*/
+func statusLabel(status string) string {
+	if status == "" {
+		status = data.StatusWantToRead
+	}
+
+	label := strings.ReplaceAll(status, "_", " ")
+
+	return strings.ToUpper(label[:1]) + label[1:]
+}
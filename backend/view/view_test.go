@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"readinglistapp/internal/data"
+	"strings"
 	"testing"
 )
 
@@ -69,3 +71,47 @@ func TestReadJSON(t *testing.T) {
 		t.Errorf("got error %v, expected nil", err)
 	}
 }
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		markdown  string
+		contains  string
+		forbidden string
+	}{
+		{"formatting", "A *great* read", "<em>great</em>", ""},
+		{"raw html", "Hi <script>alert(1)</script>", "Hi", "<script"},
+		{"javascript link", "[click](javascript:alert(1))", "click", "javascript:"},
+		{"event handler", `<img src=x onerror="alert(1)">`, "", "onerror"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := string(renderMarkdown(tt.markdown))
+
+			if !strings.Contains(html, tt.contains) {
+				t.Errorf("got %q, expected it to contain %q", html, tt.contains)
+			}
+
+			if tt.forbidden != "" && strings.Contains(html, tt.forbidden) {
+				t.Errorf("got %q, expected %q to be removed", html, tt.forbidden)
+			}
+		})
+	}
+}
+
+func TestRenderHighlightsMarkdown(t *testing.T) {
+	book := &data.Book{Title: "Dune", Authors: []string{"Frank Herbert"}}
+	highlights := []*data.Highlight{
+		{Quote: "I must not fear.\nFear is the mind-killer.", Page: 8, Comment: "The litany", Tags: []string{"fear"}},
+		{Quote: "The spice must flow.", Location: "1234"},
+	}
+
+	expected := "# Dune\n\n*Frank Herbert*\n\n" +
+		"> I must not fear.\n> Fear is the mind-killer.\n\n— page 8\n\nThe litany\n\nTags: fear\n\n" +
+		"---\n\n> The spice must flow.\n\n— location 1234\n\n"
+
+	if got := string(NewView().RenderHighlightsMarkdown(book, highlights)); got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}